@@ -1,10 +1,18 @@
 package main
 
 import (
+	"context"
+	"expvar"
+	"fmt"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"calculator/config"
+	"calculator/domain/entities"
+	"calculator/domain/repositories"
 	"calculator/internal/container"
 
 	"github.com/gorilla/mux"
@@ -12,6 +20,9 @@ import (
     "github.com/joho/godotenv"
 )
 
+// tempoEncerramento é a espera pelas requisições em andamento no desligamento
+const tempoEncerramento = 30 * time.Second
+
 func init() {
 	// Configuração do logrus para JSON
 	logrus.SetFormatter(&logrus.JSONFormatter{})
@@ -27,8 +38,15 @@ func init() {
 	}
 }
 
-func main() {    
-    
+func main() {
+	// o Fatal fica fora de run para que os defers (cont.Close) rodem antes da saída
+	if err := run(); err != nil {
+		logrus.Fatal(err)
+	}
+}
+
+// run sobe o servidor e só retorna quando ele é encerrado
+func run() error {
     if err := godotenv.Load("../.env"); err != nil {
         logrus.Warn("Não foi possível carregar o arquivo .env:", err)
     }
@@ -39,16 +57,104 @@ func main() {
 	// Inicializa o container de dependências
 	cont, err := container.NewContainer(config)
 	if err != nil {
-		logrus.Fatal("Erro ao inicializar o container de dependências:", err)
+		return fmt.Errorf("Erro ao inicializar o container de dependências: %w", err)
 	}
 	defer cont.Close()
 
+	// Endpoints que dependem de uma fonte opcional respondem 503 enquanto ela estiver fora do ar.
+	// O cálculo de preço não depende do Firebird: fora do ar ele usa o cache da última leitura.
+	comFirebird := cont.SourcesController.Require(repositories.FonteFirebird)
+	comSQLServer := cont.SourcesController.Require(repositories.FonteSQLServer)
+	// Rotas que agem em nome de um usuário: usuário e papéis vêm do token, não do corpo
+	autenticado := cont.AuthController.Require
+	// Webhooks e replay expõem os eventos de preço: só admin ou pricing
+	gestorEventos := cont.AuthController.RequireRole(entities.PapelAdmin, entities.PapelPricing)
+
 	// Configura o roteamento
 	r := mux.NewRouter()
+	r.HandleFunc("/health", cont.SourcesController.HealthHandler).Methods("GET")
 	r.HandleFunc("/calcAlpha", cont.PriceController.CalculateAlphaHandler).Methods("GET")
+	r.HandleFunc("/priceList", cont.PriceController.PriceListHandler).Methods("GET")
+	r.HandleFunc("/quotes", cont.QuoteController.CreateQuoteHandler).Methods("POST")
+	r.HandleFunc("/quotes/{id}", cont.QuoteController.GetQuoteHandler).Methods("GET")
+	r.HandleFunc("/nfe/import", autenticado(cont.NfeController.ImportHandler)).Methods("POST")
+	r.HandleFunc("/costHistory", cont.CostController.CostHistoryHandler).Methods("GET")
+	r.HandleFunc("/stock/movements", autenticado(cont.CostController.StockMovementsHandler)).Methods("POST")
+	r.HandleFunc("/replacementCosts", autenticado(cont.CostController.ReplacementCostsHandler)).Methods("POST")
+	r.HandleFunc("/freight/reload", autenticado(cont.FreightController.ReloadHandler)).Methods("POST")
+	r.HandleFunc("/markdown", cont.MarkdownController.ProposeHandler).Methods("GET")
+	r.HandleFunc("/markdown", cont.MarkdownController.ProposeFromFileHandler).Methods("POST")
+	r.HandleFunc("/competitorPrices/import", autenticado(cont.CompetitorController.ImportHandler)).Methods("POST")
+	r.HandleFunc("/positioning", cont.CompetitorController.PositioningHandler).Methods("GET")
+	r.HandleFunc("/priceDiff", comFirebird(cont.PriceDiffController.DiffHandler)).Methods("POST")
+	r.HandleFunc("/priceDiff/{id}", cont.PriceDiffController.ResultHandler).Methods("GET")
+	r.HandleFunc("/priceWrites/{id}", cont.PriceWriteController.GetHandler).Methods("GET")
+	r.HandleFunc("/priceWrites/{id}/rollback", autenticado(comFirebird(cont.PriceWriteController.RollbackHandler))).Methods("POST")
+	r.HandleFunc("/priceWrites/{id}/publish", autenticado(cont.PriceWriteController.PublishHandler)).Methods("POST")
+	r.HandleFunc("/proposals", autenticado(comFirebird(cont.ProposalController.CreateHandler))).Methods("POST")
+	r.HandleFunc("/proposals", cont.ProposalController.ListHandler).Methods("GET")
+	r.HandleFunc("/proposals/transition", autenticado(cont.ProposalController.TransitionHandler)).Methods("POST")
+	r.HandleFunc("/proposals/publish", autenticado(comFirebird(cont.ProposalController.PublishHandler))).Methods("POST")
+	r.HandleFunc("/proposals/{id}", cont.ProposalController.GetHandler).Methods("GET")
+	r.HandleFunc("/marginAnalysis", comSQLServer(cont.MarginAnalysisController.AnalyzeHandler)).Methods("POST")
+	r.HandleFunc("/marginAnalysis/{id}", cont.MarginAnalysisController.ResultHandler).Methods("GET")
+	r.HandleFunc("/backtest", comSQLServer(cont.BacktestController.RunHandler)).Methods("POST")
+	r.HandleFunc("/elasticity/estimate", comSQLServer(cont.ElasticityController.EstimateHandler)).Methods("POST")
+	r.HandleFunc("/elasticity/estimate/{id}", cont.ElasticityController.ResultHandler).Methods("GET")
+	r.HandleFunc("/priceSuggestion", cont.ElasticityController.SuggestionHandler).Methods("GET")
+	r.HandleFunc("/repricing", autenticado(comFirebird(cont.RepricingController.RepriceHandler))).Methods("POST")
+	r.HandleFunc("/repricing/status", cont.RepricingController.StatusHandler).Methods("GET")
+	r.HandleFunc("/webhooks", autenticado(gestorEventos(cont.WebhookController.CreateHandler))).Methods("POST")
+	r.HandleFunc("/webhooks", autenticado(gestorEventos(cont.WebhookController.ListHandler))).Methods("GET")
+	r.HandleFunc("/webhooks/deadLetters", autenticado(gestorEventos(cont.WebhookController.DeadLettersHandler))).Methods("GET")
+	r.HandleFunc("/events/replay", autenticado(gestorEventos(cont.WebhookController.ReplayHandler))).Methods("POST")
+	r.HandleFunc("/jobs", autenticado(cont.JobController.CreateHandler)).Methods("POST")
+	r.HandleFunc("/jobs/{id}", cont.JobController.GetHandler).Methods("GET")
+	r.HandleFunc("/jobs/{id}/cancel", autenticado(cont.JobController.CancelHandler)).Methods("POST")
+	r.HandleFunc("/jobs/{id}/results", cont.JobController.ResultsHandler).Methods("GET")
+	r.HandleFunc("/snapshot", cont.SnapshotController.StatusHandler).Methods("GET")
+	r.HandleFunc("/snapshot/reload", autenticado(comFirebird(cont.SnapshotController.ReloadHandler))).Methods("POST")
+	r.HandleFunc("/firebirdCache", cont.FirebirdCacheController.StatusHandler).Methods("GET")
+	r.HandleFunc("/firebirdCache/refresh", autenticado(comFirebird(cont.FirebirdCacheController.RefreshHandler))).Methods("POST")
+
+	// SIGINT/SIGTERM encerram o servidor, esperam as requisições em andamento e,
+	// no defer, param as rotinas em segundo plano e fecham as conexões
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
 
-	logrus.Info("Servidor na porta 8080...")
-	if err := http.ListenAndServe(":8080", r); err != nil {
-		logrus.Fatal("Erro ao iniciar o servidor:", err)
+	srv := &http.Server{Addr: ":8080", Handler: r}
+	erros := make(chan error, 2)
+	go func() {
+		logrus.Info("Servidor na porta 8080...")
+		erros <- srv.ListenAndServe()
+	}()
+
+	// Métricas (expvar) só no endereço interno, nunca na porta da API
+	var debugSrv *http.Server
+	if config.DebugAddr != "" {
+		debug := http.NewServeMux()
+		debug.Handle("/debug/vars", expvar.Handler())
+		debugSrv = &http.Server{Addr: config.DebugAddr, Handler: debug}
+		go func() {
+			logrus.Info("Métricas em ", config.DebugAddr)
+			erros <- debugSrv.ListenAndServe()
+		}()
+	}
+
+	select {
+	case err := <-erros:
+		return fmt.Errorf("Erro ao iniciar o servidor: %w", err)
+	case <-ctx.Done():
+	}
+
+	logrus.Info("Encerrando o servidor...")
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), tempoEncerramento)
+	defer cancelShutdown()
+	if debugSrv != nil {
+		debugSrv.Shutdown(shutdownCtx)
+	}
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("Erro ao encerrar o servidor: %w", err)
 	}
+	return nil
 }
@@ -0,0 +1,87 @@
+// Receptor local de webhooks para testar o despacho do outbox.
+//
+//	go run ./cmd/webhookreceiver -addr :9090 -segredo teste -falhas 3
+//
+// Cadastre o destino com POST /webhooks {"nome":"local","url":"http://localhost:9090/","segredo":"teste"}.
+// Com -falhas N as N primeiras requisições recebem 500, para exercitar as novas tentativas.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"calculator/domain/entities"
+	"calculator/internal/webhook"
+
+	"github.com/sirupsen/logrus"
+)
+
+func main() {
+	addr := flag.String("addr", ":9090", "endereço de escuta")
+	segredo := flag.String("segredo", os.Getenv("WEBHOOK_SECRET"), "segredo cadastrado no destino")
+	falhas := flag.Int("falhas", 0, "número de requisições iniciais respondidas com 500")
+	tolerancia := flag.Duration("tolerancia", 5*time.Minute, "diferença máxima do X-Timestamp")
+	flag.Parse()
+
+	if *segredo == "" {
+		logrus.Fatal("Informe o segredo com -segredo ou WEBHOOK_SECRET")
+	}
+
+	var mu sync.Mutex
+	recebidas := 0
+	vistos := map[int64]bool{}
+
+	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		corpo, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+		if err != nil {
+			http.Error(w, "erro ao ler o corpo", http.StatusBadRequest)
+			return
+		}
+		err = webhook.Verify(*segredo, r.Header.Get(webhook.HeaderAssinatura), r.Header.Get(webhook.HeaderTimestamp),
+			corpo, *tolerancia, time.Now())
+		if err != nil {
+			logrus.WithError(err).Warn("Webhook rejeitado")
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		mu.Lock()
+		recebidas++
+		n := recebidas
+		mu.Unlock()
+		if n <= *falhas {
+			logrus.WithField("requisicao", n).Info("Falha simulada")
+			http.Error(w, "falha simulada", http.StatusInternalServerError)
+			return
+		}
+
+		var ev entities.PriceEvent
+		if err := json.Unmarshal(corpo, &ev); err != nil {
+			http.Error(w, "evento inválido", http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		repetido := vistos[ev.ID]
+		vistos[ev.ID] = true
+		mu.Unlock()
+
+		logrus.WithFields(logrus.Fields{
+			"evento":   ev.ID,
+			"tipo":     ev.Tipo,
+			"produto":  ev.Produto,
+			"repetido": repetido,
+			"dados":    string(ev.Dados),
+		}).Info("Webhook recebido")
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	logrus.Info("Receptor de webhooks em ", *addr)
+	if err := http.ListenAndServe(*addr, nil); err != nil {
+		logrus.Fatal("Erro ao iniciar o receptor:", err)
+	}
+}
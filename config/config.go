@@ -2,6 +2,9 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -11,6 +14,63 @@ type Config struct {
 	PostgresURL  string
 	FirebirdURL  string
 	SQLServerURL string
+
+	// Fontes obrigatórias impedem a inicialização quando fora do ar; as opcionais são
+	// reconectadas em segundo plano. O Postgres é sempre obrigatório.
+	FirebirdRequired  bool
+	SQLServerRequired bool
+
+	// UF do estabelecimento emissor; separa venda interna e interestadual no ICMS por destino
+	UFOrigem string
+
+	// Consulta do estoque atual no ERP (Firebird), com o produto como único parâmetro;
+	// é o estoque inicial da primeira NF-e importada de cada produto
+	ErpStockQuery string
+
+	// Consulta das linhas de venda no SQL Server, usada na análise de margem, no backtest e
+	// na elasticidade; vazia desativa esses relatórios
+	SalesQuery string
+
+	// Tabela de preços do ERP (Firebird) lida no relatório de diferenças e atualizada nas
+	// gravações de preço; um preço por produto e tabela de preço
+	ErpPriceTable      string
+	ErpPriceColProduto string
+	ErpPriceColTabela  string
+	ErpPriceColPreco   string
+
+	// Fonte das tabelas de frete: "postgres" (padrão) ou "csv"
+	FreightSource string
+	// Diretório dos CSVs de frete quando FreightSource é "csv"
+	FreightCSVDir string
+	// Intervalo de recarga das tabelas de frete (0 desativa)
+	FreightReloadInterval time.Duration
+
+	// Publica as gravações de preço no banco sysuni (SQL Server) para o BI
+	SysuniPublish bool
+	// Intervalo da publicação da fila de gravações no sysuni
+	SysuniPublishInterval time.Duration
+
+	// Escuta as notificações do Postgres e recalcula os preços afetados (uma instância por banco)
+	RepricingListen bool
+	// Espera para repetir a varredura de recuperação do recálculo que falhou
+	RepricingRetryInterval time.Duration
+
+	// Intervalo do despacho dos webhooks de alteração de preço (0 desativa)
+	WebhookDispatchInterval time.Duration
+
+	// Cálculos simultâneos dos jobs em lote nesta instância (0 não executa jobs)
+	JobWorkers int
+
+	// Intervalo de recarga do snapshot de dados de preço em memória (0 desativa o modo snapshot)
+	SnapshotInterval time.Duration
+
+	// Intervalo de atualização do cache da última leitura do Firebird (0 não atualiza)
+	FirebirdCacheInterval time.Duration
+	// Idade máxima do cache aceita com o Firebird fora do ar (0 recusa sempre)
+	FirebirdCacheMaxAge time.Duration
+
+	// Endereço interno das métricas (/debug/vars), fora da porta da API; vazio desativa
+	DebugAddr string
 }
 
 // Load carrega as variáveis de ambiente do arquivo .env
@@ -24,5 +84,70 @@ func Load() *Config {
 		PostgresURL:  postgresURL,
 		FirebirdURL:  os.Getenv("FIREBIRD_URL"),
 		SQLServerURL: os.Getenv("SQLSERVER_URL"),
+
+		FirebirdRequired:  os.Getenv("FIREBIRD_REQUIRED") == "true",
+		SQLServerRequired: os.Getenv("SQLSERVER_REQUIRED") == "true",
+
+		UFOrigem: strings.ToUpper(getEnv("UF_ORIGEM", "SP")),
+
+		ErpStockQuery: os.Getenv("ERP_STOCK_QUERY"),
+
+		SalesQuery: os.Getenv("SALES_QUERY"),
+
+		ErpPriceTable:      getEnv("ERP_PRICE_TABLE", "precos_produto"),
+		ErpPriceColProduto: getEnv("ERP_PRICE_COL_PRODUTO", "produto"),
+		ErpPriceColTabela:  getEnv("ERP_PRICE_COL_TABELA", "tabela"),
+		ErpPriceColPreco:   getEnv("ERP_PRICE_COL_PRECO", "preco"),
+
+		FreightSource:         getEnv("FREIGHT_SOURCE", "postgres"),
+		FreightCSVDir:         os.Getenv("FREIGHT_CSV_DIR"),
+		FreightReloadInterval: getEnvDuration("FREIGHT_RELOAD_INTERVAL", 15*time.Minute),
+
+		SysuniPublish:         os.Getenv("SYSUNI_PUBLISH") == "true",
+		SysuniPublishInterval: getEnvDuration("SYSUNI_PUBLISH_INTERVAL", 10*time.Second),
+
+		RepricingListen:        os.Getenv("REPRICING_LISTEN") != "false",
+		RepricingRetryInterval: getEnvDuration("REPRICING_RETRY_INTERVAL", time.Minute),
+
+		WebhookDispatchInterval: getEnvDuration("WEBHOOK_DISPATCH_INTERVAL", 5*time.Second),
+
+		JobWorkers: getEnvInt("JOB_WORKERS", 4),
+
+		SnapshotInterval: getEnvDuration("SNAPSHOT_INTERVAL", 0),
+
+		FirebirdCacheInterval: getEnvDuration("FIREBIRD_CACHE_INTERVAL", time.Hour),
+		FirebirdCacheMaxAge:   getEnvDuration("FIREBIRD_CACHE_MAX_AGE", 24*time.Hour),
+
+		DebugAddr: getEnv("DEBUG_ADDR", "127.0.0.1:6060"),
+	}
+}
+
+// getEnv retorna a variável de ambiente ou o valor padrão
+func getEnv(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+// getEnvInt lê um inteiro; inválido usa o padrão
+func getEnvInt(key string, def int) int {
+	v, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return def
+	}
+	return v
+}
+
+// getEnvDuration lê uma duração no formato do Go (ex.: 15m, 1h); inválida usa o padrão
+func getEnvDuration(key string, def time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return def
 	}
+	return d
 }
@@ -0,0 +1,86 @@
+package entities
+
+import "time"
+
+// ElasticidadePadrao é a elasticidade-preço da demanda usada quando não informada
+const ElasticidadePadrao = -1.5
+
+// BacktestRequest reaplica um conjunto de parâmetros às vendas de um período
+type BacktestRequest struct {
+	De    time.Time
+	Ate   time.Time
+	Skus  []string
+	Canal string
+	// Parametros altera os parâmetros atuais; os campos omitidos ficam como estão
+	Parametros *ParametersOverride
+	// Elasticidade da quantidade ao preço (-1,5: preço 10% maior vende ~13,3% menos)
+	Elasticidade *float64
+}
+
+// BacktestRow agrega o resultado do backtesting por SKU ou departamento.
+// Os preços médios são ponderados pela quantidade vendida.
+type BacktestRow struct {
+	Chave               string  `json:"chave"`
+	Vendas              int     `json:"vendas"`
+	Quantidade          float64 `json:"quantidade"`
+	QuantidadeEstimada  float64 `json:"quantidade_estimada"`
+	PrecoMedioPraticado float64 `json:"preco_medio_praticado"`
+	PrecoMedioEstimado  float64 `json:"preco_medio_estimado"`
+	PrecoAlfaAtual      float64 `json:"preco_alfa_atual"`
+	PrecoAlfaProposto   float64 `json:"preco_alfa_proposto"`
+	Receita             float64 `json:"receita"`
+	ReceitaEstimada     float64 `json:"receita_estimada"`
+	Lucro               float64 `json:"lucro"`
+	LucroEstimado       float64 `json:"lucro_estimado"`
+	VariacaoReceita     float64 `json:"variacao_receita"`
+	VariacaoLucro       float64 `json:"variacao_lucro"`
+
+	alfaAtual, alfaProposto float64
+}
+
+// BacktestLine é uma venda reprecificada com os parâmetros propostos
+type BacktestLine struct {
+	Venda              SaleLine
+	PrecoAlfaAtual     float64
+	PrecoAlfaProposto  float64
+	PrecoEstimado      float64
+	QuantidadeEstimada float64
+	Lucro              float64
+	LucroEstimado      float64
+}
+
+// Adicionar soma uma venda reprecificada
+func (r *BacktestRow) Adicionar(l BacktestLine) {
+	r.Vendas++
+	r.Quantidade += l.Venda.Quantidade
+	r.QuantidadeEstimada += l.QuantidadeEstimada
+	r.Receita += l.Venda.Receita()
+	r.ReceitaEstimada += l.PrecoEstimado * l.QuantidadeEstimada
+	r.Lucro += l.Lucro
+	r.LucroEstimado += l.LucroEstimado
+	r.alfaAtual += l.PrecoAlfaAtual * l.Venda.Quantidade
+	r.alfaProposto += l.PrecoAlfaProposto * l.Venda.Quantidade
+
+	if r.Quantidade > 0 {
+		r.PrecoMedioPraticado = r.Receita / r.Quantidade
+		r.PrecoAlfaAtual = r.alfaAtual / r.Quantidade
+		r.PrecoAlfaProposto = r.alfaProposto / r.Quantidade
+	}
+	if r.QuantidadeEstimada > 0 {
+		r.PrecoMedioEstimado = r.ReceitaEstimada / r.QuantidadeEstimada
+	}
+	r.VariacaoReceita = r.ReceitaEstimada - r.Receita
+	r.VariacaoLucro = r.LucroEstimado - r.Lucro
+}
+
+// BacktestResult é o resultado do backtesting no período
+type BacktestResult struct {
+	De              time.Time     `json:"de"`
+	Ate             time.Time     `json:"ate"`
+	Elasticidade    float64       `json:"elasticidade"`
+	Parametros      Parameters    `json:"parametros"`
+	Total           BacktestRow   `json:"total"`
+	PorSku          []BacktestRow `json:"por_sku"`
+	PorDepartamento []BacktestRow `json:"por_departamento"`
+	Erros           []SaleError   `json:"erros,omitempty"`
+}
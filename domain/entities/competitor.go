@@ -0,0 +1,79 @@
+package entities
+
+import (
+	"sort"
+	"time"
+)
+
+// CompetitorPrice é um preço coletado em um concorrente
+type CompetitorPrice struct {
+	Produto     string    `json:"produto"`
+	Ean         string    `json:"ean,omitempty"`
+	Concorrente string    `json:"concorrente"`
+	Preco       float64   `json:"preco"`
+	ColetadoEm  time.Time `json:"coletado_em"`
+}
+
+// CompetitorImportResult resume a importação de um arquivo de preços de concorrentes
+type CompetitorImportResult struct {
+	Importados    int                   `json:"importados"`
+	NaoVinculados []CompetitorUnmatched `json:"nao_vinculados,omitempty"`
+}
+
+// CompetitorUnmatched é uma linha do arquivo sem produto correspondente
+type CompetitorUnmatched struct {
+	Linha       int    `json:"linha"`
+	Ean         string `json:"ean"`
+	Concorrente string `json:"concorrente"`
+}
+
+// PositioningOptions controla o relatório de posicionamento e o modo ancorado no concorrente
+type PositioningOptions struct {
+	// Dias limita a idade dos preços de concorrentes considerados
+	Dias int
+	// Ancorado sugere o menor preço de concorrente menos DescontoAncora
+	Ancorado       bool
+	DescontoAncora float64
+	// MargemMinima é o piso de margem sobre o preço para aceitar o preço ancorado;
+	// nil usa o lucro padrão desejado dos parâmetros
+	MargemMinima *float64
+}
+
+// CompetitorPositioning compara o preço alfa com os preços dos concorrentes.
+// Os índices são a variação do preço alfa sobre a referência (0,05 = 5% acima).
+type CompetitorPositioning struct {
+	Sku              string            `json:"sku"`
+	PrecoAlfa        float64           `json:"preco_alfa"`
+	Concorrentes     []CompetitorPrice `json:"concorrentes"`
+	MenorPreco       float64           `json:"menor_preco"`
+	MenorConcorrente string            `json:"menor_concorrente"`
+	Mediana          float64           `json:"mediana"`
+	IndiceMenor      float64           `json:"indice_menor"`
+	IndiceMediana    float64           `json:"indice_mediana"`
+
+	// Modo ancorado
+	PrecoAncorado     float64 `json:"preco_ancorado,omitempty"`
+	MargemAncorado    float64 `json:"margem_ancorado,omitempty"`
+	MargemMinima      float64 `json:"margem_minima,omitempty"`
+	AncoragemAplicada bool    `json:"ancoragem_aplicada"`
+	PrecoSugerido     float64 `json:"preco_sugerido"`
+	Motivo            string  `json:"motivo,omitempty"`
+	Erro              string  `json:"erro,omitempty"`
+}
+
+// MedianaPrecos devolve a mediana dos preços informados
+func MedianaPrecos(precos []CompetitorPrice) float64 {
+	if len(precos) == 0 {
+		return 0
+	}
+	valores := make([]float64, len(precos))
+	for i, p := range precos {
+		valores[i] = p.Preco
+	}
+	sort.Float64s(valores)
+	meio := len(valores) / 2
+	if len(valores)%2 == 0 {
+		return (valores[meio-1] + valores[meio]) / 2
+	}
+	return valores[meio]
+}
@@ -0,0 +1,68 @@
+package entities
+
+// Bases de custo aceitas no cálculo de preço
+const (
+	BaseCustoMedio            = "medio"
+	BaseCustoUltimaCompra     = "ultima_compra"
+	BaseCustoTabelaFornecedor = "tabela_fornecedor"
+	BaseCustoReposicao        = "reposicao"
+)
+
+// BaseCustoValida informa se a base de custo é conhecida
+func BaseCustoValida(base string) bool {
+	switch base {
+	case BaseCustoMedio, BaseCustoUltimaCompra, BaseCustoTabelaFornecedor, BaseCustoReposicao:
+		return true
+	}
+	return false
+}
+
+// ReplacementCost é o custo de reposição informado para um produto
+type ReplacementCost struct {
+	Produto string  `json:"produto"`
+	Custo   float64 `json:"custo"`
+	Usuario string  `json:"usuario"`
+}
+
+// CustoBase descreve a base de custo aplicada em um cálculo
+type CustoBase struct {
+	Base string
+	// Origem indica de onde veio a escolha: requisicao, departamento ou padrao
+	Origem string
+	// CustoNF é o custo unitário de nota da base usada
+	CustoNF float64
+	// Observacao explica substituições, como a falta de dados da base escolhida
+	Observacao string
+}
+
+// EntradaPorUnidade converte uma entrada de compra nos valores unitários do PriceInput
+func EntradaPorUnidade(e PurchaseEntry) PriceInput {
+	if e.Quantidade <= 0 {
+		return PriceInput{}
+	}
+	return PriceInput{
+		CustoMedioNF:   e.CustoNF / e.Quantidade,
+		CustoMedioLiq:  e.CustoLiquido() / e.Quantidade,
+		IcmsMedio:      e.CreditoIcms / e.Quantidade,
+		PisCofinsMedio: e.CreditoPisCofins / e.Quantidade,
+	}
+}
+
+// AjustarCustoNF reescala os custos do PriceInput para um novo custo de nota,
+// mantendo a proporção de créditos do custo médio
+func AjustarCustoNF(pi PriceInput, custoNF float64) PriceInput {
+	if pi.CustoMedioNF <= 0 {
+		// Sem custo médio de referência não há créditos conhecidos
+		pi.CustoMedioNF = custoNF
+		pi.CustoMedioLiq = custoNF
+		pi.IcmsMedio = 0
+		pi.PisCofinsMedio = 0
+		return pi
+	}
+	fator := custoNF / pi.CustoMedioNF
+	pi.CustoMedioNF = custoNF
+	pi.CustoMedioLiq *= fator
+	pi.IcmsMedio *= fator
+	pi.PisCofinsMedio *= fator
+	return pi
+}
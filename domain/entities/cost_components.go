@@ -0,0 +1,33 @@
+package entities
+
+// ProvisaoCustoNFPadrao é a provisão histórica de 1% sobre o custo médio NF
+const ProvisaoCustoNFPadrao = 0.01
+
+// CostComponents são os componentes de custo configuráveis por departamento e canal
+type CostComponents struct {
+	// Escopo descreve a configuração aplicada (ex.: "departamento 12 / canal mercado_livre")
+	Escopo string `json:"escopo"`
+	// Percentuais sobre o preço de venda
+	TaxaDevolucao  float64 `json:"taxa_devolucao"`
+	TaxaChargeback float64 `json:"taxa_chargeback"`
+	// Valores por unidade
+	Embalagem   float64 `json:"embalagem"`
+	Fulfillment float64 `json:"fulfillment"`
+	// ProvisaoCustoNF é um percentual sobre o custo médio NF
+	ProvisaoCustoNF float64 `json:"provisao_custo_nf"`
+}
+
+// ComponentesPadrao mantém o comportamento anterior: só a provisão de 1% sobre o custo NF
+func ComponentesPadrao() CostComponents {
+	return CostComponents{Escopo: "padrao", ProvisaoCustoNF: ProvisaoCustoNFPadrao}
+}
+
+// CustoUnitario soma os componentes em reais por unidade
+func (c CostComponents) CustoUnitario(custoMedioNF float64) float64 {
+	return custoMedioNF*c.ProvisaoCustoNF + c.Embalagem + c.Fulfillment
+}
+
+// PercentualVenda soma os componentes proporcionais ao preço
+func (c CostComponents) PercentualVenda() float64 {
+	return c.TaxaDevolucao + c.TaxaChargeback
+}
@@ -0,0 +1,129 @@
+package entities
+
+import (
+	"math"
+	"time"
+)
+
+// Escopos de estimativa de elasticidade
+const (
+	EscopoSku          = "sku"
+	EscopoDepartamento = "departamento"
+)
+
+// Níveis de confiança da estimativa, pela estatística t do coeficiente
+const (
+	ConfiancaAlta  = "alta"
+	ConfiancaMedia = "media"
+	ConfiancaBaixa = "baixa"
+)
+
+// VariacaoMaximaPadrao limita a sugestão de preço em relação ao preço alfa
+const VariacaoMaximaPadrao = 0.20
+
+// ElasticityEstimate é a elasticidade-preço estimada por regressão log-log
+type ElasticityEstimate struct {
+	Escopo       string  `json:"escopo"`
+	Chave        string  `json:"chave"`
+	Elasticidade float64 `json:"elasticidade"`
+	ErroPadrao   float64 `json:"erro_padrao"`
+	// IntervaloMin e IntervaloMax formam o intervalo de 95% pela t de Student
+	IntervaloMin   float64   `json:"intervalo_min"`
+	IntervaloMax   float64   `json:"intervalo_max"`
+	R2             float64   `json:"r2"`
+	Observacoes    int       `json:"observacoes"`
+	GrausLiberdade int       `json:"graus_liberdade"`
+	Confianca      string    `json:"confianca"`
+	De             time.Time `json:"de"`
+	Ate            time.Time `json:"ate"`
+	CalculadoEm    time.Time `json:"calculado_em"`
+}
+
+// CalcularIntervalo preenche o intervalo de 95% a partir do erro padrão
+func (e *ElasticityEstimate) CalcularIntervalo() {
+	t := ValorCriticoT(e.GrausLiberdade, Nivel95)
+	e.IntervaloMin = e.Elasticidade - t*e.ErroPadrao
+	e.IntervaloMax = e.Elasticidade + t*e.ErroPadrao
+}
+
+// Utilizavel indica se a estimativa serve para sugerir preço: confiança média ou alta
+// e sinal negativo (demanda cai quando o preço sobe)
+func (e ElasticityEstimate) Utilizavel() bool {
+	return e.Elasticidade < 0 && e.Confianca != ConfiancaBaixa
+}
+
+// ClassificarConfianca compara |t| com o valor crítico da t de Student com gl graus de
+// liberdade: 99% para alta e 95% para média
+func ClassificarConfianca(coef, erroPadrao float64, gl int) string {
+	if erroPadrao <= 0 {
+		return ConfiancaBaixa
+	}
+	t := math.Abs(coef / erroPadrao)
+	switch {
+	case t >= ValorCriticoT(gl, Nivel99):
+		return ConfiancaAlta
+	case t >= ValorCriticoT(gl, Nivel95):
+		return ConfiancaMedia
+	}
+	return ConfiancaBaixa
+}
+
+// Níveis bicaudais aceitos por ValorCriticoT
+const (
+	Nivel95 = 95
+	Nivel99 = 99
+)
+
+// Valores críticos bicaudais da t de Student até 30 graus de liberdade e da normal
+var (
+	tabelaT95 = []float64{12.706, 4.303, 3.182, 2.776, 2.571, 2.447, 2.365, 2.306, 2.262, 2.228,
+		2.201, 2.179, 2.160, 2.145, 2.131, 2.120, 2.110, 2.101, 2.093, 2.086,
+		2.080, 2.074, 2.069, 2.064, 2.060, 2.056, 2.052, 2.048, 2.045, 2.042}
+	tabelaT99 = []float64{63.657, 9.925, 5.841, 4.604, 4.032, 3.707, 3.499, 3.355, 3.250, 3.169,
+		3.106, 3.055, 3.012, 2.977, 2.947, 2.921, 2.898, 2.878, 2.861, 2.845,
+		2.831, 2.819, 2.807, 2.797, 2.787, 2.779, 2.771, 2.763, 2.756, 2.750}
+	normal95 = 1.959964
+	normal99 = 2.575829
+)
+
+// ValorCriticoT devolve o valor crítico bicaudal (Nivel95 ou Nivel99) com gl graus de
+// liberdade: tabela até 30 e expansão de Cornish-Fisher acima. gl zero, das estimativas
+// gravadas antes do registro dos graus de liberdade, usa a normal.
+func ValorCriticoT(gl, nivel int) float64 {
+	tabela, z := tabelaT95, normal95
+	if nivel == Nivel99 {
+		tabela, z = tabelaT99, normal99
+	}
+	if gl <= 0 {
+		return z
+	}
+	if gl <= len(tabela) {
+		return tabela[gl-1]
+	}
+	v := float64(gl)
+	z3, z5, z7 := math.Pow(z, 3), math.Pow(z, 5), math.Pow(z, 7)
+	return z + (z3+z)/(4*v) + (5*z5+16*z3+3*z)/(96*v*v) + (3*z7+19*z5+17*z3-15*z)/(384*v*v*v)
+}
+
+// ElasticityRequest define o período e os SKUs da estimativa
+type ElasticityRequest struct {
+	De   time.Time `json:"de"`
+	Ate  time.Time `json:"ate"`
+	Skus []string  `json:"skus,omitempty"`
+}
+
+// PriceSuggestion é o preço que maximiza o lucro com a elasticidade estimada
+type PriceSuggestion struct {
+	Sku             string              `json:"sku"`
+	PrecoAlfa       float64             `json:"preco_alfa"`
+	PrecoEquilibrio float64             `json:"preco_equilibrio"`
+	Estimativa      *ElasticityEstimate `json:"estimativa,omitempty"`
+	// PrecoOtimo é o ótimo sem limites; zero quando a demanda é inelástica
+	PrecoOtimo     float64 `json:"preco_otimo"`
+	PrecoSugerido  float64 `json:"preco_sugerido"`
+	VariacaoMaxima float64 `json:"variacao_maxima"`
+	Limitado       bool    `json:"limitado"`
+	MargemAlfa     float64 `json:"margem_alfa"`
+	MargemSugerida float64 `json:"margem_sugerida"`
+	Motivo         string  `json:"motivo"`
+}
@@ -0,0 +1,53 @@
+package entities
+
+import "time"
+
+// Dados do Firebird que podem vir do cache da última leitura
+const (
+	DadoCustoFire    = "custo_fire"
+	DadoPerfilFiscal = "perfil_fiscal"
+)
+
+// DadosDesatualizados indica que o cálculo usou o cache porque o Firebird não respondeu
+type DadosDesatualizados struct {
+	// Dados lista o que veio do cache (custo_fire, perfil_fiscal)
+	Dados []string `json:"dados"`
+	// AtualizadoEm é a leitura mais antiga usada
+	AtualizadoEm  time.Time `json:"atualizado_em"`
+	IdadeSegundos float64   `json:"idade_segundos"`
+}
+
+// AdicionarDesatualizado registra mais um dado do cache; aceita d nil
+func AdicionarDesatualizado(d *DadosDesatualizados, dado string, em, agora time.Time) *DadosDesatualizados {
+	if d == nil {
+		d = &DadosDesatualizados{AtualizadoEm: em}
+	}
+	d.Dados = append(d.Dados, dado)
+	if em.Before(d.AtualizadoEm) {
+		d.AtualizadoEm = em
+	}
+	d.IdadeSegundos = agora.Sub(d.AtualizadoEm).Seconds()
+	return d
+}
+
+// FirebirdCacheInfo descreve uma atualização do cache a partir do Firebird
+type FirebirdCacheInfo struct {
+	AtualizadoEm  time.Time `json:"atualizado_em"`
+	DuracaoMs     int64     `json:"duracao_ms"`
+	CustosFire    int       `json:"custos_fire"`
+	PerfisFiscais int       `json:"perfis_fiscais"`
+}
+
+// FirebirdCacheStatus é a situação do cache exposta na API
+type FirebirdCacheStatus struct {
+	// Ultima é nil enquanto nenhuma atualização teve sucesso nesta instância
+	Ultima              *FirebirdCacheInfo `json:"ultima,omitempty"`
+	IdadeMaximaSegundos float64            `json:"idade_maxima_segundos"`
+	Atualizacoes        int64              `json:"atualizacoes"`
+	Falhas              int64              `json:"falhas"`
+	UltimoErro          string             `json:"ultimo_erro,omitempty"`
+	// Usos conta as leituras servidas pelo cache com o Firebird indisponível
+	Usos int64 `json:"usos"`
+	// Recusas conta as leituras recusadas por falta de cache ou cache velho demais
+	Recusas int64 `json:"recusas"`
+}
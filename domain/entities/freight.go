@@ -0,0 +1,187 @@
+package entities
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FatorCubagemPadrao é o divisor rodoviário usual (cm³/kg)
+const FatorCubagemPadrao = 6000
+
+// ProdutoDimensoes guarda peso e medidas da embalagem de envio do produto
+type ProdutoDimensoes struct {
+	Produto       string  `json:"produto"`
+	PesoKg        float64 `json:"peso_kg"`
+	AlturaCm      float64 `json:"altura_cm"`
+	LarguraCm     float64 `json:"largura_cm"`
+	ComprimentoCm float64 `json:"comprimento_cm"`
+}
+
+// PesoTaxado é o maior entre o peso real e o peso cubado
+func (d ProdutoDimensoes) PesoTaxado(fatorCubagem float64) float64 {
+	return d.Carga(1).PesoTaxado(fatorCubagem)
+}
+
+// Carga é o peso e o volume de quantidade unidades do produto
+func (d ProdutoDimensoes) Carga(quantidade int) Carga {
+	q := float64(quantidade)
+	return Carga{PesoKg: d.PesoKg * q, VolumeCm3: d.AlturaCm * d.LarguraCm * d.ComprimentoCm * q}
+}
+
+// Carga é o peso e o volume de um envio; no orçamento soma todos os itens do pedido
+type Carga struct {
+	PesoKg    float64 `json:"peso_kg"`
+	VolumeCm3 float64 `json:"volume_cm3"`
+}
+
+// Somar junta duas cargas no mesmo envio
+func (c Carga) Somar(o Carga) Carga {
+	return Carga{PesoKg: c.PesoKg + o.PesoKg, VolumeCm3: c.VolumeCm3 + o.VolumeCm3}
+}
+
+// PesoTaxado é o maior entre o peso real e o peso cubado
+func (c Carga) PesoTaxado(fatorCubagem float64) float64 {
+	if fatorCubagem <= 0 {
+		fatorCubagem = FatorCubagemPadrao
+	}
+	cubado := c.VolumeCm3 / fatorCubagem
+	if cubado > c.PesoKg {
+		return cubado
+	}
+	return c.PesoKg
+}
+
+// FaixaFrete é uma linha da tabela de uma transportadora
+type FaixaFrete struct {
+	Transportadora string
+	// UF de destino; usada quando a faixa não tem intervalo de CEP
+	UF string
+	// CepInicio e CepFim delimitam a região (8 dígitos); zero quando a faixa vale para a UF toda
+	CepInicio int
+	CepFim    int
+	PesoMinKg float64
+	// PesoMaxKg zero indica faixa sem limite superior
+	PesoMaxKg        float64
+	Valor            float64
+	ValorKgExcedente float64
+	FatorCubagem     float64
+}
+
+// RegraFreteSubsidiado é a regra de frete subsidiado de um marketplace/canal
+type RegraFreteSubsidiado struct {
+	Canal string
+	// Acima do PrecoMinimo o vendedor paga PercentualVendedor do frete, abaixo PercentualVendedorAbaixo
+	PrecoMinimo              float64
+	PercentualVendedor       float64
+	PercentualVendedorAbaixo float64
+	// LimiteVendedor é o valor máximo de frete cobrado do vendedor (zero sem limite)
+	LimiteVendedor float64
+}
+
+// ValorVendedor é a parte do frete paga pelo vendedor para um preço de venda
+func (r RegraFreteSubsidiado) ValorVendedor(frete, preco float64) float64 {
+	pct := r.PercentualVendedorAbaixo
+	if preco >= r.PrecoMinimo {
+		pct = r.PercentualVendedor
+	}
+	valor := frete * pct
+	if r.LimiteVendedor > 0 && valor > r.LimiteVendedor {
+		valor = r.LimiteVendedor
+	}
+	return valor
+}
+
+// FreightTables reúne as tabelas de frete carregadas de arquivo ou banco
+type FreightTables struct {
+	Faixas      []FaixaFrete
+	Subsidios   map[string]RegraFreteSubsidiado
+	Fonte       string
+	CarregadoEm time.Time
+}
+
+// FreteCotacao é o frete escolhido para um produto e destino
+type FreteCotacao struct {
+	Transportadora string  `json:"transportadora"`
+	PesoTaxado     float64 `json:"peso_taxado"`
+	Valor          float64 `json:"valor"`
+}
+
+// NormalizarCep mantém apenas os dígitos do CEP; zero se não tiver 8 dígitos
+func NormalizarCep(cep string) int {
+	var b strings.Builder
+	for _, r := range cep {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	if b.Len() != 8 {
+		return 0
+	}
+	n, _ := strconv.Atoi(b.String())
+	return n
+}
+
+// Cotar escolhe a transportadora mais barata para a carga e o destino. Em cada
+// transportadora as faixas por CEP têm precedência sobre as faixas da UF.
+func (t FreightTables) Cotar(d Carga, uf, cep string) (FreteCotacao, bool) {
+	cepNum := NormalizarCep(cep)
+
+	type candidatos struct {
+		porCep []FreteCotacao
+		porUF  []FreteCotacao
+	}
+	porTransportadora := map[string]*candidatos{}
+
+	for _, f := range t.Faixas {
+		porCep := f.CepInicio > 0 && f.CepFim > 0
+		if porCep && (cepNum == 0 || cepNum < f.CepInicio || cepNum > f.CepFim) {
+			continue
+		}
+		if !porCep && (uf == "" || !strings.EqualFold(f.UF, uf)) {
+			continue
+		}
+
+		peso := d.PesoTaxado(f.FatorCubagem)
+		if peso < f.PesoMinKg || (f.PesoMaxKg > 0 && peso > f.PesoMaxKg) {
+			continue
+		}
+		excedente := peso - f.PesoMinKg
+		if excedente < 0 {
+			excedente = 0
+		}
+		cot := FreteCotacao{
+			Transportadora: f.Transportadora,
+			PesoTaxado:     peso,
+			Valor:          f.Valor + excedente*f.ValorKgExcedente,
+		}
+
+		c := porTransportadora[f.Transportadora]
+		if c == nil {
+			c = &candidatos{}
+			porTransportadora[f.Transportadora] = c
+		}
+		if porCep {
+			c.porCep = append(c.porCep, cot)
+		} else {
+			c.porUF = append(c.porUF, cot)
+		}
+	}
+
+	var melhor FreteCotacao
+	encontrado := false
+	for _, c := range porTransportadora {
+		opcoes := c.porCep
+		if len(opcoes) == 0 {
+			opcoes = c.porUF
+		}
+		for _, cot := range opcoes {
+			if !encontrado || cot.Valor < melhor.Valor ||
+				(cot.Valor == melhor.Valor && cot.Transportadora < melhor.Transportadora) {
+				melhor = cot
+				encontrado = true
+			}
+		}
+	}
+	return melhor, encontrado
+}
@@ -0,0 +1,57 @@
+package entities
+
+// RegraST é a MVA da substituição tributária do produto para uma UF (tabela icms_st)
+type RegraST struct {
+	Produto string  `json:"produto"`
+	UF      string  `json:"uf"`
+	Mva     float64 `json:"mva"`
+}
+
+// IcmsSt é o ICMS retido por substituição tributária na venda a contribuinte. É
+// cobrado do cliente além do preço, não compõe o custo do vendedor.
+type IcmsSt struct {
+	Mva float64 `json:"mva"`
+	// MvaAjustada é a MVA usada na base; difere da original só na venda interestadual
+	MvaAjustada     float64 `json:"mva_ajustada"`
+	BaseCalculo     float64 `json:"base_calculo"`
+	AliquotaInterna float64 `json:"aliquota_interna"`
+	IcmsProprio     float64 `json:"icms_proprio"`
+	Valor           float64 `json:"valor"`
+	Fcp             float64 `json:"fcp"`
+	PrecoComSt      float64 `json:"preco_com_st"`
+}
+
+// CalcularSt calcula o ICMS-ST e o FCP-ST unitários sobre o preço de venda, com a
+// alíquota interna e o FCP do destino e descontando o ICMS e o FCP próprios
+func CalcularSt(preco float64, regra RegraST, icms IcmsVenda, fcpProprio float64) IcmsSt {
+	st := IcmsSt{Mva: regra.Mva, MvaAjustada: regra.Mva}
+	if icms.Destino == nil {
+		return st
+	}
+	st.AliquotaInterna = icms.Destino.AliquotaInterna
+	st.MvaAjustada = MvaAjustada(regra.Mva, *icms.Destino)
+	st.BaseCalculo = preco * (1 + st.MvaAjustada)
+	st.IcmsProprio = preco * (icms.IcmsEfetivo - icms.Difal)
+
+	st.Valor = st.BaseCalculo*st.AliquotaInterna - st.IcmsProprio
+	if st.Valor < 0 {
+		st.Valor = 0
+	}
+	st.Fcp = st.BaseCalculo*icms.Destino.Fcp - preco*fcpProprio
+	if st.Fcp < 0 {
+		st.Fcp = 0
+	}
+	st.PrecoComSt = preco + st.Valor + st.Fcp
+	return st
+}
+
+// MvaAjustada compensa na venda interestadual a diferença entre a alíquota interestadual
+// e a interna do destino (Convênio ICMS 142/2018):
+// (1 + MVA original) × (1 − interestadual) / (1 − interna) − 1. Na venda interna, ou com a
+// interestadual igual ou acima da interna, vale a MVA original.
+func MvaAjustada(mva float64, dest IcmsDestino) float64 {
+	if dest.Interna || dest.AliquotaInterestadual >= dest.AliquotaInterna || dest.AliquotaInterna >= 1 {
+		return mva
+	}
+	return (1+mva)*(1-dest.AliquotaInterestadual)/(1-dest.AliquotaInterna) - 1
+}
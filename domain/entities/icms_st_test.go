@@ -0,0 +1,76 @@
+package entities
+
+import "testing"
+
+func TestMvaAjustada(t *testing.T) {
+	casos := []struct {
+		nome     string
+		mva      float64
+		dest     IcmsDestino
+		esperado float64
+	}{
+		{"interestadual 12% para interna 18%", 0.40,
+			IcmsDestino{AliquotaInterna: 0.18, AliquotaInterestadual: 0.12}, 1.4*0.88/0.82 - 1},
+		{"importado 4% para interna 18%", 0.50,
+			IcmsDestino{AliquotaInterna: 0.18, AliquotaInterestadual: 0.04}, 1.5*0.96/0.82 - 1},
+		{"venda interna mantém a original", 0.40,
+			IcmsDestino{Interna: true, AliquotaInterna: 0.18}, 0.40},
+		{"interestadual igual à interna", 0.40,
+			IcmsDestino{AliquotaInterna: 0.12, AliquotaInterestadual: 0.12}, 0.40},
+	}
+	for _, c := range casos {
+		if got := MvaAjustada(c.mva, c.dest); !quase(got, c.esperado) {
+			t.Errorf("%s: MVA ajustada = %v, esperado %v", c.nome, got, c.esperado)
+		}
+	}
+}
+
+func TestCalcularSt(t *testing.T) {
+	interestadual := IcmsVenda{
+		IcmsEfetivo: 0.18, Difal: 0.06,
+		Destino: &IcmsDestino{UF: "RJ", AliquotaInterna: 0.18, AliquotaInterestadual: 0.12, Fcp: 0.02},
+	}
+	interna := IcmsVenda{
+		IcmsEfetivo: 0.18,
+		Destino:     &IcmsDestino{UF: "SP", Interna: true, AliquotaInterna: 0.18, Fcp: 0.02},
+	}
+	mvaRJ := 1.4*0.88/0.82 - 1
+
+	casos := []struct {
+		nome       string
+		regra      RegraST
+		icms       IcmsVenda
+		fcpProprio float64
+		base       float64
+		valor      float64
+		fcp        float64
+	}{
+		// base 100 × (1 + MVA ajustada); ST = base × 18% − 12 de ICMS próprio
+		{"interestadual com MVA ajustada", RegraST{Mva: 0.40}, interestadual, 0,
+			100 * (1 + mvaRJ), 100*(1+mvaRJ)*0.18 - 12, 100 * (1 + mvaRJ) * 0.02},
+		// base 140; ST = 25,20 − 18; FCP-ST = 2,80 − 2 de FCP próprio
+		{"interna com MVA original", RegraST{Mva: 0.40}, interna, 0.02, 140, 7.2, 0.8},
+		// ICMS e FCP próprios acima da base do destino: nada a reter
+		{"ST negativo zerado", RegraST{Mva: 0}, IcmsVenda{IcmsEfetivo: 0.20, Destino: interna.Destino}, 0.03, 100, 0, 0},
+	}
+	for _, c := range casos {
+		st := CalcularSt(100, c.regra, c.icms, c.fcpProprio)
+		if !quase(st.BaseCalculo, c.base) || !quase(st.Valor, c.valor) || !quase(st.Fcp, c.fcp) {
+			t.Errorf("%s: base/ST/FCP = %v/%v/%v, esperado %v/%v/%v",
+				c.nome, st.BaseCalculo, st.Valor, st.Fcp, c.base, c.valor, c.fcp)
+		}
+		if !quase(st.PrecoComSt, 100+c.valor+c.fcp) {
+			t.Errorf("%s: preço com ST = %v", c.nome, st.PrecoComSt)
+		}
+		if st.Mva != c.regra.Mva {
+			t.Errorf("%s: MVA original alterada para %v", c.nome, st.Mva)
+		}
+	}
+}
+
+func TestCalcularStSemDestino(t *testing.T) {
+	st := CalcularSt(100, RegraST{Mva: 0.40}, IcmsVenda{IcmsEfetivo: 0.18}, 0)
+	if st.Valor != 0 || st.Fcp != 0 || st.BaseCalculo != 0 || st.MvaAjustada != 0.40 {
+		t.Errorf("ST sem destino = %+v", st)
+	}
+}
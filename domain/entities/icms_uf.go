@@ -0,0 +1,62 @@
+package entities
+
+import "fmt"
+
+// AliquotaUF são as alíquotas de ICMS e FCP de uma UF de destino (tabela icms_uf)
+type AliquotaUF struct {
+	UF              string  `json:"uf"`
+	AliquotaInterna float64 `json:"aliquota_interna"`
+	// AliquotaInterestadual vale para mercadoria nacional; a importada segue com 4%
+	AliquotaInterestadual float64 `json:"aliquota_interestadual"`
+	Fcp                   float64 `json:"fcp"`
+}
+
+// IcmsDestino descreve o ICMS da venda ajustado à UF de destino
+type IcmsDestino struct {
+	UF                    string  `json:"uf"`
+	Interna               bool    `json:"interna"`
+	AliquotaInterna       float64 `json:"aliquota_interna"`
+	AliquotaInterestadual float64 `json:"aliquota_interestadual"`
+	Fcp                   float64 `json:"fcp"`
+	Motivo                string  `json:"motivo"`
+}
+
+// ParaDestino ajusta o ICMS próprio, o Difal e o FCP à UF de destino. Na venda
+// interna não há Difal; na interestadual o ICMS próprio usa a alíquota interestadual
+// da rota e o Difal vai até a alíquota interna do destino. Com redução de base o
+// ICMS efetivo reduzido continua sendo o teto, como no cálculo pela origem.
+func (v IcmsVenda) ParaDestino(ufOrigem string, dest AliquotaUF) IcmsVenda {
+	d := IcmsDestino{UF: dest.UF, AliquotaInterna: dest.AliquotaInterna, Fcp: dest.Fcp}
+
+	if dest.UF == ufOrigem {
+		d.Interna = true
+		d.Motivo = fmt.Sprintf("venda interna em %s: ICMS efetivo de %.2f%%, sem Difal", dest.UF, v.IcmsEfetivo*100)
+		v.Difal = 0
+		v.Destino = &d
+		return v
+	}
+
+	interestadual := v.Origem.AliquotaInterestadual
+	if interestadual == AliquotaInterestadualNacional && dest.AliquotaInterestadual > 0 {
+		interestadual = dest.AliquotaInterestadual
+	}
+	d.AliquotaInterestadual = interestadual
+
+	teto := dest.AliquotaInterna
+	if v.ReducaoBase && v.IcmsEfetivo < teto {
+		teto = v.IcmsEfetivo
+	}
+	proprio := interestadual
+	if teto < proprio {
+		proprio = teto
+	}
+	v.Difal = teto - proprio
+	v.IcmsEfetivo = proprio + v.Difal
+	d.Motivo = fmt.Sprintf("venda de %s para %s: ICMS próprio de %.2f%% e Difal de %.2f%% (interna de destino %.2f%%)",
+		ufOrigem, dest.UF, proprio*100, v.Difal*100, dest.AliquotaInterna*100)
+	if v.ReducaoBase {
+		d.Motivo += "; produto com redução de base"
+	}
+	v.Destino = &d
+	return v
+}
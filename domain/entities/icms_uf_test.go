@@ -0,0 +1,55 @@
+package entities
+
+import "testing"
+
+func TestParaDestino(t *testing.T) {
+	nacional := AliquotaOrigem{AliquotaInterestadual: AliquotaInterestadualNacional}
+	importado := AliquotaOrigem{AliquotaInterestadual: AliquotaInterestadualImportado}
+
+	casos := []struct {
+		nome          string
+		venda         IcmsVenda
+		dest          AliquotaUF
+		interna       bool
+		interestadual float64
+		efetivo       float64
+		difal         float64
+	}{
+		{"venda interna sem Difal",
+			IcmsVenda{IcmsEfetivo: 0.18, Difal: 0.06, Origem: nacional},
+			AliquotaUF{UF: "SP", AliquotaInterna: 0.18, Fcp: 0.02}, true, 0, 0.18, 0},
+		{"interestadual nacional 12%",
+			IcmsVenda{IcmsEfetivo: 0.18, Origem: nacional},
+			AliquotaUF{UF: "RJ", AliquotaInterna: 0.20, Fcp: 0.02}, false, 0.12, 0.20, 0.08},
+		{"interestadual nacional pela rota de 7%",
+			IcmsVenda{IcmsEfetivo: 0.18, Origem: nacional},
+			AliquotaUF{UF: "BA", AliquotaInterna: 0.205, AliquotaInterestadual: 0.07}, false, 0.07, 0.205, 0.135},
+		{"importado segue com 4% em qualquer rota",
+			IcmsVenda{IcmsEfetivo: 0.18, Origem: importado},
+			AliquotaUF{UF: "BA", AliquotaInterna: 0.205, AliquotaInterestadual: 0.07}, false, 0.04, 0.205, 0.165},
+		{"Difal zerado com interna abaixo da interestadual",
+			IcmsVenda{IcmsEfetivo: 0.18, Origem: nacional},
+			AliquotaUF{UF: "XX", AliquotaInterna: 0.10}, false, 0.12, 0.10, 0},
+		{"redução de base limita o teto",
+			IcmsVenda{IcmsEfetivo: 0.08, ReducaoBase: true, Origem: nacional},
+			AliquotaUF{UF: "RJ", AliquotaInterna: 0.20}, false, 0.12, 0.08, 0},
+	}
+	for _, c := range casos {
+		v := c.venda.ParaDestino("SP", c.dest)
+		if v.Destino == nil {
+			t.Fatalf("%s: destino não registrado", c.nome)
+		}
+		if v.Destino.Interna != c.interna || !quase(v.Destino.AliquotaInterestadual, c.interestadual) {
+			t.Errorf("%s: destino = %+v", c.nome, *v.Destino)
+		}
+		if !quase(v.IcmsEfetivo, c.efetivo) || !quase(v.Difal, c.difal) {
+			t.Errorf("%s: ICMS efetivo/Difal = %v/%v, esperado %v/%v", c.nome, v.IcmsEfetivo, v.Difal, c.efetivo, c.difal)
+		}
+		if v.Difal < 0 {
+			t.Errorf("%s: Difal negativo %v", c.nome, v.Difal)
+		}
+		if v.Destino.Fcp != c.dest.Fcp || v.Destino.AliquotaInterna != c.dest.AliquotaInterna {
+			t.Errorf("%s: alíquotas do destino = %+v", c.nome, *v.Destino)
+		}
+	}
+}
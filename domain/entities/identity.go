@@ -0,0 +1,26 @@
+package entities
+
+// Identidade é o usuário autenticado pelo token da requisição
+type Identidade struct {
+	Usuario string   `json:"usuario"`
+	Papeis  []string `json:"papeis"`
+}
+
+// PapelPublicador libera a publicação das propostas aprovadas na tabela do ERP
+const PapelPublicador = "publicador"
+
+// PapelAdmin e PapelPricing administram os webhooks e o replay de eventos
+const (
+	PapelAdmin   = "admin"
+	PapelPricing = "pricing"
+)
+
+// TemPapel indica se o usuário tem o papel informado
+func (i Identidade) TemPapel(papel string) bool {
+	for _, p := range i.Papeis {
+		if p == papel {
+			return true
+		}
+	}
+	return false
+}
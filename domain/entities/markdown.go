@@ -0,0 +1,57 @@
+package entities
+
+import "time"
+
+// MarkdownRule aplica um desconto a partir de uma idade do estoque em dias
+type MarkdownRule struct {
+	DiasMinimos int     `json:"dias_minimos"`
+	Desconto    float64 `json:"desconto"`
+}
+
+// MarkdownRulesPadrao são as regras usadas quando não há regras cadastradas
+func MarkdownRulesPadrao() []MarkdownRule {
+	return []MarkdownRule{
+		{DiasMinimos: 90, Desconto: 0.05},
+		{DiasMinimos: 180, Desconto: 0.15},
+	}
+}
+
+// SelecionarMarkdown escolhe a regra de maior idade atendida
+func SelecionarMarkdown(regras []MarkdownRule, dias int) (MarkdownRule, bool) {
+	var escolhida MarkdownRule
+	encontrada := false
+	for _, r := range regras {
+		if dias >= r.DiasMinimos && (!encontrada || r.DiasMinimos > escolhida.DiasMinimos) {
+			escolhida = r
+			encontrada = true
+		}
+	}
+	return escolhida, encontrada
+}
+
+// StockPosition é o estoque de um produto com a data da entrada mais antiga ainda em estoque
+type StockPosition struct {
+	Produto     string    `json:"produto"`
+	Quantidade  float64   `json:"quantidade"`
+	DataEntrada time.Time `json:"data_entrada"`
+}
+
+// MarkdownProposal é uma linha da lista de remarcação proposta
+type MarkdownProposal struct {
+	Sku           string    `json:"sku"`
+	Quantidade    float64   `json:"quantidade"`
+	DataEntrada   time.Time `json:"data_entrada"`
+	DiasEstoque   int       `json:"dias_estoque"`
+	DescontoRegra float64   `json:"desconto_regra"`
+	PrecoAtual    float64   `json:"preco_atual"`
+	PrecoProposto float64   `json:"preco_proposto"`
+	// DescontoAplicado pode ser menor que o da regra quando limitado ao equilíbrio
+	DescontoAplicado     float64 `json:"desconto_aplicado"`
+	PrecoEquilibrio      float64 `json:"preco_equilibrio"`
+	LimitadoEquilibrio   bool    `json:"limitado_equilibrio"`
+	MargemAtual          float64 `json:"margem_atual"`
+	MargemProposta       float64 `json:"margem_proposta"`
+	ImpactoMargemUnidade float64 `json:"impacto_margem_unidade"`
+	ImpactoMargemTotal   float64 `json:"impacto_margem_total"`
+	Erro                 string  `json:"erro,omitempty"`
+}
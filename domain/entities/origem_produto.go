@@ -0,0 +1,143 @@
+package entities
+
+import (
+	"fmt"
+	"time"
+)
+
+// Alíquotas interestaduais de ICMS usadas no cálculo do Difal
+const (
+	AliquotaInterestadualImportado = 0.04
+	AliquotaInterestadualNacional  = 0.12
+)
+
+// LimiteConteudoImportacao é o conteúdo de importação (FCI) acima do qual
+// o produto fabricado no Brasil passa a usar a alíquota de 4% (Res. Senado 13/2012)
+const LimiteConteudoImportacao = 0.40
+
+// OrigemProduto representa um dos códigos de origem da mercadoria (tabela A do CST)
+type OrigemProduto struct {
+	Codigo    string
+	Descricao string
+	// Estrangeira indica se a origem é tratada como importada na regra de negócio
+	Estrangeira bool
+	// DependeFci indica se a alíquota interestadual depende do conteúdo de importação
+	DependeFci bool
+	// ConteudoMinimo e ConteudoMaximo delimitam a faixa de conteúdo de importação da origem
+	ConteudoMinimo float64
+	ConteudoMaximo float64
+	// ExcluidaResolucao13 marca as origens fora da regra dos 4% (lista CAMEX)
+	ExcluidaResolucao13 bool
+}
+
+// OrigensProduto é a tabela completa de origens (0 a 8)
+var OrigensProduto = map[string]OrigemProduto{
+	"0": {Codigo: "0", Descricao: "Nacional, exceto as indicadas nos códigos 3, 4, 5 e 8"},
+	"1": {Codigo: "1", Descricao: "Estrangeira - Importação direta", Estrangeira: true},
+	"2": {Codigo: "2", Descricao: "Estrangeira - Adquirida no mercado interno", Estrangeira: true},
+	"3": {Codigo: "3", Descricao: "Nacional, com conteúdo de importação superior a 40% e inferior ou igual a 70%",
+		DependeFci: true, ConteudoMinimo: 0.40, ConteudoMaximo: 0.70},
+	"4": {Codigo: "4", Descricao: "Nacional, produzida conforme processos produtivos básicos (PPB)"},
+	"5": {Codigo: "5", Descricao: "Nacional, com conteúdo de importação inferior ou igual a 40%",
+		DependeFci: true, ConteudoMinimo: 0, ConteudoMaximo: 0.40},
+	"6": {Codigo: "6", Descricao: "Estrangeira - Importação direta, sem similar nacional, constante em lista CAMEX",
+		Estrangeira: true, ExcluidaResolucao13: true},
+	"7": {Codigo: "7", Descricao: "Estrangeira - Adquirida no mercado interno, sem similar nacional, constante em lista CAMEX",
+		Estrangeira: true, ExcluidaResolucao13: true},
+	"8": {Codigo: "8", Descricao: "Nacional, com conteúdo de importação superior a 70%",
+		DependeFci: true, ConteudoMinimo: 0.70, ConteudoMaximo: 1},
+}
+
+// Fci guarda a Ficha de Conteúdo de Importação informada para o produto
+type Fci struct {
+	Produto            string    `json:"produto"`
+	Numero             string    `json:"numero"`
+	ConteudoImportacao float64   `json:"conteudo_importacao"`
+	DataEmissao        time.Time `json:"data_emissao"`
+}
+
+// AliquotaOrigem é o resultado da análise de origem com a justificativa da alíquota
+type AliquotaOrigem struct {
+	Origem                OrigemProduto `json:"-"`
+	ConteudoImportacao    *float64      `json:"conteudo_importacao"`
+	AliquotaInterestadual float64       `json:"aliquota_interestadual"`
+	Motivo                string        `json:"motivo"`
+}
+
+// OrigemPadrao é a origem assumida quando o cadastro não informa uma origem válida
+const OrigemPadrao = "0"
+
+// BuscarOrigem retorna a origem correspondente ao código informado no cadastro.
+// Código vazio ou desconhecido assume a origem nacional, como no cálculo original;
+// o retorno false indica que o padrão foi usado.
+func BuscarOrigem(codigo string) (OrigemProduto, bool) {
+	origem, ok := OrigensProduto[codigo]
+	if !ok {
+		return OrigensProduto[OrigemPadrao], false
+	}
+	return origem, true
+}
+
+// AliquotaInterestadual define a alíquota interestadual a partir da origem e,
+// quando disponível, do conteúdo de importação da FCI
+func (o OrigemProduto) AliquotaInterestadual(fci *Fci) AliquotaOrigem {
+	res := AliquotaOrigem{Origem: o}
+
+	switch {
+	case o.ExcluidaResolucao13:
+		res.AliquotaInterestadual = AliquotaInterestadualNacional
+		res.Motivo = fmt.Sprintf("origem %s consta na lista CAMEX, fora da Res. Senado 13/2012: alíquota de 12%%", o.Codigo)
+	case o.Estrangeira:
+		res.AliquotaInterestadual = AliquotaInterestadualImportado
+		res.Motivo = fmt.Sprintf("origem %s é estrangeira: alíquota de 4%%", o.Codigo)
+	case o.DependeFci && fci != nil:
+		conteudo := fci.ConteudoImportacao
+		res.ConteudoImportacao = &conteudo
+		if conteudo > LimiteConteudoImportacao {
+			res.AliquotaInterestadual = AliquotaInterestadualImportado
+			res.Motivo = fmt.Sprintf("origem %s com FCI %s e conteúdo de importação de %.2f%% (acima de 40%%): alíquota de 4%%",
+				o.Codigo, fci.Numero, conteudo*100)
+		} else {
+			res.AliquotaInterestadual = AliquotaInterestadualNacional
+			res.Motivo = fmt.Sprintf("origem %s com FCI %s e conteúdo de importação de %.2f%% (até 40%%): alíquota de 12%%",
+				o.Codigo, fci.Numero, conteudo*100)
+		}
+		if conteudo < o.ConteudoMinimo || conteudo > o.ConteudoMaximo {
+			res.Motivo += fmt.Sprintf("; atenção: conteúdo fora da faixa da origem %s, revisar cadastro", o.Codigo)
+		}
+	case o.DependeFci:
+		// Sem FCI cadastrada, vale a faixa nominal do código de origem
+		if o.ConteudoMinimo >= LimiteConteudoImportacao {
+			res.AliquotaInterestadual = AliquotaInterestadualImportado
+			res.Motivo = fmt.Sprintf("origem %s sem FCI cadastrada, faixa nominal acima de 40%%: alíquota de 4%%", o.Codigo)
+		} else {
+			res.AliquotaInterestadual = AliquotaInterestadualNacional
+			res.Motivo = fmt.Sprintf("origem %s sem FCI cadastrada, faixa nominal até 40%%: alíquota de 12%%", o.Codigo)
+		}
+	default:
+		res.AliquotaInterestadual = AliquotaInterestadualNacional
+		res.Motivo = fmt.Sprintf("origem %s é nacional: alíquota de 12%%", o.Codigo)
+	}
+
+	return res
+}
+
+// IcmsVenda reúne o ICMS efetivo, o Difal e a análise de origem usada no cálculo
+type IcmsVenda struct {
+	IcmsEfetivo float64
+	Difal       float64
+	Origem      AliquotaOrigem
+	// ReducaoBase indica o ICMS efetivo reduzido pelo perfil de imposto
+	ReducaoBase bool
+	// Destino é nil quando o cálculo não tem UF de destino com alíquotas cadastradas
+	Destino *IcmsDestino
+}
+
+// PerfilFiscal são os dados do ERP usados no ICMS de venda de um produto
+type PerfilFiscal struct {
+	Produto int `json:"produto"`
+	// ReducaoIcms é a soma de RED_ICMS do perfil de imposto; TemReducao é falso quando não há linhas
+	ReducaoIcms float64 `json:"reducao_icms"`
+	TemReducao  bool    `json:"tem_reducao"`
+	OrigemProd  string  `json:"origem_prod"`
+}
@@ -0,0 +1,78 @@
+package entities
+
+import "testing"
+
+func TestAliquotaInterestadualSemFci(t *testing.T) {
+	casos := []struct {
+		origem   string
+		aliquota float64
+	}{
+		{"0", AliquotaInterestadualNacional},
+		{"1", AliquotaInterestadualImportado},
+		{"2", AliquotaInterestadualImportado},
+		// 3 e 8 usam a faixa nominal acima de 40%; 5, a faixa até 40%
+		{"3", AliquotaInterestadualImportado},
+		{"4", AliquotaInterestadualNacional},
+		{"5", AliquotaInterestadualNacional},
+		// lista CAMEX fica fora da Res. Senado 13/2012
+		{"6", AliquotaInterestadualNacional},
+		{"7", AliquotaInterestadualNacional},
+		{"8", AliquotaInterestadualImportado},
+	}
+	for _, c := range casos {
+		o, ok := BuscarOrigem(c.origem)
+		if !ok {
+			t.Fatalf("origem %s não encontrada", c.origem)
+		}
+		res := o.AliquotaInterestadual(nil)
+		if res.AliquotaInterestadual != c.aliquota {
+			t.Errorf("origem %s: alíquota = %v, esperado %v (%s)", c.origem, res.AliquotaInterestadual, c.aliquota, res.Motivo)
+		}
+		if res.ConteudoImportacao != nil {
+			t.Errorf("origem %s: conteúdo de importação sem FCI = %v", c.origem, *res.ConteudoImportacao)
+		}
+	}
+}
+
+func TestAliquotaInterestadualComFci(t *testing.T) {
+	casos := []struct {
+		origem   string
+		conteudo float64
+		aliquota float64
+	}{
+		{"5", 0.3999, AliquotaInterestadualNacional},
+		{"5", 0.40, AliquotaInterestadualNacional},
+		{"5", 0.4001, AliquotaInterestadualImportado},
+		{"3", 0.3999, AliquotaInterestadualNacional},
+		{"3", 0.40, AliquotaInterestadualNacional},
+		{"3", 0.4001, AliquotaInterestadualImportado},
+		{"8", 0.3999, AliquotaInterestadualNacional},
+		{"8", 0.40, AliquotaInterestadualNacional},
+		{"8", 0.4001, AliquotaInterestadualImportado},
+		// a FCI só vale para as origens 3, 5 e 8
+		{"0", 0.90, AliquotaInterestadualNacional},
+		{"1", 0.10, AliquotaInterestadualImportado},
+		{"6", 0.90, AliquotaInterestadualNacional},
+	}
+	for _, c := range casos {
+		o, _ := BuscarOrigem(c.origem)
+		fci := &Fci{Numero: "FCI-1", ConteudoImportacao: c.conteudo}
+		res := o.AliquotaInterestadual(fci)
+		if res.AliquotaInterestadual != c.aliquota {
+			t.Errorf("origem %s com FCI %.4f: alíquota = %v, esperado %v (%s)",
+				c.origem, c.conteudo, res.AliquotaInterestadual, c.aliquota, res.Motivo)
+		}
+		if o.DependeFci && (res.ConteudoImportacao == nil || *res.ConteudoImportacao != c.conteudo) {
+			t.Errorf("origem %s: conteúdo de importação não registrado", c.origem)
+		}
+	}
+}
+
+func TestBuscarOrigemPadrao(t *testing.T) {
+	for _, codigo := range []string{"", "9", "X"} {
+		o, ok := BuscarOrigem(codigo)
+		if ok || o.Codigo != OrigemPadrao {
+			t.Errorf("origem %q = %s/%v, esperado %s/false", codigo, o.Codigo, ok, OrigemPadrao)
+		}
+	}
+}
@@ -13,4 +13,48 @@ type Parameters struct{
 	Fcp float64
 	RedutorPadrao float64
 	type_price string
-}
\ No newline at end of file
+}
+
+// ParametersOverride altera só os parâmetros informados, mantendo os demais do config_params
+type ParametersOverride struct {
+	LucroAdicionalDesejado *float64 `json:"lucro_adicional_desejado"`
+	LucroPadraoDesejado    *float64 `json:"lucro_padrao_desejado"`
+	ImpostoFederal         *float64 `json:"imposto_federal"`
+	Operacao               *float64 `json:"operacao"`
+	CustoFixo              *float64 `json:"custo_fixo"`
+	AliquotaPis            *float64 `json:"aliquota_pis"`
+	AliquotaCofins         *float64 `json:"aliquota_cofins"`
+	Rebate                 *float64 `json:"rebate"`
+	Fcp                    *float64 `json:"fcp"`
+	RedutorPadrao          *float64 `json:"redutor_padrao"`
+}
+
+// Aplicar devolve os parâmetros base com os valores informados substituídos
+func (o ParametersOverride) Aplicar(base Parameters) Parameters {
+	campos := []struct {
+		valor   *float64
+		destino *float64
+	}{
+		{o.LucroAdicionalDesejado, &base.LucroAdicionalDesejado},
+		{o.LucroPadraoDesejado, &base.LucroPadraoDesejado},
+		{o.ImpostoFederal, &base.ImpostoFederal},
+		{o.Operacao, &base.Operacao},
+		{o.CustoFixo, &base.CustoFixo},
+		{o.AliquotaPis, &base.AliquotaPis},
+		{o.AliquotaCofins, &base.AliquotaCofins},
+		{o.Rebate, &base.Rebate},
+		{o.Fcp, &base.Fcp},
+		{o.RedutorPadrao, &base.RedutorPadrao},
+	}
+	for _, c := range campos {
+		if c.valor != nil {
+			*c.destino = *c.valor
+		}
+	}
+	return base
+}
+
+// Vazio indica que nenhum parâmetro foi informado
+func (o ParametersOverride) Vazio() bool {
+	return o == ParametersOverride{}
+}
@@ -0,0 +1,68 @@
+package entities
+
+import "math"
+
+// Meios de pagamento
+const (
+	MeioPix     = "pix"
+	MeioBoleto  = "boleto"
+	MeioDebito  = "debito"
+	MeioCredito = "credito"
+)
+
+// CanalPadrao identifica os perfis que valem para canais sem configuração própria
+const CanalPadrao = "padrao"
+
+// PaymentProfile é o custo de um meio de pagamento em um canal
+type PaymentProfile struct {
+	Canal                 string  `json:"canal"`
+	MeioPagamento         string  `json:"meio_pagamento"`
+	Taxa                  float64 `json:"taxa"`
+	ParcelasSemJuros      int     `json:"parcelas_sem_juros"`
+	TaxaAntecipacaoMensal float64 `json:"taxa_antecipacao_mensal"`
+}
+
+// CustoFinanceiro é o custo de receber o parcelado sem juros à vista: a diferença entre
+// o valor nominal e o valor presente das parcelas descontadas à taxa de antecipação
+func (p PaymentProfile) CustoFinanceiro() float64 {
+	n := p.ParcelasSemJuros
+	if n <= 1 || p.TaxaAntecipacaoMensal <= 0 {
+		return 0
+	}
+	var vp float64
+	for k := 1; k <= n; k++ {
+		vp += 1 / math.Pow(1+p.TaxaAntecipacaoMensal, float64(k))
+	}
+	return 1 - vp/float64(n)
+}
+
+// Custo é o percentual total do preço consumido pelo meio de pagamento
+func (p PaymentProfile) Custo() float64 {
+	return p.Taxa + p.CustoFinanceiro()
+}
+
+// Parcelas devolve a quantidade de parcelas sem juros (mínimo 1)
+func (p PaymentProfile) Parcelas() int {
+	if p.ParcelasSemJuros < 1 {
+		return 1
+	}
+	return p.ParcelasSemJuros
+}
+
+// SelecionarPagamentos escolhe o perfil à vista de menor custo e o perfil de crédito
+// com mais parcelas sem juros
+func SelecionarPagamentos(perfis []PaymentProfile) (aVista *PaymentProfile, parcelado *PaymentProfile) {
+	for i := range perfis {
+		p := perfis[i]
+		if p.MeioPagamento == MeioCredito {
+			if parcelado == nil || p.Parcelas() > parcelado.Parcelas() {
+				parcelado = &p
+			}
+			continue
+		}
+		if aVista == nil || p.Custo() < aVista.Custo() {
+			aVista = &p
+		}
+	}
+	return aVista, parcelado
+}
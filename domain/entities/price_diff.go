@@ -0,0 +1,38 @@
+package entities
+
+// TabelaPrecoPadrao é a tabela de preços do ERP correspondente ao preço alfa
+const TabelaPrecoPadrao = "U02"
+
+// ErpPrice é o preço vigente de um produto em uma tabela de preços do ERP
+type ErpPrice struct {
+	Sku          string  `json:"sku"`
+	Produto      int     `json:"produto"`
+	Departamento int     `json:"departamento"`
+	Tabela       string  `json:"tabela"`
+	Preco        float64 `json:"preco"`
+}
+
+// PriceDiffFilter seleciona os itens do relatório de diferenças
+type PriceDiffFilter struct {
+	Tabela string `json:"tabela"`
+	// Departamento zero considera todos os departamentos
+	Departamento int `json:"departamento,omitempty"`
+	// Skus restringe o relatório; vazio considera toda a tabela
+	Skus []string `json:"skus,omitempty"`
+	// Limite é a variação absoluta mínima (0,03 = 3%) para o item entrar no relatório
+	Limite float64 `json:"limite"`
+}
+
+// PriceDiff compara o preço do ERP com o preço proposto pelo serviço
+type PriceDiff struct {
+	Sku           string  `json:"sku"`
+	Departamento  int     `json:"departamento"`
+	Tabela        string  `json:"tabela"`
+	PrecoAtual    float64 `json:"preco_atual"`
+	PrecoProposto float64 `json:"preco_proposto"`
+	// Variacao é a diferença do proposto sobre o atual (0,05 = 5% acima)
+	Variacao       float64 `json:"variacao"`
+	MargemAtual    float64 `json:"margem_atual"`
+	MargemProposta float64 `json:"margem_proposta"`
+	Erro           string  `json:"erro,omitempty"`
+}
@@ -0,0 +1,85 @@
+package entities
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Tipos de evento do outbox
+const (
+	// EventoPrecoAlterado é gravado quando o preço calculado de um produto muda
+	EventoPrecoAlterado = "price.changed"
+	// EventoPrecoPublicado é gravado quando uma gravação no ERP é aplicada ou revertida
+	EventoPrecoPublicado = "price.published"
+)
+
+// Situações da entrega de um evento a um destino
+const (
+	EntregaPendente = "pendente"
+	EntregaEntregue = "entregue"
+	// EntregaFalhou é a mensagem morta: esgotou as tentativas e só volta com replay
+	EntregaFalhou = "falhou"
+)
+
+// PriceChangedData é o conteúdo do evento price.changed
+type PriceChangedData struct {
+	Produto string `json:"produto"`
+	// PrecoAnterior é nulo no primeiro cálculo do produto
+	PrecoAnterior *float64  `json:"preco_anterior"`
+	Preco         float64   `json:"preco"`
+	PrecoAVista   float64   `json:"preco_a_vista"`
+	Motivo        string    `json:"motivo"`
+	CalculadoEm   time.Time `json:"calculado_em"`
+}
+
+// PricePublishedData é o conteúdo do evento price.published, um por item da gravação.
+// Na reversão, Preco volta ao valor anterior e PrecoAnterior é o que foi desfeito.
+type PricePublishedData struct {
+	Produto       string    `json:"produto"`
+	Tabela        string    `json:"tabela"`
+	PrecoAnterior float64   `json:"preco_anterior"`
+	Preco         float64   `json:"preco"`
+	Gravacao      string    `json:"gravacao"`
+	Situacao      string    `json:"situacao"`
+	PublicadoEm   time.Time `json:"publicado_em"`
+}
+
+// PriceEvent é um evento do outbox; é também o corpo JSON enviado no webhook
+type PriceEvent struct {
+	ID       int64           `json:"id"`
+	Tipo     string          `json:"tipo"`
+	Produto  string          `json:"produto"`
+	CriadoEm time.Time       `json:"criado_em"`
+	Dados    json.RawMessage `json:"dados"`
+}
+
+// WebhookSubscription é um destino dos webhooks
+type WebhookSubscription struct {
+	ID   int64  `json:"id"`
+	Nome string `json:"nome"`
+	URL  string `json:"url"`
+	// Segredo da assinatura HMAC; só é aceito na criação e nunca devolvido
+	Segredo  string    `json:"segredo,omitempty"`
+	Ativo    bool      `json:"ativo"`
+	CriadoEm time.Time `json:"criado_em"`
+}
+
+// WebhookDelivery é a entrega de um evento a um destino
+type WebhookDelivery struct {
+	Evento           PriceEvent `json:"evento"`
+	AssinaturaID     int64      `json:"assinatura_id"`
+	URL              string     `json:"url"`
+	Segredo          string     `json:"-"`
+	Status           string     `json:"status"`
+	Tentativas       int        `json:"tentativas"`
+	ProximaTentativa time.Time  `json:"proxima_tentativa"`
+	UltimoErro       string     `json:"ultimo_erro,omitempty"`
+}
+
+// EventReplayRequest escolhe os eventos a reenviar: por id ou a partir de uma data.
+// AssinaturaID limita a um destino; sem ele todos os destinos ativos recebem de novo.
+type EventReplayRequest struct {
+	EventoIDs    []int64   `json:"evento_ids"`
+	Desde        time.Time `json:"desde"`
+	AssinaturaID int64     `json:"assinatura_id"`
+}
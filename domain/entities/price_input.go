@@ -17,6 +17,14 @@ type PriceInput struct{
 	IcmsMinas float64
 	IcmsTriangular float64
 	Difal float64
+	// DifalDestinatario indica venda a contribuinte: o Difal fica a cargo do cliente
+	DifalDestinatario bool
+	// CustoPagamento é o percentual do preço consumido pelo meio de pagamento
+	CustoPagamento float64
+	// RebateAcordo é o percentual do acordo com o fornecedor sobre o custo NF
+	RebateAcordo float64
+	// Componentes de devolução, chargeback, embalagem, fulfillment e provisão
+	Componentes CostComponents
 }
 
 // CalculationDetails contém os detalhes do cálculo
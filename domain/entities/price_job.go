@@ -0,0 +1,101 @@
+package entities
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Situações de um job de cálculo em lote
+const (
+	JobPendente   = "pendente"
+	JobExecutando = "executando"
+	JobConcluido  = "concluido"
+	JobCancelado  = "cancelado"
+	// JobFalhou é o relatório encerrado com erro
+	JobFalhou = "falhou"
+)
+
+// Tipos de job: o cálculo em lote tem um item por SKU; os relatórios guardam o
+// resultado inteiro no próprio job
+const (
+	JobPreco         = "preco"
+	JobDiferencaErp  = "diferenca_erp"
+	JobAnaliseMargem = "analise_margem"
+	JobElasticidade  = "elasticidade"
+)
+
+// Situações de um item do job
+const (
+	ItemPendente = "pendente"
+	ItemOk       = "ok"
+	ItemErro     = "erro"
+)
+
+// PriceJobRequest é o pedido de cálculo em lote; as opções valem para todos os SKUs
+type PriceJobRequest struct {
+	Usuario     string   `json:"usuario"`
+	Skus        []string `json:"skus,omitempty"`
+	TipoCliente string   `json:"tipo_cliente,omitempty"`
+	Quantidade  int      `json:"quantidade,omitempty"`
+	UF          string   `json:"uf,omitempty"`
+	Canal       string   `json:"canal,omitempty"`
+}
+
+// PriceJobError é um SKU que falhou no job
+type PriceJobError struct {
+	Sku  string `json:"sku"`
+	Erro string `json:"erro"`
+}
+
+// PriceJob é um job de cálculo em lote com o progresso
+type PriceJob struct {
+	ID         string          `json:"id"`
+	Tipo       string          `json:"tipo"`
+	Status     string          `json:"status"`
+	Usuario    string          `json:"usuario"`
+	Parametros PriceJobRequest `json:"parametros"`
+	// Filtro é o pedido do relatório; vazio no cálculo em lote
+	Filtro      json.RawMessage `json:"filtro,omitempty"`
+	Total       int             `json:"total"`
+	Processados int             `json:"processados"`
+	Erros       int             `json:"erros"`
+	// Progresso é a fração processada, de 0 a 1
+	Progresso   float64    `json:"progresso"`
+	CriadoEm    time.Time  `json:"criado_em"`
+	IniciadoEm  *time.Time `json:"iniciado_em,omitempty"`
+	ConcluidoEm *time.Time `json:"concluido_em,omitempty"`
+	// PrevisaoTermino estima o fim pelo ritmo da execução atual
+	PrevisaoTermino *time.Time      `json:"previsao_termino,omitempty"`
+	PrimeirosErros  []PriceJobError `json:"primeiros_erros,omitempty"`
+	// Erro é o motivo da falha do relatório
+	Erro string `json:"erro,omitempty"`
+
+	RetomadoEm            *time.Time `json:"-"`
+	ProcessadosNaRetomada int        `json:"-"`
+}
+
+// CalcularProgresso preenche o progresso e, em execução, a previsão de término
+func (j *PriceJob) CalcularProgresso(agora time.Time) {
+	if j.Total > 0 {
+		j.Progresso = float64(j.Processados) / float64(j.Total)
+	}
+	j.PrevisaoTermino = nil
+	feitos := j.Processados - j.ProcessadosNaRetomada
+	if j.Status != JobExecutando || j.RetomadoEm == nil || feitos <= 0 {
+		return
+	}
+	porItem := agora.Sub(*j.RetomadoEm) / time.Duration(feitos)
+	fim := agora.Add(porItem * time.Duration(j.Total-j.Processados))
+	j.PrevisaoTermino = &fim
+}
+
+// PriceJobItem é o resultado de um SKU do job
+type PriceJobItem struct {
+	Posicao         int     `json:"posicao"`
+	Sku             string  `json:"sku"`
+	Status          string  `json:"status"`
+	Preco           float64 `json:"preco"`
+	PrecoAVista     float64 `json:"preco_a_vista"`
+	PrecoEquilibrio float64 `json:"preco_equilibrio"`
+	Erro            string  `json:"erro,omitempty"`
+}
@@ -0,0 +1,162 @@
+package entities
+
+import (
+	"math"
+	"time"
+)
+
+// Situações de uma proposta de preço
+const (
+	PropostaRascunho  = "rascunho"
+	PropostaPendente  = "pendente"
+	PropostaAprovada  = "aprovada"
+	PropostaRejeitada = "rejeitada"
+	PropostaPublicada = "publicada"
+)
+
+// Ações do fluxo de aprovação
+const (
+	AcaoSubmeter = "submeter"
+	AcaoAprovar  = "aprovar"
+	AcaoRejeitar = "rejeitar"
+	AcaoPublicar = "publicar"
+)
+
+// UsuarioSistema identifica as transições automáticas
+const UsuarioSistema = "sistema"
+
+// Origens das propostas criadas pelo recálculo automático
+const (
+	OrigemRecalculo = "recalculo"
+	OrigemMarkdown  = "markdown"
+)
+
+// transicoesProposta define, por situação, a situação resultante de cada ação
+var transicoesProposta = map[string]map[string]string{
+	PropostaRascunho: {AcaoSubmeter: PropostaPendente},
+	PropostaPendente: {AcaoAprovar: PropostaAprovada, AcaoRejeitar: PropostaRejeitada},
+	PropostaAprovada: {AcaoPublicar: PropostaPublicada},
+}
+
+// ProximoStatusProposta devolve a situação após a ação; false se a transição não é permitida
+func ProximoStatusProposta(status, acao string) (string, bool) {
+	proximo, ok := transicoesProposta[status][acao]
+	return proximo, ok
+}
+
+// PriceProposal é um preço proposto aguardando o fluxo de aprovação
+type PriceProposal struct {
+	ID     string `json:"id"`
+	Sku    string `json:"sku"`
+	Tabela string `json:"tabela"`
+	// Origem descreve quem gerou a proposta (markdown, concorrencia, manual...)
+	Origem        string  `json:"origem"`
+	PrecoAtual    float64 `json:"preco_atual"`
+	PrecoProposto float64 `json:"preco_proposto"`
+	Variacao      float64 `json:"variacao"`
+	// PapelExigido é o papel do revisor que pode aprovar; vazio é aprovação automática
+	PapelExigido string          `json:"papel_exigido"`
+	Status       string          `json:"status"`
+	CriadoPor    string          `json:"criado_por"`
+	CriadoEm     time.Time       `json:"criado_em"`
+	AtualizadoEm time.Time       `json:"atualizado_em"`
+	GravacaoID   string          `json:"gravacao_id,omitempty"`
+	Eventos      []ProposalEvent `json:"eventos,omitempty"`
+}
+
+// ProposalEvent registra uma transição da proposta
+type ProposalEvent struct {
+	De         string    `json:"de"`
+	Para       string    `json:"para"`
+	Usuario    string    `json:"usuario"`
+	Papel      string    `json:"papel,omitempty"`
+	Comentario string    `json:"comentario,omitempty"`
+	Em         time.Time `json:"em"`
+}
+
+// ApprovalRule define o papel exigido até uma variação absoluta; VariacaoAte nil não tem limite
+type ApprovalRule struct {
+	VariacaoAte  *float64 `json:"variacao_ate"`
+	PapelExigido string   `json:"papel_exigido"`
+}
+
+// RegrasAprovacaoPadrao: até 3% aprova automaticamente, acima exige gerente
+func RegrasAprovacaoPadrao() []ApprovalRule {
+	limite := 0.03
+	return []ApprovalRule{
+		{VariacaoAte: &limite},
+		{PapelExigido: "gerente"},
+	}
+}
+
+// PapelExigido escolhe a regra de menor limite que cobre a variação.
+// Sem regra aplicável vale a regra sem limite ou, sem ela, a de maior limite.
+func PapelExigido(regras []ApprovalRule, variacao float64) string {
+	variacao = math.Abs(variacao)
+	melhor := -1
+	semLimite := -1
+	maior := -1
+	for i, r := range regras {
+		if r.VariacaoAte == nil {
+			semLimite = i
+			continue
+		}
+		if maior < 0 || *r.VariacaoAte > *regras[maior].VariacaoAte {
+			maior = i
+		}
+		if variacao <= *r.VariacaoAte && (melhor < 0 || *r.VariacaoAte < *regras[melhor].VariacaoAte) {
+			melhor = i
+		}
+	}
+	switch {
+	case melhor >= 0:
+		return regras[melhor].PapelExigido
+	case semLimite >= 0:
+		return regras[semLimite].PapelExigido
+	case maior >= 0:
+		return regras[maior].PapelExigido
+	}
+	return ""
+}
+
+// ProposalDraft é um item para criar uma proposta; tabela vazia usa a TabelaPrecoPadrao
+type ProposalDraft struct {
+	Sku           string  `json:"sku"`
+	Tabela        string  `json:"tabela"`
+	PrecoProposto float64 `json:"preco_proposto"`
+	Origem        string  `json:"origem"`
+}
+
+// ProposalCreateRequest cria propostas em rascunho ou já submetidas.
+// Nos pedidos de proposta o usuário e os papéis vêm da autenticação, nunca do corpo;
+// o comentário é obrigatório ao submeter.
+type ProposalCreateRequest struct {
+	Usuario    string          `json:"-"`
+	Submeter   bool            `json:"submeter"`
+	Comentario string          `json:"comentario"`
+	Itens      []ProposalDraft `json:"itens"`
+}
+
+// ProposalTransitionRequest aplica uma ação (submeter, aprovar, rejeitar) às propostas
+type ProposalTransitionRequest struct {
+	Ids        []string `json:"ids"`
+	Acao       string   `json:"acao"`
+	Usuario    string   `json:"-"`
+	Papeis     []string `json:"-"`
+	Comentario string   `json:"comentario"`
+}
+
+// ProposalPublishRequest publica as propostas aprovadas na tabela de preços do ERP
+type ProposalPublishRequest struct {
+	Ids     []string `json:"ids"`
+	Usuario string   `json:"-"`
+	Papeis  []string `json:"-"`
+	DryRun  bool     `json:"dry_run"`
+}
+
+// ProposalResult é o resultado da ação para uma proposta
+type ProposalResult struct {
+	ID     string `json:"id"`
+	Status string `json:"status,omitempty"`
+	Erro   string `json:"erro,omitempty"`
+}
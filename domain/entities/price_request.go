@@ -0,0 +1,139 @@
+package entities
+
+import "time"
+
+// Tipos de cliente aceitos pela API de preço
+const (
+	// ClienteConsumidorFinal é o não contribuinte de ICMS (Difal recolhido pelo vendedor)
+	ClienteConsumidorFinal = "consumidor_final"
+	// ClienteContribuinte é a empresa contribuinte de ICMS (Difal de responsabilidade do destinatário)
+	ClienteContribuinte = "contribuinte"
+)
+
+// TipoClienteValido informa se o tipo de cliente é conhecido
+func TipoClienteValido(tipo string) bool {
+	return tipo == ClienteConsumidorFinal || tipo == ClienteContribuinte
+}
+
+// PriceRequest reúne os dados informados para o cálculo de um preço
+type PriceRequest struct {
+	Sku         string
+	UserPrice   float64
+	TipoCliente string
+	Quantidade  int
+	// UF e CEP de destino da venda, quando conhecidos
+	UF  string
+	Cep string
+	// Canal de venda (ex.: marketplace), usado nas regras de frete subsidiado
+	Canal string
+	// DataCusto calcula com o custo médio vigente na data; zero usa o índice atual
+	DataCusto time.Time
+	// BaseCusto escolhe a base de custo; vazio usa a do departamento ou o custo médio
+	BaseCusto string
+	// Parametros substitui os parâmetros do config_params (simulações e backtesting)
+	Parametros *Parameters
+}
+
+// FaixaVolume define o lucro desejado a partir de uma quantidade mínima
+type FaixaVolume struct {
+	TipoCliente      string  `json:"tipo_cliente"`
+	QuantidadeMinima int     `json:"quantidade_minima"`
+	LucroDesejado    float64 `json:"lucro_desejado"`
+}
+
+// reducoesFaixaPadrao aplica sobre o lucro padrão quando não há faixas cadastradas
+var reducoesFaixaPadrao = []struct {
+	quantidade int
+	fator      float64
+}{
+	{1, 1.00},
+	{10, 0.85},
+	{50, 0.70},
+	{100, 0.50},
+}
+
+// FaixasVolumePadrao gera as faixas 1, 10, 50 e 100 unidades com lucro decrescente
+func FaixasVolumePadrao(tipoCliente string, lucroPadrao float64) []FaixaVolume {
+	faixas := make([]FaixaVolume, 0, len(reducoesFaixaPadrao))
+	for _, r := range reducoesFaixaPadrao {
+		faixas = append(faixas, FaixaVolume{
+			TipoCliente:      tipoCliente,
+			QuantidadeMinima: r.quantidade,
+			LucroDesejado:    lucroPadrao * r.fator,
+		})
+	}
+	return faixas
+}
+
+// SelecionarFaixa retorna a faixa de maior quantidade mínima atendida pela quantidade.
+// As faixas devem estar em ordem crescente de quantidade mínima.
+func SelecionarFaixa(faixas []FaixaVolume, quantidade int) (FaixaVolume, bool) {
+	var escolhida FaixaVolume
+	encontrada := false
+	for _, f := range faixas {
+		if quantidade >= f.QuantidadeMinima {
+			escolhida = f
+			encontrada = true
+		}
+	}
+	return escolhida, encontrada
+}
+
+// PriceResult é o resultado completo de um cálculo de preço
+type PriceResult struct {
+	Sku           string
+	Request       PriceRequest
+	Input         PriceInput
+	Params        Parameters
+	Cost          CostFire
+	Icms          IcmsVenda
+	Faixa         FaixaVolume
+	ValorFinal    float64
+	LucroSimulado float64
+	// PrecoAVista usa o meio de pagamento à vista; ValorFinal é o preço parcelado
+	PrecoAVista  float64
+	Parcelas     int
+	ValorParcela float64
+	// CustoUnitarioTotal é o numerador do cálculo (custo, componentes por unidade e frete)
+	CustoUnitarioTotal float64
+	// PrecoEquilibrio é o preço com lucro zero (break-even)
+	PrecoEquilibrio float64
+	// St é o ICMS-ST cobrado do cliente contribuinte, nil quando não há substituição
+	St *IcmsSt
+	// Dimensoes é nil quando o produto não tem peso e medidas cadastrados
+	Dimensoes *ProdutoDimensoes
+	// Snapshot é a carga em memória usada no cálculo, nil quando os dados vieram dos bancos
+	Snapshot *SnapshotInfo
+	// Desatualizado é nil quando nenhum dado veio do cache do Firebird
+	Desatualizado *DadosDesatualizados
+	// Detalhes é o rastro do cálculo devolvido pela API
+	Detalhes map[string]interface{}
+}
+
+// PriceListItem é uma linha da lista de preços B2B por segmento
+type PriceListItem struct {
+	Sku         string           `json:"sku"`
+	TipoCliente string           `json:"tipo_cliente"`
+	Faixas      []PriceListFaixa `json:"faixas"`
+	Erro        string           `json:"erro,omitempty"`
+	// SnapshotIdadeSegundos é a idade dos dados quando a lista usa o modo snapshot
+	SnapshotIdadeSegundos float64 `json:"snapshot_idade_segundos,omitempty"`
+	// Desatualizado informa os dados do cache usados com o Firebird fora do ar
+	Desatualizado *DadosDesatualizados `json:"desatualizado,omitempty"`
+}
+
+// PriceListFaixa é o preço de uma faixa de quantidade na lista de preços
+type PriceListFaixa struct {
+	QuantidadeMinima int     `json:"quantidade_minima"`
+	LucroDesejado    float64 `json:"lucro_desejado"`
+	Preco            float64 `json:"preco"`
+}
+
+// MargemAoPreco é o lucro sobre o preço de venda obtido vendendo ao preço informado,
+// com os mesmos custos e impostos do cálculo; no preço calculado é igual ao lucro desejado
+func (r PriceResult) MargemAoPreco(preco float64) float64 {
+	if preco <= 0 || r.PrecoEquilibrio <= 0 {
+		return 0
+	}
+	return r.CustoUnitarioTotal * (1/r.PrecoEquilibrio - 1/preco)
+}
@@ -0,0 +1,55 @@
+package entities
+
+import "time"
+
+// Situações de uma gravação de preços no ERP
+const (
+	GravacaoPendente  = "pendente"
+	GravacaoSimulada  = "simulada"
+	GravacaoAplicada  = "aplicada"
+	GravacaoFalhou    = "falhou"
+	GravacaoRevertida = "revertida"
+)
+
+// PriceWriteItem é um preço aprovado a gravar; tabela vazia usa a TabelaPrecoPadrao
+type PriceWriteItem struct {
+	Sku    string  `json:"sku"`
+	Tabela string  `json:"tabela"`
+	Preco  float64 `json:"preco"`
+}
+
+// PriceWriteRequest é um lote de preços gravado em uma única transação
+type PriceWriteRequest struct {
+	Usuario string           `json:"usuario"`
+	DryRun  bool             `json:"dry_run"`
+	Itens   []PriceWriteItem `json:"itens"`
+}
+
+// PriceWriteChange é a alteração de um item com o preço antes e depois
+type PriceWriteChange struct {
+	Sku           string  `json:"sku"`
+	Produto       int     `json:"produto"`
+	Tabela        string  `json:"tabela"`
+	PrecoAnterior float64 `json:"preco_anterior"`
+	PrecoNovo     float64 `json:"preco_novo"`
+}
+
+// PriceWriteRun registra uma gravação de preços e permite revertê-la
+type PriceWriteRun struct {
+	ID           string             `json:"id"`
+	CriadoEm     time.Time          `json:"criado_em"`
+	Usuario      string             `json:"usuario"`
+	DryRun       bool               `json:"dry_run"`
+	Status       string             `json:"status"`
+	Erro         string             `json:"erro,omitempty"`
+	RevertidoPor string             `json:"revertido_por,omitempty"`
+	Alteracoes   []PriceWriteChange `json:"alteracoes"`
+	// Inalterados são os itens já com o preço pedido, que não geram gravação
+	Inalterados []string `json:"inalterados,omitempty"`
+}
+
+// RunPublication é uma gravação na fila de publicação do BI
+type RunPublication struct {
+	RunID      string
+	Tentativas int
+}
@@ -0,0 +1,208 @@
+package entities
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// NotaFiscalCompra é a NF-e de compra lida do XML
+type NotaFiscalCompra struct {
+	Chave        string
+	Numero       string
+	Serie        string
+	EmitenteCNPJ string
+	EmitenteNome string
+	DataEmissao  time.Time
+	Itens        []NotaFiscalItem
+}
+
+// NotaFiscalItem é um item (det) da NF-e com os valores totais da linha
+type NotaFiscalItem struct {
+	NumeroItem       int
+	CodigoFornecedor string
+	EAN              string
+	Descricao        string
+	NCM              string
+	CFOP             string
+	Unidade          string
+	Origem           string
+	Quantidade       float64
+	ValorProduto     float64
+	Frete            float64
+	Seguro           float64
+	Desconto         float64
+	Outros           float64
+	Icms             float64
+	IcmsSt           float64
+	Ipi              float64
+	Pis              float64
+	Cofins           float64
+}
+
+// CfopsCompra são os CFOPs de saída do fornecedor que representam compra para revenda
+// (venda de produção ou de mercadoria, com ou sem ST, entrega futura e por conta e
+// ordem) e os de entrada de importação. Devolução, transferência, bonificação e
+// remessas ficam fora do custo médio.
+var CfopsCompra = map[string]bool{
+	"5101": true, "5102": true, "5103": true, "5104": true, "5105": true, "5106": true,
+	"5116": true, "5117": true, "5118": true, "5119": true, "5120": true, "5122": true, "5123": true,
+	"5401": true, "5402": true, "5403": true, "5405": true,
+	"6101": true, "6102": true, "6103": true, "6104": true, "6105": true, "6106": true, "6107": true, "6108": true,
+	"6116": true, "6117": true, "6118": true, "6119": true, "6120": true, "6122": true, "6123": true,
+	"6401": true, "6402": true, "6403": true, "6404": true,
+	"3101": true, "3102": true,
+}
+
+// Compra informa se o CFOP do item é de compra e deve entrar no custo médio
+func (i NotaFiscalItem) Compra() bool {
+	return CfopsCompra[strings.TrimSpace(i.CFOP)]
+}
+
+// CustoNF é o custo total da linha na nota: produto + despesas acessórias + IPI + ST - desconto
+func (i NotaFiscalItem) CustoNF() float64 {
+	return i.ValorProduto + i.Frete + i.Seguro + i.Outros - i.Desconto + i.Ipi + i.IcmsSt
+}
+
+// PurchaseEntry é a entrada de compra de um produto, já vinculada ao SKU
+type PurchaseEntry struct {
+	ChaveNfe    string    `json:"chave_nfe"`
+	NumeroItem  int       `json:"numero_item"`
+	Produto     string    `json:"produto"`
+	Fornecedor  string    `json:"fornecedor"`
+	DataEmissao time.Time `json:"data_emissao"`
+	Quantidade  float64   `json:"quantidade"`
+	// Valores totais da linha
+	CustoNF          float64 `json:"custo_nf"`
+	CreditoIcms      float64 `json:"credito_icms"`
+	CreditoPisCofins float64 `json:"credito_pis_cofins"`
+	// IPI compõe o custo NF; no comércio não gera crédito
+	Ipi float64 `json:"ipi"`
+}
+
+// CustoLiquido é o custo da linha descontados os créditos de ICMS e PIS/COFINS
+func (e PurchaseEntry) CustoLiquido() float64 {
+	return e.CustoNF - e.CreditoIcms - e.CreditoPisCofins
+}
+
+// CmpIndex é uma linha da tabela productscmp (custo médio ponderado unitário)
+type CmpIndex struct {
+	Produto      string  `json:"produto"`
+	Index        int     `json:"index"`
+	Cmp          float64 `json:"cmp"`
+	CmpNF        float64 `json:"cmp_nf"`
+	CmpIcms      float64 `json:"cmp_icms"`
+	CmpPisCofins float64 `json:"cmp_pis_cofins"`
+	// Quantidade é o estoque após a movimentação que gerou o índice
+	Quantidade float64 `json:"quantidade"`
+	// DataIndice é a data da entrada que gerou o índice (zero nos índices legados)
+	DataIndice time.Time `json:"data_indice"`
+	// EstoqueRegistrado é falso nos índices do ERP sem estoque em cmp_stock
+	EstoqueRegistrado bool `json:"-"`
+}
+
+// AplicarEntrada pondera o custo médio atual com uma entrada de compra. A data do
+// índice não recua: uma NF-e mais antiga importada depois mantém a data atual. Entrada
+// sem quantidade positiva não gera índice e devolve erro.
+func (c CmpIndex) AplicarEntrada(e PurchaseEntry) (CmpIndex, error) {
+	if e.Quantidade <= 0 {
+		return c, fmt.Errorf("entrada do produto %s (item %d) sem quantidade positiva: %v", e.Produto, e.NumeroItem, e.Quantidade)
+	}
+	novo := CmpIndex{Produto: c.Produto, Index: c.Index + 1, DataIndice: c.DataIndice, EstoqueRegistrado: true}
+	if e.DataEmissao.After(novo.DataIndice) {
+		novo.DataIndice = e.DataEmissao
+	}
+
+	estoque := c.Quantidade
+	if estoque < 0 {
+		estoque = 0
+	}
+	novo.Quantidade = estoque + e.Quantidade
+
+	// Sem estoque anterior o custo médio passa a ser o da entrada
+	novo.Cmp = (estoque*c.Cmp + e.CustoLiquido()) / novo.Quantidade
+	novo.CmpNF = (estoque*c.CmpNF + e.CustoNF) / novo.Quantidade
+	novo.CmpIcms = (estoque*c.CmpIcms + e.CreditoIcms) / novo.Quantidade
+	novo.CmpPisCofins = (estoque*c.CmpPisCofins + e.CreditoPisCofins) / novo.Quantidade
+	return novo, nil
+}
+
+// NfeImportResult resume a importação de uma NF-e de compra
+type NfeImportResult struct {
+	Chave          string             `json:"chave"`
+	Numero         string             `json:"numero"`
+	Fornecedor     string             `json:"fornecedor"`
+	Importados     []NfeImportedItem  `json:"importados"`
+	NaoLocalizados []NfeUnmatchedItem `json:"nao_localizados"`
+	// IgnoradosCfop são os itens com CFOP que não é de compra (devolução, bonificação...)
+	IgnoradosCfop []NfeUnmatchedItem `json:"ignorados_cfop"`
+	// IgnoradosQuantidade são os itens de compra sem quantidade positiva, que não formam custo
+	IgnoradosQuantidade []NfeUnmatchedItem `json:"ignorados_quantidade"`
+	Precos              []RepricedSku      `json:"precos"`
+}
+
+// NfeImportedItem mostra o novo custo médio gerado para o SKU
+type NfeImportedItem struct {
+	NumeroItem int           `json:"numero_item"`
+	Sku        string        `json:"sku"`
+	Entrada    PurchaseEntry `json:"entrada"`
+	NovoIndice CmpIndex      `json:"novo_indice"`
+}
+
+// NfeUnmatchedItem é um item da nota que não foi vinculado a nenhum produto
+type NfeUnmatchedItem struct {
+	NumeroItem       int    `json:"numero_item"`
+	CodigoFornecedor string `json:"codigo_fornecedor"`
+	EAN              string `json:"ean"`
+	Descricao        string `json:"descricao"`
+	Cfop             string `json:"cfop,omitempty"`
+}
+
+// RepricedSku é o preço recalculado de um SKU afetado
+type RepricedSku struct {
+	Sku   string  `json:"sku"`
+	Preco float64 `json:"preco"`
+	Erro  string  `json:"erro,omitempty"`
+}
+
+// Tipos de movimentação de estoque
+const (
+	MovimentoVenda           = "venda"
+	MovimentoDevolucaoVenda  = "devolucao_venda"
+	MovimentoDevolucaoCompra = "devolucao_compra"
+	MovimentoAjuste          = "ajuste"
+)
+
+// StockMovement é uma movimentação de estoque que altera a quantidade sem mudar o custo médio
+type StockMovement struct {
+	Produto string    `json:"produto"`
+	Data    time.Time `json:"data"`
+	Tipo    string    `json:"tipo"`
+	// Quantidade com sinal: saídas negativas, entradas positivas
+	Quantidade float64 `json:"quantidade"`
+	Referencia string  `json:"referencia"`
+}
+
+// Validar confere o tipo e o sinal da quantidade
+func (m StockMovement) Validar() error {
+	switch m.Tipo {
+	case MovimentoVenda, MovimentoDevolucaoCompra:
+		if m.Quantidade >= 0 {
+			return fmt.Errorf("movimento %s deve ter quantidade negativa", m.Tipo)
+		}
+	case MovimentoDevolucaoVenda:
+		if m.Quantidade <= 0 {
+			return fmt.Errorf("movimento %s deve ter quantidade positiva", m.Tipo)
+		}
+	case MovimentoAjuste:
+		if m.Quantidade == 0 {
+			return fmt.Errorf("movimento de ajuste com quantidade zero")
+		}
+	default:
+		return fmt.Errorf("tipo de movimento desconhecido: %q", m.Tipo)
+	}
+	if m.Produto == "" || m.Data.IsZero() {
+		return fmt.Errorf("movimento sem produto ou data")
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package entities
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func quase(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestAplicarEntradaPondera(t *testing.T) {
+	atual := CmpIndex{Produto: "100", Index: 3, Cmp: 8, CmpNF: 10, CmpIcms: 1.2, CmpPisCofins: 0.8, Quantidade: 10, EstoqueRegistrado: true}
+	data := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	e := PurchaseEntry{Produto: "100", DataEmissao: data, Quantidade: 30, CustoNF: 360, CreditoIcms: 43.2, CreditoPisCofins: 16.8}
+
+	novo, err := atual.AplicarEntrada(e)
+	if err != nil {
+		t.Fatalf("AplicarEntrada: %v", err)
+	}
+
+	if novo.Index != 4 || !novo.DataIndice.Equal(data) || novo.Quantidade != 40 {
+		t.Fatalf("índice = %+v", novo)
+	}
+	// (10*10 + 360) / 40
+	if !quase(novo.CmpNF, 11.5) {
+		t.Errorf("CmpNF = %v, esperado 11.5", novo.CmpNF)
+	}
+	// (10*8 + 300) / 40
+	if !quase(novo.Cmp, 9.5) {
+		t.Errorf("Cmp = %v, esperado 9.5", novo.Cmp)
+	}
+	if !quase(novo.CmpIcms, (10*1.2+43.2)/40) || !quase(novo.CmpPisCofins, (10*0.8+16.8)/40) {
+		t.Errorf("créditos = %v / %v", novo.CmpIcms, novo.CmpPisCofins)
+	}
+}
+
+func TestAplicarEntradaSemEstoque(t *testing.T) {
+	atual := CmpIndex{Produto: "100", Index: 1, Cmp: 50, CmpNF: 60, Quantidade: 0}
+	e := PurchaseEntry{Produto: "100", Quantidade: 4, CustoNF: 48, CreditoIcms: 4, CreditoPisCofins: 4}
+
+	novo, err := atual.AplicarEntrada(e)
+	if err != nil {
+		t.Fatalf("AplicarEntrada: %v", err)
+	}
+
+	if !quase(novo.CmpNF, 12) || !quase(novo.Cmp, 10) {
+		t.Errorf("sem estoque o custo deveria ser o da entrada: %+v", novo)
+	}
+}
+
+func TestAplicarEntradaEstoqueNegativo(t *testing.T) {
+	// Estoque negativo (vendas antes da entrada) conta como zero
+	atual := CmpIndex{Produto: "100", Index: 1, Cmp: 50, CmpNF: 60, Quantidade: -5}
+	e := PurchaseEntry{Produto: "100", Quantidade: 2, CustoNF: 20}
+
+	novo, err := atual.AplicarEntrada(e)
+	if err != nil {
+		t.Fatalf("AplicarEntrada: %v", err)
+	}
+
+	if novo.Quantidade != 2 || !quase(novo.CmpNF, 10) {
+		t.Errorf("índice = %+v", novo)
+	}
+}
+
+func TestAplicarEntradaSemQuantidade(t *testing.T) {
+	atual := CmpIndex{Produto: "100", Index: 1, Cmp: 50, CmpNF: 60, Quantidade: 0}
+
+	for _, qtd := range []float64{0, -1} {
+		novo, err := atual.AplicarEntrada(PurchaseEntry{Produto: "100", Quantidade: qtd, CustoNF: 20})
+		if err == nil {
+			t.Errorf("quantidade %v: esperado erro, veio o índice %+v", qtd, novo)
+		}
+		if novo != atual {
+			t.Errorf("quantidade %v: entrada recusada não deveria mudar o índice: %+v", qtd, novo)
+		}
+	}
+}
+
+func TestNotaFiscalItemCompra(t *testing.T) {
+	casos := map[string]bool{
+		"5102": true, "6102": true, "5405": true, "6403": true, "3102": true, " 6102 ": true,
+		"5202": false, "6202": false, "5152": false, "6152": false, "5910": false, "6910": false, "": false,
+	}
+	for cfop, esperado := range casos {
+		if got := (NotaFiscalItem{CFOP: cfop}).Compra(); got != esperado {
+			t.Errorf("CFOP %q: Compra() = %v, esperado %v", cfop, got, esperado)
+		}
+	}
+}
+
+func TestAplicarEntradaNaoRecuaData(t *testing.T) {
+	atual := CmpIndex{Produto: "100", Index: 5, Cmp: 10, CmpNF: 12, Quantidade: 10,
+		DataIndice: time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC), EstoqueRegistrado: true}
+	antiga := PurchaseEntry{Produto: "100", DataEmissao: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), Quantidade: 10, CustoNF: 100}
+
+	novo, err := atual.AplicarEntrada(antiga)
+	if err != nil {
+		t.Fatalf("AplicarEntrada: %v", err)
+	}
+
+	if !novo.DataIndice.Equal(atual.DataIndice) {
+		t.Errorf("data do índice = %v, esperado manter %v", novo.DataIndice, atual.DataIndice)
+	}
+}
@@ -0,0 +1,136 @@
+package entities
+
+import (
+	"math"
+	"time"
+)
+
+// UFs válidas para destino de orçamentos
+var UFs = map[string]bool{
+	"AC": true, "AL": true, "AM": true, "AP": true, "BA": true, "CE": true, "DF": true,
+	"ES": true, "GO": true, "MA": true, "MG": true, "MS": true, "MT": true, "PA": true,
+	"PB": true, "PE": true, "PI": true, "PR": true, "RJ": true, "RN": true, "RO": true,
+	"RR": true, "RS": true, "SC": true, "SE": true, "SP": true, "TO": true,
+}
+
+// QuoteRequest é o pedido de orçamento montado pelo vendedor
+type QuoteRequest struct {
+	UF           string             `json:"uf"`
+	Cep          string             `json:"cep"`
+	Canal        string             `json:"canal"`
+	TipoCliente  string             `json:"tipo_cliente"`
+	ValidadeDias int                `json:"validade_dias"`
+	Itens        []QuoteItemRequest `json:"itens"`
+}
+
+// QuoteItemRequest é um SKU com a quantidade desejada
+type QuoteItemRequest struct {
+	Sku        string `json:"sku"`
+	Quantidade int    `json:"quantidade"`
+}
+
+// Quote é o orçamento calculado e salvo
+type Quote struct {
+	ID          string      `json:"id"`
+	CriadoEm    time.Time   `json:"criado_em"`
+	ValidoAte   time.Time   `json:"valido_ate"`
+	UF          string      `json:"uf"`
+	Cep         string      `json:"cep"`
+	Canal       string      `json:"canal"`
+	TipoCliente string      `json:"tipo_cliente"`
+	Itens       []QuoteItem `json:"itens"`
+	// Frete é o frete do pedido inteiro, rateado entre os itens pelo valor
+	Frete  QuoteFreight `json:"frete"`
+	Totais QuoteTaxes   `json:"totais"`
+
+	// Vigente é recalculado a cada leitura a partir de ValidoAte
+	Vigente bool `json:"vigente"`
+}
+
+// Origens do frete do orçamento
+const (
+	// FreteTransportadora é a cotação da carga somada do pedido na tabela das transportadoras
+	FreteTransportadora = "tabela_transportadora"
+	// FreteComissao soma o frete unitário do np_comissao_frete, quando algum item não tem
+	// peso e medidas ou o destino não tem faixa
+	FreteComissao = "np_comissao_frete"
+)
+
+// QuoteFreight é o frete do pedido
+type QuoteFreight struct {
+	Origem         string  `json:"origem"`
+	Transportadora string  `json:"transportadora,omitempty"`
+	Carga          Carga   `json:"carga"`
+	PesoTaxado     float64 `json:"peso_taxado,omitempty"`
+	Valor          float64 `json:"valor"`
+}
+
+// RatearFrete divide o valor proporcionalmente às bases, em centavos; a diferença do
+// arredondamento fica no item de maior base. Sem base positiva divide em partes iguais.
+func RatearFrete(valor float64, bases []float64) []float64 {
+	partes := make([]float64, len(bases))
+	if len(bases) == 0 {
+		return partes
+	}
+	total := 0.0
+	maior := 0
+	for i, b := range bases {
+		total += b
+		if b > bases[maior] {
+			maior = i
+		}
+	}
+	soma := 0.0
+	for i, b := range bases {
+		if total > 0 {
+			partes[i] = math.Round(valor*b/total*100) / 100
+		} else {
+			partes[i] = math.Round(valor/float64(len(bases))*100) / 100
+		}
+		soma += partes[i]
+	}
+	partes[maior] = math.Round((partes[maior]+valor-soma)*100) / 100
+	return partes
+}
+
+// QuoteItem é uma linha do orçamento com a abertura de impostos
+type QuoteItem struct {
+	Sku           string     `json:"sku"`
+	Quantidade    int        `json:"quantidade"`
+	PrecoUnitario float64    `json:"preco_unitario"`
+	Impostos      QuoteTaxes `json:"impostos"`
+}
+
+// QuoteTaxes acumula valores e impostos de um item ou do orçamento inteiro
+type QuoteTaxes struct {
+	ValorTotal float64 `json:"valor_total"`
+	Icms       float64 `json:"icms"`
+	Difal      float64 `json:"difal"`
+	Fcp        float64 `json:"fcp"`
+	Pis        float64 `json:"pis"`
+	Cofins     float64 `json:"cofins"`
+	Frete      float64 `json:"frete"`
+	// IcmsSt e FcpSt são retidos do cliente contribuinte além do valor dos itens
+	IcmsSt float64 `json:"icms_st"`
+	FcpSt  float64 `json:"fcp_st"`
+}
+
+// Add soma os valores de outro item
+func (t QuoteTaxes) Add(o QuoteTaxes) QuoteTaxes {
+	return QuoteTaxes{
+		ValorTotal: t.ValorTotal + o.ValorTotal,
+		Icms:       t.Icms + o.Icms,
+		Difal:      t.Difal + o.Difal,
+		Fcp:        t.Fcp + o.Fcp,
+		Pis:        t.Pis + o.Pis,
+		Cofins:     t.Cofins + o.Cofins,
+		Frete:      t.Frete + o.Frete,
+		IcmsSt:     t.IcmsSt + o.IcmsSt,
+		FcpSt:      t.FcpSt + o.FcpSt,
+	}
+}
+
+// Expirado informa se o orçamento passou da validade
+func (q Quote) Expirado(agora time.Time) bool {
+	return agora.After(q.ValidoAte)
+}
@@ -0,0 +1,56 @@
+package entities
+
+import "testing"
+
+func TestRatearFrete(t *testing.T) {
+	casos := []struct {
+		nome     string
+		valor    float64
+		bases    []float64
+		esperado []float64
+	}{
+		{"proporcional ao valor", 30, []float64{100, 200}, []float64{10, 20}},
+		// 10/3 = 3,33 em cada; o centavo restante vai para a maior base
+		{"arredondamento na maior base", 10, []float64{50, 100, 50}, []float64{2.5, 5, 2.5}},
+		{"resíduo de centavos", 10, []float64{100, 100, 100}, []float64{3.34, 3.33, 3.33}},
+		{"sem base divide igual", 9, []float64{0, 0, 0}, []float64{3, 3, 3}},
+		{"sem frete", 0, []float64{100, 50}, []float64{0, 0}},
+	}
+	for _, c := range casos {
+		partes := RatearFrete(c.valor, c.bases)
+		soma := 0.0
+		for i, p := range partes {
+			soma += p
+			if !quase(p, c.esperado[i]) {
+				t.Errorf("%s: partes = %v, esperado %v", c.nome, partes, c.esperado)
+				break
+			}
+		}
+		if !quase(soma, c.valor) {
+			t.Errorf("%s: soma do rateio = %v, esperado %v", c.nome, soma, c.valor)
+		}
+	}
+}
+
+func TestCotarCargaDoPedido(t *testing.T) {
+	tabelas := FreightTables{Faixas: []FaixaFrete{
+		{Transportadora: "A", UF: "RJ", PesoMinKg: 0, PesoMaxKg: 10, Valor: 30},
+		{Transportadora: "A", UF: "RJ", PesoMinKg: 10, Valor: 30, ValorKgExcedente: 2},
+	}}
+	// 4 × 2 kg + 1 × 5 kg = 13 kg; volume pequeno não altera o peso taxado
+	d1 := ProdutoDimensoes{PesoKg: 2, AlturaCm: 10, LarguraCm: 10, ComprimentoCm: 10}
+	d2 := ProdutoDimensoes{PesoKg: 5, AlturaCm: 20, LarguraCm: 20, ComprimentoCm: 20}
+	carga := d1.Carga(4).Somar(d2.Carga(1))
+
+	cot, ok := tabelas.Cotar(carga, "RJ", "")
+	if !ok {
+		t.Fatal("sem cotação para a carga do pedido")
+	}
+	if !quase(cot.PesoTaxado, 13) || !quase(cot.Valor, 36) {
+		t.Errorf("cotação = %+v, esperado 13 kg e 36", cot)
+	}
+	// peso cubado: 12000 cm³ / 6000 = 2 kg acima do real de 1 kg
+	if p := (Carga{PesoKg: 1, VolumeCm3: 12000}).PesoTaxado(0); !quase(p, 2) {
+		t.Errorf("peso taxado = %v, esperado 2", p)
+	}
+}
@@ -0,0 +1,44 @@
+package entities
+
+import "time"
+
+// RebateAgreement é um acordo de rebate com fornecedor/marca
+type RebateAgreement struct {
+	ID             int       `json:"id"`
+	Fornecedor     string    `json:"fornecedor"`
+	Marca          string    `json:"marca,omitempty"`
+	Descricao      string    `json:"descricao"`
+	Percentual     float64   `json:"percentual"`
+	VolumeMinimo   float64   `json:"volume_minimo"`
+	VigenciaInicio time.Time `json:"vigencia_inicio"`
+	VigenciaFim    time.Time `json:"vigencia_fim"`
+	// VolumeComprado é o valor de nota comprado no período de vigência
+	VolumeComprado float64 `json:"volume_comprado"`
+}
+
+// Atingido informa se o volume mínimo do acordo foi alcançado
+func (a RebateAgreement) Atingido() bool {
+	return a.VolumeComprado >= a.VolumeMinimo
+}
+
+// SelecionarAcordoRebate escolhe o acordo aplicável entre os vigentes: os de marca têm
+// precedência sobre os do fornecedor inteiro e, entre eles, vale o maior percentual
+func SelecionarAcordoRebate(acordos []RebateAgreement) (RebateAgreement, bool) {
+	var escolhido RebateAgreement
+	encontrado := false
+	for _, a := range acordos {
+		if !a.Atingido() {
+			continue
+		}
+		if !encontrado {
+			escolhido, encontrado = a, true
+			continue
+		}
+		maisEspecifico := a.Marca != "" && escolhido.Marca == ""
+		mesmoNivel := (a.Marca != "") == (escolhido.Marca != "")
+		if maisEspecifico || (mesmoNivel && a.Percentual > escolhido.Percentual) {
+			escolhido = a
+		}
+	}
+	return escolhido, encontrado
+}
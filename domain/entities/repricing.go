@@ -0,0 +1,72 @@
+package entities
+
+import "time"
+
+// Notificações do Postgres que disparam o recálculo automático
+const (
+	// CanalRecalculo é o canal do LISTEN/NOTIFY usado pelos gatilhos da migration
+	CanalRecalculo = "repricing"
+	// TodosProdutos é o payload enviado quando o config_params muda
+	TodosProdutos = "*"
+)
+
+// Motivos gravados com o preço recalculado
+const (
+	MotivoIndiceCusto = "productscmp"
+	MotivoParametros  = "config_params"
+	MotivoManual      = "manual"
+)
+
+// ChangeEvent é uma mudança recebida do Postgres
+type ChangeEvent struct {
+	// Produto cujo índice de custo mudou; vazio quando Todos ou Perdidos
+	Produto string
+	// Todos indica mudança nos parâmetros, que afeta todos os produtos
+	Todos bool
+	// Perdidos indica (re)conexão: notificações podem ter sido perdidas e é preciso varrer
+	Perdidos bool
+}
+
+// CostChange é um produto com índice de custo vigente diferente do último recalculado
+type CostChange struct {
+	Produto string
+	Indice  int
+}
+
+// ComputedPrice é o preço calculado vigente de um produto
+type ComputedPrice struct {
+	Produto         string    `json:"produto"`
+	Preco           float64   `json:"preco"`
+	PrecoAVista     float64   `json:"preco_a_vista"`
+	PrecoEquilibrio float64   `json:"preco_equilibrio"`
+	CustoUnitario   float64   `json:"custo_unitario"`
+	Motivo          string    `json:"motivo"`
+	CalculadoEm     time.Time `json:"calculado_em"`
+	// DescontoMarkdown é o desconto da regra de idade do estoque já aplicado em Preco
+	DescontoMarkdown float64 `json:"desconto_markdown,omitempty"`
+}
+
+// RepricingError é um SKU que não pôde ser recalculado no lote
+type RepricingError struct {
+	Sku  string `json:"sku"`
+	Erro string `json:"erro"`
+}
+
+// RepricingBatchResult resume um lote do recálculo
+type RepricingBatchResult struct {
+	Recalculados int `json:"recalculados"`
+	// Propostas são as submetidas ao fluxo de aprovação para os preços que mudaram
+	Propostas int              `json:"propostas"`
+	Erros     []RepricingError `json:"erros,omitempty"`
+}
+
+// RepricingStatus é a situação da fila de recálculo
+type RepricingStatus struct {
+	Ativo           bool      `json:"ativo"`
+	Pendentes       int       `json:"pendentes"`
+	Recalculados    int       `json:"recalculados"`
+	Propostas       int       `json:"propostas"`
+	Erros           int       `json:"erros"`
+	UltimoLote      time.Time `json:"ultimo_lote,omitempty"`
+	UltimaVarredura time.Time `json:"ultima_varredura,omitempty"`
+}
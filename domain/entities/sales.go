@@ -0,0 +1,79 @@
+package entities
+
+import "time"
+
+// SaleLine é uma linha de venda do histórico (banco de vendas no SQL Server)
+type SaleLine struct {
+	Documento     string    `json:"documento"`
+	Data          time.Time `json:"data"`
+	Sku           string    `json:"sku"`
+	Quantidade    float64   `json:"quantidade"`
+	PrecoUnitario float64   `json:"preco_unitario"`
+	Canal         string    `json:"canal"`
+	UF            string    `json:"uf"`
+}
+
+// Receita é o valor da linha de venda
+func (s SaleLine) Receita() float64 {
+	return s.Quantidade * s.PrecoUnitario
+}
+
+// SalesFilter seleciona as vendas do período; datas zero desconsideram o limite
+type SalesFilter struct {
+	De    time.Time `json:"de"`
+	Ate   time.Time `json:"ate"`
+	Skus  []string  `json:"skus,omitempty"`
+	Canal string    `json:"canal,omitempty"`
+}
+
+// MarginAnalysisRow agrega vendas por SKU, departamento ou canal.
+// LucroPrevisto aplica a margem do preço alfa sobre a receita realizada.
+type MarginAnalysisRow struct {
+	Chave           string  `json:"chave"`
+	Vendas          int     `json:"vendas"`
+	Quantidade      float64 `json:"quantidade"`
+	Receita         float64 `json:"receita"`
+	LucroRealizado  float64 `json:"lucro_realizado"`
+	LucroPrevisto   float64 `json:"lucro_previsto"`
+	MargemRealizada float64 `json:"margem_realizada"`
+	MargemPrevista  float64 `json:"margem_prevista"`
+	// Desvio positivo indica que o realizado superou o previsto pela fórmula
+	Desvio float64 `json:"desvio"`
+}
+
+// Adicionar soma uma venda com as margens realizada e prevista
+func (r *MarginAnalysisRow) Adicionar(s SaleLine, margemRealizada, margemPrevista float64) {
+	receita := s.Receita()
+	r.Vendas++
+	r.Quantidade += s.Quantidade
+	r.Receita += receita
+	r.LucroRealizado += receita * margemRealizada
+	r.LucroPrevisto += receita * margemPrevista
+	if r.Receita != 0 {
+		r.MargemRealizada = r.LucroRealizado / r.Receita
+		r.MargemPrevista = r.LucroPrevisto / r.Receita
+	}
+	r.Desvio = r.MargemRealizada - r.MargemPrevista
+}
+
+// SaleError é uma venda que não pôde ser recalculada
+type SaleError struct {
+	Documento string    `json:"documento"`
+	Sku       string    `json:"sku"`
+	Data      time.Time `json:"data"`
+	Erro      string    `json:"erro"`
+}
+
+// MarginAnalysis compara a margem realizada nas vendas com a margem prevista pelo preço alfa.
+// Só o custo é o da data da venda: parâmetros, impostos e frete são os atuais, então as
+// margens são uma estimativa (ParametrosAtuais).
+type MarginAnalysis struct {
+	De               time.Time           `json:"de"`
+	Ate              time.Time           `json:"ate"`
+	ParametrosAtuais bool                `json:"parametros_atuais"`
+	Total            MarginAnalysisRow   `json:"total"`
+	PorSku           []MarginAnalysisRow `json:"por_sku"`
+	PorDepartamento  []MarginAnalysisRow `json:"por_departamento"`
+	PorCanal         []MarginAnalysisRow `json:"por_canal"`
+	Erros            []SaleError         `json:"erros,omitempty"`
+}
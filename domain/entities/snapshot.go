@@ -0,0 +1,41 @@
+package entities
+
+import "time"
+
+// FonteDados* indicam de onde vieram os dados de um cálculo
+const (
+	FonteDadosBancos   = "bancos"
+	FonteDadosSnapshot = "snapshot"
+)
+
+// SnapshotInfo descreve uma carga do snapshot de dados de preço em memória
+type SnapshotInfo struct {
+	CarregadoEm time.Time `json:"carregado_em"`
+	// DuracaoCargaMs é o tempo gasto para ler todos os dados dos bancos
+	DuracaoCargaMs int64 `json:"duracao_carga_ms"`
+	// IdadeSegundos é calculada no momento da consulta ou do cálculo
+	IdadeSegundos float64 `json:"idade_segundos"`
+	Custos        int     `json:"custos"`
+	CustosFire    int     `json:"custos_fire"`
+	PerfisFiscais int     `json:"perfis_fiscais"`
+	Fcis          int     `json:"fcis"`
+	Rebates       int     `json:"rebates"`
+}
+
+// ComIdade devolve a informação com a idade na data informada
+func (s SnapshotInfo) ComIdade(agora time.Time) SnapshotInfo {
+	s.IdadeSegundos = agora.Sub(s.CarregadoEm).Seconds()
+	return s
+}
+
+// SnapshotStatus é a situação do modo snapshot exposta na API e nas métricas
+type SnapshotStatus struct {
+	Ativo bool `json:"ativo"`
+	// Atual é nil enquanto nenhuma carga teve sucesso
+	Atual      *SnapshotInfo `json:"atual,omitempty"`
+	Cargas     int64         `json:"cargas"`
+	Falhas     int64         `json:"falhas"`
+	UltimoErro string        `json:"ultimo_erro,omitempty"`
+	// ConsultasBanco conta as consultas da carga atual que não estavam no snapshot e foram aos bancos
+	ConsultasBanco int64 `json:"consultas_banco"`
+}
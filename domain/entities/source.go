@@ -0,0 +1,16 @@
+package entities
+
+import "time"
+
+// SourceStatus é a situação de conexão de uma fonte de dados
+type SourceStatus struct {
+	Nome        string `json:"nome"`
+	Obrigatoria bool   `json:"obrigatoria"`
+	Disponivel  bool   `json:"disponivel"`
+	// Desde é quando a fonte entrou na situação atual
+	Desde      time.Time `json:"desde"`
+	UltimoErro string    `json:"ultimo_erro,omitempty"`
+	// Falhas é o número de tentativas seguidas sem sucesso
+	Falhas           int        `json:"falhas"`
+	ProximaTentativa *time.Time `json:"proxima_tentativa,omitempty"`
+}
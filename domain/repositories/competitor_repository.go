@@ -0,0 +1,16 @@
+package repositories
+
+import (
+	"time"
+
+	"calculator/domain/entities"
+)
+
+// CompetitorRepository persiste os preços coletados nos concorrentes
+type CompetitorRepository interface {
+	// Grava os preços importados em uma única transação
+	SaveCompetitorPrices(prices []entities.CompetitorPrice) error
+
+	// Busca o preço mais recente de cada concorrente coletado a partir de desde
+	GetLatestCompetitorPrices(produto string, desde time.Time) ([]entities.CompetitorPrice, error)
+}
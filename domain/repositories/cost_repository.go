@@ -0,0 +1,48 @@
+package repositories
+
+import (
+	"time"
+
+	"calculator/domain/entities"
+)
+
+// CostRepository define as operações sobre entradas de compra e custo médio (productscmp)
+type CostRepository interface {
+	// Localiza o produto pelo EAN ou pelo código do fornecedor; ErrNotFound se não houver vínculo
+	FindProductByCodes(ean, cnpjFornecedor, codigoFornecedor string) (string, error)
+
+	// Informa se a NF-e já foi importada
+	PurchaseImported(chave string) (bool, error)
+
+	// Grava as entradas e os novos índices de custo médio em uma única transação. Os produtos
+	// ficam bloqueados até o commit e compute recebe o índice vigente de cada um, lido dentro
+	// da transação (ausente quando o produto não tem custo): importações simultâneas do mesmo
+	// produto calculam uma depois da outra. Devolve os índices gravados.
+	SavePurchaseImport(entries []entities.PurchaseEntry,
+		compute func(atuais map[string]entities.CmpIndex) ([]entities.CmpIndex, error)) ([]entities.CmpIndex, error)
+
+	// Lista os índices do produto com data no intervalo (datas zero desconsideram o limite)
+	GetCmpHistory(produto string, de, ate time.Time) ([]entities.CmpIndex, error)
+
+	// Soma as movimentações de estoque com data em (desde, ate]
+	SumStockMovements(produto string, desde, ate time.Time) (float64, error)
+
+	// Grava movimentações de estoque
+	SaveStockMovements(movs []entities.StockMovement) error
+
+	// Calcula o estoque atual de cada produto e a data da entrada mais antiga ainda em estoque;
+	// produtos nil considera todos
+	GetStockPositions(produtos []string) ([]entities.StockPosition, error)
+
+	// Busca as regras de remarcação por idade do estoque
+	GetMarkdownRules() ([]entities.MarkdownRule, error)
+
+	// Grava (ou substitui) custos de reposição informados manualmente
+	SaveReplacementCosts(costs []entities.ReplacementCost) error
+}
+
+// ErpStockRepository lê o estoque atual do produto no ERP, usado como estoque inicial
+// da primeira entrada importada
+type ErpStockRepository interface {
+	GetErpStock(produto string) (float64, error)
+}
@@ -0,0 +1,14 @@
+package repositories
+
+import (
+	"calculator/domain/entities"
+)
+
+// ElasticityRepository guarda a última estimativa de elasticidade por escopo
+type ElasticityRepository interface {
+	// Grava ou substitui as estimativas
+	SaveElasticityEstimates(estimates []entities.ElasticityEstimate) error
+
+	// Busca a estimativa do escopo (sku ou departamento); ErrNotFound se não houver
+	GetElasticityEstimate(escopo, chave string) (entities.ElasticityEstimate, error)
+}
@@ -0,0 +1,37 @@
+package repositories
+
+import (
+	"time"
+
+	"calculator/domain/entities"
+)
+
+// ErpPriceRepository lê as tabelas de preço do ERP (Millennium)
+type ErpPriceRepository interface {
+	// Lista os preços vigentes da tabela, filtrando por departamento e SKUs quando informados
+	GetErpPrices(filter entities.PriceDiffFilter) ([]entities.ErpPrice, error)
+
+	// Grava os preços novos em uma única transação. Cada item só é alterado se o preço
+	// vigente ainda for o PrecoAnterior; caso contrário desfaz tudo e retorna ErrConflict
+	ApplyErpPrices(changes []entities.PriceWriteChange) error
+}
+
+// PriceWriteRepository registra as gravações de preço no ERP (log antes/depois)
+type PriceWriteRepository interface {
+	// Salva a gravação com as alterações
+	SaveWriteRun(run entities.PriceWriteRun) error
+
+	// Atualiza a situação da gravação; revertidoPor só é usado na reversão.
+	// Gravações aplicadas ou revertidas entram na fila de publicação na mesma transação.
+	UpdateWriteRunStatus(id, status, erro, revertidoPor string) error
+
+	// Busca a gravação com as alterações; ErrNotFound se não existir
+	GetWriteRun(id string) (entities.PriceWriteRun, error)
+
+	// Volta a gravação para a fila de publicação
+	EnqueuePublication(id string) error
+	// Reserva até limite publicações pendentes vencidas; durante a reserva outras instâncias não as pegam
+	ClaimDuePublications(limite int, reserva time.Duration) ([]entities.RunPublication, error)
+	MarkPublished(id string, em time.Time) error
+	MarkPublishFailed(id string, tentativas int, erro string, proxima time.Time) error
+}
@@ -0,0 +1,19 @@
+package repositories
+
+import "errors"
+
+// ErrNotFound indica que o registro procurado não existe
+var ErrNotFound = errors.New("registro não encontrado")
+
+// ErrAlreadyExists indica que o registro já foi gravado anteriormente
+var ErrAlreadyExists = errors.New("registro já existente")
+
+// ErrConflict indica que o registro foi alterado por outro processo desde a leitura
+var ErrConflict = errors.New("registro alterado por outro processo")
+
+// ErrIndeterminate indica que o commit falhou sem resposta do banco: a gravação pode ter
+// sido aplicada ou não e deve ser conferida
+var ErrIndeterminate = errors.New("resultado do commit indeterminado")
+
+// ErrUnavailable indica que a fonte de dados não respondeu (banco fora do ar ou sem rede)
+var ErrUnavailable = errors.New("fonte de dados indisponível")
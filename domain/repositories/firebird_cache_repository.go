@@ -0,0 +1,19 @@
+package repositories
+
+import (
+	"time"
+
+	"calculator/domain/entities"
+)
+
+// FirebirdCacheRepository guarda no Postgres a última leitura boa do Firebird por produto
+type FirebirdCacheRepository interface {
+	// RefreshFirebirdCache lê o Firebird inteiro e regrava o cache
+	RefreshFirebirdCache() (entities.FirebirdCacheInfo, error)
+
+	// GetCachedCostFire devolve o CostFire e a data da leitura; ErrNotFound se não houver
+	GetCachedCostFire(sku string) (entities.CostFire, time.Time, error)
+
+	// GetCachedPerfilFiscal devolve o perfil fiscal e a data da leitura; ErrNotFound se não houver
+	GetCachedPerfilFiscal(produto int) (entities.PerfilFiscal, time.Time, error)
+}
@@ -0,0 +1,10 @@
+package repositories
+
+import (
+	"calculator/domain/entities"
+)
+
+// FreightRepository carrega as tabelas de frete das transportadoras e as regras de subsídio
+type FreightRepository interface {
+	LoadFreightTables() (entities.FreightTables, error)
+}
@@ -0,0 +1,11 @@
+package repositories
+
+import (
+	"calculator/domain/entities"
+)
+
+// IdentityRepository busca os usuários da API
+type IdentityRepository interface {
+	// Busca o usuário ativo pelo hash do token; ErrNotFound se não houver
+	GetIdentityByTokenHash(hash string) (entities.Identidade, error)
+}
@@ -0,0 +1,48 @@
+package repositories
+
+import (
+	"time"
+
+	"calculator/domain/entities"
+)
+
+// JobRepository persiste os jobs de cálculo em lote e seus itens
+type JobRepository interface {
+	// Grava o job e um item pendente por SKU, na ordem informada
+	CreateJob(job entities.PriceJob, skus []string) error
+
+	// Busca o job com os primeiros erros; ErrNotFound se não existir
+	GetJob(id string, limiteErros int) (entities.PriceJob, error)
+
+	// Reserva o job pendente mais antigo, ou um em execução com a reserva vencida, e o marca
+	// em execução; ok falso quando não há job a executar
+	ClaimJob(reserva time.Duration) (job entities.PriceJob, ok bool, err error)
+
+	// Próximos itens pendentes em ordem de posição
+	GetPendingItems(jobID string, limite int) ([]entities.PriceJobItem, error)
+
+	// Grava os resultados, soma os contadores e renova a reserva; devolve a situação atual
+	// do job, para quem executa perceber o cancelamento
+	SaveItemResults(jobID string, itens []entities.PriceJobItem, reserva time.Duration) (string, error)
+
+	// Encerra o job em execução com a situação final
+	FinishJob(jobID, status string) error
+
+	// Libera a reserva para outra execução retomar logo (parada do serviço)
+	ReleaseJob(jobID string) error
+
+	// Cancela o job pendente ou em execução; ErrConflict se já estiver encerrado
+	CancelJob(id string) error
+
+	// Renova a reserva do relatório em execução; devolve a situação atual do job
+	RenewJob(jobID string, reserva time.Duration) (string, error)
+
+	// Encerra o relatório em execução com o resultado ou, com erro informado, como falhou
+	SaveReport(jobID string, resultado []byte, erro string) error
+
+	// Resultado gravado do relatório; nil enquanto não concluído
+	GetReport(jobID string) ([]byte, error)
+
+	// Percorre os itens em ordem de posição
+	EachJobItem(jobID string, fn func(entities.PriceJobItem) error) error
+}
@@ -0,0 +1,30 @@
+package repositories
+
+import (
+	"time"
+
+	"calculator/domain/entities"
+)
+
+// OutboxRepository guarda os destinos de webhook e controla a entrega dos eventos do outbox.
+// Os eventos são gravados pelo RepricingRepository na transação dos preços.
+type OutboxRepository interface {
+	SaveSubscription(s entities.WebhookSubscription) (entities.WebhookSubscription, error)
+	ListSubscriptions() ([]entities.WebhookSubscription, error)
+
+	// Reserva até limite entregas pendentes vencidas; durante a reserva outras instâncias não as pegam
+	ClaimDueDeliveries(limite int, reserva time.Duration) ([]entities.WebhookDelivery, error)
+	MarkDelivered(eventoID, assinaturaID int64, em time.Time) error
+	// Registra a falha; morta move a entrega para a lista de mensagens mortas
+	MarkFailed(eventoID, assinaturaID int64, tentativas int, erro string, proxima time.Time, morta bool) error
+	ListDeadLetters(limite int) ([]entities.WebhookDelivery, error)
+
+	// Volta as entregas escolhidas para pendente, criando-as para destinos que não as tinham
+	ReplayEvents(req entities.EventReplayRequest) (int, error)
+}
+
+// WebhookSender entrega um evento a um destino
+type WebhookSender interface {
+	// Envia o corpo assinado; erro em falha de rede ou resposta fora de 2xx
+	Send(d entities.WebhookDelivery, corpo []byte) error
+}
@@ -0,0 +1,13 @@
+package repositories
+
+import (
+	"context"
+
+	"calculator/domain/entities"
+)
+
+// PricePublisher publica as gravações de preço em um destino externo (BI)
+type PricePublisher interface {
+	// Grava ou atualiza a gravação e seus itens no destino; o contexto limita o tempo da publicação
+	PublishPriceRun(ctx context.Context, run entities.PriceWriteRun) error
+}
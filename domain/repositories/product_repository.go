@@ -1,6 +1,8 @@
 package repositories
 
 import (
+	"time"
+
 	"calculator/domain/entities"
 )
 
@@ -10,11 +12,52 @@ type ProductRepository interface {
 	// Busca dados do 'productscmp' (Postgres)
 	GetProductCmpValues(sku string) (entities.PriceInput, error)
 
+	// Busca o índice do 'productscmp' vigente na data informada
+	GetProductCmpValuesAt(sku string, data time.Time) (entities.PriceInput, error)
+
 	// Busca parâmetros padrão (Parameters) do Postgres
 	GetParameters() (entities.Parameters, error)
 
 	// Busca custo Firebird (departamento, comissao, frete) no Firebird
 	GetCostFire(sku string) (entities.CostFire, error)
+
+	// Busca a FCI (conteúdo de importação) do produto no Postgres; nil se não houver
+	GetFci(sku string) (*entities.Fci, error)
+
+	// Busca a última entrada de compra do produto; ErrNotFound se não houver
+	GetLastPurchase(sku string) (entities.PurchaseEntry, error)
+
+	// Busca o custo da tabela de fornecedor vigente; ErrNotFound se não houver
+	GetSupplierListCost(sku string) (float64, error)
+
+	// Busca o custo de reposição informado; ErrNotFound se não houver
+	GetReplacementCost(sku string) (float64, error)
+
+	// Busca a base de custo configurada para o departamento; "" se não houver
+	GetDepartmentCostBasis(departamento int) (string, error)
+
+	// Busca peso e medidas de envio do produto; ErrNotFound se não houver
+	GetProductDimensions(sku string) (entities.ProdutoDimensoes, error)
+
+	// Busca os acordos de rebate vigentes na data para o fornecedor/marca do produto,
+	// com o volume comprado no período de cada acordo
+	GetRebateAgreements(sku string, data time.Time) ([]entities.RebateAgreement, error)
+
+	// Busca os perfis de pagamento do canal, ou os do canal padrão se não houver
+	GetPaymentProfiles(canal string) ([]entities.PaymentProfile, error)
+
+	// Busca os componentes de custo mais específicos para departamento e canal;
+	// false quando não há configuração
+	GetCostComponents(departamento int, canal string) (entities.CostComponents, bool, error)
+
+	// Busca as faixas de quantidade do tipo de cliente, em ordem crescente
+	GetFaixasVolume(tipoCliente string) ([]entities.FaixaVolume, error)
+
+	// Busca as alíquotas de ICMS e FCP da UF de destino; ErrNotFound se não houver
+	GetAliquotaUF(uf string) (entities.AliquotaUF, error)
+
+	// Busca a MVA da substituição tributária do produto para a UF; ErrNotFound se não houver
+	GetRegraST(sku string, uf string) (entities.RegraST, error)
 	
 	// Se precisar, define também GetIcmsEfetivo() ou etc.
 }
@@ -0,0 +1,27 @@
+package repositories
+
+import (
+	"calculator/domain/entities"
+)
+
+// ProposalRepository persiste as propostas de preço e suas transições
+type ProposalRepository interface {
+	// Salva as propostas novas com os eventos informados em cada uma
+	SaveProposals(ps []entities.PriceProposal) error
+
+	// Busca a proposta com os eventos; ErrNotFound se não existir
+	GetProposal(id string) (entities.PriceProposal, error)
+
+	// Lista as propostas (sem eventos); status vazio lista todas
+	ListProposals(status string) ([]entities.PriceProposal, error)
+
+	// Lista as propostas ainda não publicadas nem rejeitadas dos SKUs (sem eventos)
+	ListOpenProposals(skus []string) ([]entities.PriceProposal, error)
+
+	// Muda a situação se ela ainda for ev.De e grava o evento; ErrConflict caso contrário.
+	// gravacaoID vazio mantém a gravação já registrada
+	TransitionProposal(id string, ev entities.ProposalEvent, gravacaoID string) error
+
+	// Busca as regras de aprovação por variação
+	GetApprovalRules() ([]entities.ApprovalRule, error)
+}
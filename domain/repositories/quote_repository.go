@@ -0,0 +1,14 @@
+package repositories
+
+import (
+	"calculator/domain/entities"
+)
+
+// QuoteRepository persiste os orçamentos gerados
+type QuoteRepository interface {
+	// Salva o orçamento completo
+	SaveQuote(q entities.Quote) error
+
+	// Busca um orçamento pelo ID; retorna ErrNotFound se não existir
+	GetQuote(id string) (entities.Quote, error)
+}
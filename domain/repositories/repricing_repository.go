@@ -0,0 +1,30 @@
+package repositories
+
+import (
+	"calculator/domain/entities"
+)
+
+// RepricingRepository guarda os preços recalculados e localiza as mudanças perdidas pelo
+// ouvinte comparando o productscmp e o config_params com o que já foi recalculado
+type RepricingRepository interface {
+	// Grava (upsert) os preços calculados em uma transação
+	SaveComputedPrices(prices []entities.ComputedPrice) error
+	// Produtos cujo índice de custo vigente difere do último recalculado
+	GetCostChanges() ([]entities.CostChange, error)
+	// Índice de custo vigente dos produtos informados
+	GetCostIndices(produtos []string) ([]entities.CostChange, error)
+	// Registra os índices recalculados
+	MarkCostsSeen(changes []entities.CostChange) error
+	// Hash dos parâmetros atuais do config_params e o do último recálculo completo
+	GetParametersHash() (atual, registrado string, err error)
+	SaveParametersHash(hash string) error
+	// Todos os produtos com índice de custo
+	GetAllProducts() ([]string, error)
+}
+
+// ChangeListener recebe as notificações de mudança do Postgres
+type ChangeListener interface {
+	// Listen entrega cada notificação a onChange até o canal stop ser fechado.
+	// Na conexão e em cada reconexão entrega um evento com Perdidos.
+	Listen(stop <-chan struct{}, onChange func(entities.ChangeEvent))
+}
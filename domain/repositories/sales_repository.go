@@ -0,0 +1,11 @@
+package repositories
+
+import (
+	"calculator/domain/entities"
+)
+
+// SalesRepository lê o histórico de vendas
+type SalesRepository interface {
+	// Lista as linhas de venda do filtro em ordem de data
+	GetSales(filter entities.SalesFilter) ([]entities.SaleLine, error)
+}
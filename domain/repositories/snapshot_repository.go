@@ -0,0 +1,22 @@
+package repositories
+
+import "calculator/domain/entities"
+
+// PricingSnapshot é uma cópia em memória dos dados usados pelo cálculo de preço.
+// As consultas por data e os SKUs que não estavam na carga vão aos bancos.
+type PricingSnapshot interface {
+	ProductRepository
+
+	// GetPerfilFiscal devolve a redução de ICMS e a origem do produto
+	GetPerfilFiscal(produto int) (entities.PerfilFiscal, error)
+
+	Info() entities.SnapshotInfo
+
+	// ConsultasBanco é o total de consultas que não estavam na carga
+	ConsultasBanco() int64
+}
+
+// SnapshotRepository carrega de uma vez todos os dados do cálculo de preço
+type SnapshotRepository interface {
+	LoadSnapshot() (PricingSnapshot, error)
+}
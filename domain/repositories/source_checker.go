@@ -0,0 +1,17 @@
+package repositories
+
+import "calculator/domain/entities"
+
+// Nomes das fontes de dados do serviço
+const (
+	FontePostgres  = "postgres"
+	FonteFirebird  = "firebird"
+	FonteSQLServer = "sqlserver"
+)
+
+// SourceChecker informa se as fontes de dados estão conectadas
+type SourceChecker interface {
+	// CheckSources devolve ErrUnavailable com o motivo se alguma das fontes estiver fora
+	CheckSources(nomes ...string) error
+	SourcesStatus() []entities.SourceStatus
+}
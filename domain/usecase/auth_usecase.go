@@ -0,0 +1,39 @@
+package usecase
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+
+	"calculator/domain/entities"
+	"calculator/domain/repositories"
+)
+
+// AuthUseCase identifica o usuário da requisição pelo token
+type AuthUseCase interface {
+	// Authenticate devolve ErrUnauthorized para token vazio, desconhecido ou inativo
+	Authenticate(token string) (entities.Identidade, error)
+}
+
+// authUseCaseImpl implementa AuthUseCase
+type authUseCaseImpl struct {
+	identityRepo repositories.IdentityRepository
+}
+
+// NewAuthUseCase cria a autenticação por token
+func NewAuthUseCase(ir repositories.IdentityRepository) AuthUseCase {
+	return &authUseCaseImpl{identityRepo: ir}
+}
+
+// Authenticate procura o usuário pelo hash SHA-256 do token; o token não é gravado
+func (uc *authUseCaseImpl) Authenticate(token string) (entities.Identidade, error) {
+	if token == "" {
+		return entities.Identidade{}, ErrUnauthorized
+	}
+	soma := sha256.Sum256([]byte(token))
+	id, err := uc.identityRepo.GetIdentityByTokenHash(hex.EncodeToString(soma[:]))
+	if errors.Is(err, repositories.ErrNotFound) {
+		return id, ErrUnauthorized
+	}
+	return id, err
+}
@@ -0,0 +1,176 @@
+package usecase
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"calculator/domain/entities"
+	"calculator/domain/repositories"
+)
+
+// BacktestUseCase reaplica um conjunto de parâmetros às vendas históricas
+type BacktestUseCase interface {
+	RunBacktest(req entities.BacktestRequest) (entities.BacktestResult, error)
+}
+
+// backtestUseCaseImpl implementa BacktestUseCase
+type backtestUseCaseImpl struct {
+	salesRepo repositories.SalesRepository
+	priceUC   PriceUseCase
+}
+
+// NewBacktestUseCase cria o backtesting de parâmetros
+func NewBacktestUseCase(sr repositories.SalesRepository, pu PriceUseCase) BacktestUseCase {
+	return &backtestUseCaseImpl{salesRepo: sr, priceUC: pu}
+}
+
+// RunBacktest calcula, para cada venda, o preço alfa com os parâmetros atuais e com os
+// propostos, ambos com o custo da data da venda. Os propostos são os atuais com os
+// campos informados substituídos. O preço praticado é ajustado na mesma
+// proporção do preço alfa (preserva promoções e descontos) e a quantidade segue a
+// elasticidade: q' = q × (p'/p)^e.
+func (uc *backtestUseCaseImpl) RunBacktest(req entities.BacktestRequest) (entities.BacktestResult, error) {
+	if req.Parametros == nil || req.Parametros.Vazio() {
+		return entities.BacktestResult{}, fmt.Errorf("%w: informe ao menos um campo em parametros", ErrInvalidRequest)
+	}
+	if req.De.IsZero() || req.Ate.IsZero() || req.Ate.Before(req.De) {
+		return entities.BacktestResult{}, fmt.Errorf("%w: informe um período válido em de e ate", ErrInvalidRequest)
+	}
+	elasticidade := entities.ElasticidadePadrao
+	if req.Elasticidade != nil {
+		elasticidade = *req.Elasticidade
+	}
+	if elasticidade > 0 {
+		return entities.BacktestResult{}, fmt.Errorf("%w: elasticidade deve ser zero ou negativa", ErrInvalidRequest)
+	}
+
+	atuais, err := uc.priceUC.GetParameters()
+	if err != nil {
+		return entities.BacktestResult{}, err
+	}
+	params := req.Parametros.Aplicar(atuais)
+	if err := validateParameters(params); err != nil {
+		return entities.BacktestResult{}, err
+	}
+
+	vendas, err := uc.salesRepo.GetSales(entities.SalesFilter{De: req.De, Ate: req.Ate, Skus: req.Skus, Canal: req.Canal})
+	if err != nil {
+		return entities.BacktestResult{}, err
+	}
+
+	res := entities.BacktestResult{
+		De:           req.De,
+		Ate:          req.Ate,
+		Elasticidade: elasticidade,
+		Parametros:   params,
+		Total:        entities.BacktestRow{Chave: "total"},
+	}
+	atual := newSaleCalculator(uc.priceUC, nil)
+	proposto := newSaleCalculator(uc.priceUC, &params)
+	porSku := map[string]*entities.BacktestRow{}
+	porDepartamento := map[string]*entities.BacktestRow{}
+
+	for _, venda := range vendas {
+		l, departamento, err := replaySale(venda, atual, proposto, elasticidade)
+		if err != nil {
+			res.Erros = append(res.Erros, entities.SaleError{
+				Documento: venda.Documento, Sku: venda.Sku, Data: venda.Data, Erro: err.Error(),
+			})
+			continue
+		}
+		res.Total.Adicionar(l)
+		backtestRow(porSku, venda.Sku).Adicionar(l)
+		backtestRow(porDepartamento, strconv.Itoa(departamento)).Adicionar(l)
+	}
+
+	res.PorSku = sortedBacktestRows(porSku)
+	res.PorDepartamento = sortedBacktestRows(porDepartamento)
+	return res, nil
+}
+
+// validateParameters exige todos os parâmetros entre 0 e 1; o lucro padrão abaixo de 1
+func validateParameters(p entities.Parameters) error {
+	campos := []struct {
+		nome  string
+		valor float64
+	}{
+		{"lucro_adicional_desejado", p.LucroAdicionalDesejado},
+		{"lucro_padrao_desejado", p.LucroPadraoDesejado},
+		{"imposto_federal", p.ImpostoFederal},
+		{"operacao", p.Operacao},
+		{"custo_fixo", p.CustoFixo},
+		{"aliquota_pis", p.AliquotaPis},
+		{"aliquota_cofins", p.AliquotaCofins},
+		{"rebate", p.Rebate},
+		{"fcp", p.Fcp},
+		{"redutor_padrao", p.RedutorPadrao},
+	}
+	for _, c := range campos {
+		if c.valor < 0 || c.valor > 1 || math.IsNaN(c.valor) {
+			return fmt.Errorf("%w: %s deve estar entre 0 e 1", ErrInvalidRequest, c.nome)
+		}
+	}
+	if p.LucroPadraoDesejado >= 1 {
+		return fmt.Errorf("%w: lucro_padrao_desejado deve ser menor que 1", ErrInvalidRequest)
+	}
+	return nil
+}
+
+// replaySale calcula a venda com os dois conjuntos de parâmetros
+func replaySale(venda entities.SaleLine, atual, proposto *saleCalculator, elasticidade float64) (entities.BacktestLine, int, error) {
+	base, err := atual.price(venda)
+	if err != nil {
+		return entities.BacktestLine{}, 0, err
+	}
+	novo, err := proposto.price(venda)
+	if err != nil {
+		return entities.BacktestLine{}, 0, err
+	}
+	if base.ValorFinal <= 0 {
+		return entities.BacktestLine{}, 0, fmt.Errorf("preço alfa atual inválido: %.2f", base.ValorFinal)
+	}
+	return backtestLine(venda, base, novo, elasticidade), base.Cost.Departamento, nil
+}
+
+// backtestLine reprecifica uma venda com a razão entre o preço alfa proposto e o atual
+func backtestLine(venda entities.SaleLine, base, novo entities.PriceResult, elasticidade float64) entities.BacktestLine {
+	razao := novo.ValorFinal / base.ValorFinal
+	l := entities.BacktestLine{
+		Venda:              venda,
+		PrecoAlfaAtual:     base.ValorFinal,
+		PrecoAlfaProposto:  novo.ValorFinal,
+		PrecoEstimado:      venda.PrecoUnitario * razao,
+		QuantidadeEstimada: venda.Quantidade * math.Pow(razao, elasticidade),
+	}
+	l.Lucro = venda.Receita() * base.MargemAoPreco(venda.PrecoUnitario)
+	l.LucroEstimado = l.PrecoEstimado * l.QuantidadeEstimada * novo.MargemAoPreco(l.PrecoEstimado)
+	return l
+}
+
+// backtestRow devolve a linha do agrupamento, criando-a na primeira venda
+func backtestRow(grupo map[string]*entities.BacktestRow, chave string) *entities.BacktestRow {
+	row, ok := grupo[chave]
+	if !ok {
+		row = &entities.BacktestRow{Chave: chave}
+		grupo[chave] = row
+	}
+	return row
+}
+
+// sortedBacktestRows ordena pela maior variação absoluta de lucro
+func sortedBacktestRows(grupo map[string]*entities.BacktestRow) []entities.BacktestRow {
+	rows := make([]entities.BacktestRow, 0, len(grupo))
+	for _, r := range grupo {
+		rows = append(rows, *r)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		di, dj := math.Abs(rows[i].VariacaoLucro), math.Abs(rows[j].VariacaoLucro)
+		if di != dj {
+			return di > dj
+		}
+		return rows[i].Chave < rows[j].Chave
+	})
+	return rows
+}
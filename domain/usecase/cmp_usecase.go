@@ -0,0 +1,136 @@
+package usecase
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"calculator/domain/entities"
+	"calculator/domain/repositories"
+)
+
+// CmpUseCase define o cálculo do custo médio ponderado a partir de entradas e movimentações
+type CmpUseCase interface {
+	// Calcula os novos índices de custo médio das entradas sobre os índices vigentes
+	// (atuais, por produto; sem gravar)
+	ComputeIndices(entries []entities.PurchaseEntry, atuais map[string]entities.CmpIndex) ([]entities.CmpIndex, error)
+	RegisterMovements(movs []entities.StockMovement) error
+	GetCostHistory(sku string, de, ate time.Time) ([]entities.CmpIndex, error)
+	RegisterReplacementCosts(costs []entities.ReplacementCost) error
+}
+
+// cmpUseCaseImpl implementa CmpUseCase
+type cmpUseCaseImpl struct {
+	costRepo repositories.CostRepository
+	// erpStock é nil quando a consulta de estoque do ERP não está configurada
+	erpStock repositories.ErpStockRepository
+}
+
+// NewCmpUseCase cria o motor de custo médio; es fornece o estoque inicial dos
+// produtos cujo índice vigente veio do ERP
+func NewCmpUseCase(cr repositories.CostRepository, es repositories.ErpStockRepository) CmpUseCase {
+	return &cmpUseCaseImpl{costRepo: cr, erpStock: es}
+}
+
+// ComputeIndices pondera as entradas sobre o índice vigente de cada produto, lido pelo
+// chamador na transação que grava o resultado.
+// O estoque antes de cada entrada é a quantidade do índice mais as movimentações
+// registradas desde então; cada produto gera um único índice novo. Índice do ERP
+// sem estoque registrado usa o estoque atual do ERP, que já inclui as movimentações.
+func (uc *cmpUseCaseImpl) ComputeIndices(entries []entities.PurchaseEntry, atuais map[string]entities.CmpIndex) ([]entities.CmpIndex, error) {
+	porProduto := map[string][]entities.PurchaseEntry{}
+	var ordem []string
+	for _, e := range entries {
+		if _, ok := porProduto[e.Produto]; !ok {
+			ordem = append(ordem, e.Produto)
+		}
+		porProduto[e.Produto] = append(porProduto[e.Produto], e)
+	}
+
+	novos := make([]entities.CmpIndex, 0, len(ordem))
+	for _, produto := range ordem {
+		atual, existe := atuais[produto]
+		if !existe {
+			atual = entities.CmpIndex{Produto: produto}
+		}
+
+		itens := porProduto[produto]
+		sort.SliceStable(itens, func(i, j int) bool {
+			return itens[i].DataEmissao.Before(itens[j].DataEmissao)
+		})
+
+		// Índice legado (sem estoque registrado) não tem data: sem o estoque do ERP, a
+		// soma das movimentações desde a data zero pegaria o histórico inteiro
+		cur := atual
+		desde := atual.DataIndice
+		if existe && !atual.EstoqueRegistrado {
+			if uc.erpStock == nil {
+				return nil, fmt.Errorf("%w: produto %s sem estoque registrado para o custo médio e ERP_STOCK_QUERY não configurada",
+					ErrInvalidRequest, produto)
+			}
+			var err error
+			if cur.Quantidade, err = uc.erpStock.GetErpStock(produto); err != nil {
+				return nil, fmt.Errorf("erro ao buscar estoque inicial do produto %s: %w", produto, err)
+			}
+			desde = itens[0].DataEmissao
+		}
+		for _, e := range itens {
+			saldo, err := uc.costRepo.SumStockMovements(produto, desde, e.DataEmissao)
+			if err != nil {
+				return nil, err
+			}
+			cur.Quantidade += saldo
+			if cur, err = cur.AplicarEntrada(e); err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+			}
+			if e.DataEmissao.After(desde) {
+				desde = e.DataEmissao
+			}
+		}
+		// A data do índice é a maior entre a do índice vigente e a das entradas
+		cur.Index = atual.Index + 1
+		if desde.After(cur.DataIndice) {
+			cur.DataIndice = desde
+		}
+		novos = append(novos, cur)
+	}
+	return novos, nil
+}
+
+// RegisterMovements valida e grava movimentações de estoque
+func (uc *cmpUseCaseImpl) RegisterMovements(movs []entities.StockMovement) error {
+	if len(movs) == 0 {
+		return fmt.Errorf("%w: nenhuma movimentação informada", ErrInvalidRequest)
+	}
+	for i, m := range movs {
+		if err := m.Validar(); err != nil {
+			return fmt.Errorf("%w: movimentação %d: %v", ErrInvalidRequest, i, err)
+		}
+	}
+	return uc.costRepo.SaveStockMovements(movs)
+}
+
+// GetCostHistory devolve a evolução do custo médio do SKU
+func (uc *cmpUseCaseImpl) GetCostHistory(sku string, de, ate time.Time) ([]entities.CmpIndex, error) {
+	hist, err := uc.costRepo.GetCmpHistory(sku, de, ate)
+	if err != nil {
+		return nil, err
+	}
+	if len(hist) == 0 {
+		return nil, fmt.Errorf("custo do sku %s: %w", sku, repositories.ErrNotFound)
+	}
+	return hist, nil
+}
+
+// RegisterReplacementCosts grava custos de reposição informados manualmente
+func (uc *cmpUseCaseImpl) RegisterReplacementCosts(costs []entities.ReplacementCost) error {
+	if len(costs) == 0 {
+		return fmt.Errorf("%w: nenhum custo informado", ErrInvalidRequest)
+	}
+	for _, c := range costs {
+		if c.Produto == "" || c.Custo <= 0 {
+			return fmt.Errorf("%w: custo de reposição inválido para o produto %q", ErrInvalidRequest, c.Produto)
+		}
+	}
+	return uc.costRepo.SaveReplacementCosts(costs)
+}
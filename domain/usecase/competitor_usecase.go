@@ -0,0 +1,167 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"calculator/domain/entities"
+	"calculator/domain/repositories"
+	"calculator/internal/csvutil"
+)
+
+// diasConcorrentePadrao é a idade máxima dos preços de concorrentes quando não informada
+const diasConcorrentePadrao = 7
+
+// CompetitorUseCase importa preços de concorrentes e posiciona o preço alfa
+type CompetitorUseCase interface {
+	// ImportCompetitorPrices lê o CSV da ferramenta de monitoramento (sku ou ean;concorrente;preco;data)
+	ImportCompetitorPrices(in io.Reader) (entities.CompetitorImportResult, error)
+	Positioning(skus []string, opts entities.PositioningOptions) ([]entities.CompetitorPositioning, error)
+}
+
+// competitorUseCaseImpl implementa CompetitorUseCase
+type competitorUseCaseImpl struct {
+	competitorRepo repositories.CompetitorRepository
+	costRepo       repositories.CostRepository
+	priceUC        PriceUseCase
+	now            func() time.Time
+}
+
+// NewCompetitorUseCase cria o caso de uso de preços de concorrentes
+func NewCompetitorUseCase(cpr repositories.CompetitorRepository, cr repositories.CostRepository, pu PriceUseCase) CompetitorUseCase {
+	return &competitorUseCaseImpl{competitorRepo: cpr, costRepo: cr, priceUC: pu, now: time.Now}
+}
+
+// ImportCompetitorPrices vincula cada linha ao produto pelo sku ou, sem ele, pelo EAN.
+// Linhas sem vínculo são devolvidas no resultado e não impedem a importação.
+func (uc *competitorUseCaseImpl) ImportCompetitorPrices(in io.Reader) (entities.CompetitorImportResult, error) {
+	var res entities.CompetitorImportResult
+	var precos []entities.CompetitorPrice
+	var repoErr error
+	agora := uc.now()
+
+	err := csvutil.Parse(in, "concorrentes", func(row *csvutil.Row) error {
+		p := entities.CompetitorPrice{
+			Produto:     row.Str("sku"),
+			Ean:         row.Str("ean"),
+			Concorrente: row.Str("concorrente"),
+			Preco:       row.Num("preco"),
+			ColetadoEm:  row.Date("data"),
+		}
+		if err := row.Err(); err != nil {
+			return err
+		}
+		if p.Concorrente == "" || p.Preco <= 0 {
+			return fmt.Errorf("linha %d: concorrente e preco são obrigatórios", row.Line)
+		}
+		if p.ColetadoEm.IsZero() {
+			p.ColetadoEm = agora
+		}
+
+		if p.Produto == "" {
+			if p.Ean == "" {
+				return fmt.Errorf("linha %d: informe sku ou ean", row.Line)
+			}
+			produto, err := uc.costRepo.FindProductByCodes(p.Ean, "", "")
+			if errors.Is(err, repositories.ErrNotFound) {
+				res.NaoVinculados = append(res.NaoVinculados, entities.CompetitorUnmatched{
+					Linha: row.Line, Ean: p.Ean, Concorrente: p.Concorrente,
+				})
+				return nil
+			}
+			if err != nil {
+				repoErr = err
+				return err
+			}
+			p.Produto = produto
+		}
+		precos = append(precos, p)
+		return nil
+	})
+	if repoErr != nil {
+		return res, repoErr
+	}
+	if err != nil {
+		return res, fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+	}
+
+	if len(precos) > 0 {
+		if err := uc.competitorRepo.SaveCompetitorPrices(precos); err != nil {
+			return res, err
+		}
+	}
+	res.Importados = len(precos)
+	return res, nil
+}
+
+// Positioning compara o preço alfa de cada SKU com o menor preço e a mediana dos concorrentes.
+// No modo ancorado o preço sugerido é o menor concorrente menos o desconto, desde que a
+// margem a esse preço não fique abaixo do piso; caso contrário mantém o preço alfa.
+// Sem piso informado vale o lucro padrão desejado do cálculo de cada SKU.
+func (uc *competitorUseCaseImpl) Positioning(skus []string, opts entities.PositioningOptions) ([]entities.CompetitorPositioning, error) {
+	if opts.Dias <= 0 {
+		opts.Dias = diasConcorrentePadrao
+	}
+	if opts.DescontoAncora < 0 || opts.DescontoAncora >= 1 {
+		return nil, fmt.Errorf("%w: desconto de ancoragem deve estar entre 0 e 1", ErrInvalidRequest)
+	}
+	if opts.MargemMinima != nil && (*opts.MargemMinima < 0 || *opts.MargemMinima >= 1) {
+		return nil, fmt.Errorf("%w: margem mínima deve estar entre 0 e 1", ErrInvalidRequest)
+	}
+	desde := uc.now().AddDate(0, 0, -opts.Dias)
+
+	relatorio := make([]entities.CompetitorPositioning, 0, len(skus))
+	for _, sku := range skus {
+		pos := entities.CompetitorPositioning{Sku: sku}
+
+		result, err := uc.priceUC.CalculatePrice(entities.PriceRequest{Sku: sku})
+		if err != nil {
+			// Um SKU com problema não derruba o relatório inteiro
+			pos.Erro = err.Error()
+			relatorio = append(relatorio, pos)
+			continue
+		}
+		pos.PrecoAlfa = result.ValorFinal
+		pos.PrecoSugerido = result.ValorFinal
+
+		concorrentes, err := uc.competitorRepo.GetLatestCompetitorPrices(sku, desde)
+		if err != nil {
+			return nil, err
+		}
+		if len(concorrentes) == 0 {
+			pos.Motivo = fmt.Sprintf("sem preços de concorrentes nos últimos %d dias", opts.Dias)
+			relatorio = append(relatorio, pos)
+			continue
+		}
+		sort.Slice(concorrentes, func(i, j int) bool { return concorrentes[i].Preco < concorrentes[j].Preco })
+		pos.Concorrentes = concorrentes
+		pos.MenorPreco = concorrentes[0].Preco
+		pos.MenorConcorrente = concorrentes[0].Concorrente
+		pos.Mediana = round2(entities.MedianaPrecos(concorrentes))
+		pos.IndiceMenor = pos.PrecoAlfa/pos.MenorPreco - 1
+		pos.IndiceMediana = pos.PrecoAlfa/pos.Mediana - 1
+
+		if opts.Ancorado {
+			pos.PrecoAncorado = round2(pos.MenorPreco * (1 - opts.DescontoAncora))
+			pos.MargemAncorado = result.MargemAoPreco(pos.PrecoAncorado)
+			pos.MargemMinima = result.Params.LucroPadraoDesejado
+			if opts.MargemMinima != nil {
+				pos.MargemMinima = *opts.MargemMinima
+			}
+			if pos.MargemAncorado >= pos.MargemMinima {
+				pos.PrecoSugerido = pos.PrecoAncorado
+				pos.AncoragemAplicada = true
+				pos.Motivo = fmt.Sprintf("ancorado em %s (%.2f) com desconto de %.2f%%",
+					pos.MenorConcorrente, pos.MenorPreco, opts.DescontoAncora*100)
+			} else {
+				pos.Motivo = fmt.Sprintf("margem de %.2f%% ao preço ancorado abaixo do piso de %.2f%%: mantido o preço alfa",
+					pos.MargemAncorado*100, pos.MargemMinima*100)
+			}
+		}
+		relatorio = append(relatorio, pos)
+	}
+	return relatorio, nil
+}
@@ -0,0 +1,362 @@
+package usecase
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"calculator/domain/entities"
+	"calculator/domain/repositories"
+)
+
+// Requisitos mínimos para uma estimativa
+const (
+	// semanasMinimasSku é o número mínimo de semanas com venda para estimar um SKU
+	semanasMinimasSku = 6
+	// observacoesMinimasDepartamento vale para o modelo agrupado do departamento
+	observacoesMinimasDepartamento = 12
+	// variacaoMinimaPreco é o desvio-padrão mínimo do log do preço (~1%)
+	variacaoMinimaPreco = 0.01
+)
+
+// ElasticityUseCase estima a elasticidade-preço e sugere o preço que maximiza o lucro
+type ElasticityUseCase interface {
+	// EstimateElasticities estima por SKU e por departamento e grava as estimativas
+	EstimateElasticities(req entities.ElasticityRequest) ([]entities.ElasticityEstimate, error)
+	// StartEstimate agenda a estimativa como job; o período inteiro não cabe em uma requisição
+	StartEstimate(usuario string, req entities.ElasticityRequest) (entities.PriceJob, error)
+	// GetEstimate devolve o job e as estimativas, nil enquanto não concluído
+	GetEstimate(id string) (entities.PriceJob, []entities.ElasticityEstimate, error)
+	SuggestPrice(sku string, variacaoMaxima float64) (entities.PriceSuggestion, error)
+}
+
+// elasticityUseCaseImpl implementa ElasticityUseCase
+type elasticityUseCaseImpl struct {
+	salesRepo      repositories.SalesRepository
+	productRepo    repositories.ProductRepository
+	elasticityRepo repositories.ElasticityRepository
+	priceUC        PriceUseCase
+	jobUC          JobUseCase
+	now            func() time.Time
+}
+
+// NewElasticityUseCase cria o caso de uso de elasticidade e registra a estimativa nos jobs
+func NewElasticityUseCase(sr repositories.SalesRepository, pr repositories.ProductRepository,
+	er repositories.ElasticityRepository, pu PriceUseCase, ju JobUseCase) ElasticityUseCase {
+	uc := &elasticityUseCaseImpl{salesRepo: sr, productRepo: pr, elasticityRepo: er, priceUC: pu, jobUC: ju, now: time.Now}
+	ju.RegisterReport(entities.JobElasticidade, uc.runReport)
+	return uc
+}
+
+// pontoDemanda é uma semana de vendas em log: x = ln(preço médio), y = ln(quantidade)
+type pontoDemanda struct {
+	x, y float64
+}
+
+// StartEstimate valida o período e grava o job
+func (uc *elasticityUseCaseImpl) StartEstimate(usuario string, req entities.ElasticityRequest) (entities.PriceJob, error) {
+	if err := validateElasticityPeriod(req); err != nil {
+		return entities.PriceJob{}, err
+	}
+	return uc.jobUC.CreateReportJob(usuario, entities.JobElasticidade, req)
+}
+
+// GetEstimate lê o resultado gravado pelo job
+func (uc *elasticityUseCaseImpl) GetEstimate(id string) (entities.PriceJob, []entities.ElasticityEstimate, error) {
+	job, corpo, err := uc.jobUC.GetReport(id, entities.JobElasticidade)
+	if err != nil || corpo == nil {
+		return job, nil, err
+	}
+	var estimativas []entities.ElasticityEstimate
+	if err := json.Unmarshal(corpo, &estimativas); err != nil {
+		return job, nil, fmt.Errorf("GetEstimate unmarshal: %w", err)
+	}
+	return job, estimativas, nil
+}
+
+// runReport executa a estimativa do job
+func (uc *elasticityUseCaseImpl) runReport(filtro json.RawMessage) (interface{}, error) {
+	var req entities.ElasticityRequest
+	if err := json.Unmarshal(filtro, &req); err != nil {
+		return nil, fmt.Errorf("filtro inválido: %w", err)
+	}
+	return uc.EstimateElasticities(req)
+}
+
+// validateElasticityPeriod exige o período completo e em ordem
+func validateElasticityPeriod(req entities.ElasticityRequest) error {
+	if req.De.IsZero() || req.Ate.IsZero() || req.Ate.Before(req.De) {
+		return fmt.Errorf("%w: informe um período válido em de e ate", ErrInvalidRequest)
+	}
+	return nil
+}
+
+// EstimateElasticities agrega as vendas por semana e ajusta ln(q) = a + e·ln(p).
+// No departamento cada SKU tem o próprio intercepto (efeito fixo), então só a
+// variação de preço dentro do SKU explica a variação de quantidade.
+func (uc *elasticityUseCaseImpl) EstimateElasticities(req entities.ElasticityRequest) ([]entities.ElasticityEstimate, error) {
+	if err := validateElasticityPeriod(req); err != nil {
+		return nil, err
+	}
+
+	vendas, err := uc.salesRepo.GetSales(entities.SalesFilter{De: req.De, Ate: req.Ate, Skus: req.Skus})
+	if err != nil {
+		return nil, err
+	}
+
+	pontos := weeklyDemandPoints(vendas)
+	skus := make([]string, 0, len(pontos))
+	for sku := range pontos {
+		skus = append(skus, sku)
+	}
+	sort.Strings(skus)
+
+	agora := uc.now()
+	novo := func(escopo, chave string, grupos [][]pontoDemanda) (entities.ElasticityEstimate, bool) {
+		reg, ok := logLogRegression(grupos)
+		if !ok {
+			return entities.ElasticityEstimate{}, false
+		}
+		e := entities.ElasticityEstimate{
+			Escopo:         escopo,
+			Chave:          chave,
+			Elasticidade:   reg.coef,
+			ErroPadrao:     reg.erroPadrao,
+			R2:             reg.r2,
+			Observacoes:    reg.n,
+			GrausLiberdade: reg.gl,
+			Confianca:      entities.ClassificarConfianca(reg.coef, reg.erroPadrao, reg.gl),
+			De:             req.De,
+			Ate:            req.Ate,
+			CalculadoEm:    agora,
+		}
+		e.CalcularIntervalo()
+		return e, true
+	}
+
+	var estimativas []entities.ElasticityEstimate
+	porDepartamento := map[int][][]pontoDemanda{}
+	for _, sku := range skus {
+		p := pontos[sku]
+		if len(p) >= semanasMinimasSku {
+			if e, ok := novo(entities.EscopoSku, sku, [][]pontoDemanda{p}); ok {
+				estimativas = append(estimativas, e)
+			}
+		}
+		if len(p) < 2 {
+			continue
+		}
+		cf, err := uc.productRepo.GetCostFire(sku)
+		if err != nil {
+			// Sem cadastro no ERP o SKU fica fora do agrupamento do departamento
+			continue
+		}
+		porDepartamento[cf.Departamento] = append(porDepartamento[cf.Departamento], p)
+	}
+
+	departamentos := make([]int, 0, len(porDepartamento))
+	for d := range porDepartamento {
+		departamentos = append(departamentos, d)
+	}
+	sort.Ints(departamentos)
+	for _, d := range departamentos {
+		grupos := porDepartamento[d]
+		n := 0
+		for _, g := range grupos {
+			n += len(g)
+		}
+		if n < observacoesMinimasDepartamento {
+			continue
+		}
+		if e, ok := novo(entities.EscopoDepartamento, strconv.Itoa(d), grupos); ok {
+			estimativas = append(estimativas, e)
+		}
+	}
+
+	if len(estimativas) > 0 {
+		if err := uc.elasticityRepo.SaveElasticityEstimates(estimativas); err != nil {
+			return nil, err
+		}
+	}
+	return estimativas, nil
+}
+
+// SuggestPrice usa a elasticidade do SKU ou, sem estimativa confiável, a do departamento.
+// Com custo unitário C e preço de equilíbrio Peq o lucro por unidade é C·(p/Peq − 1), e com
+// q ∝ p^e o lucro máximo fica em p* = Peq·e/(1+e) quando e < −1. A sugestão respeita o
+// preço de equilíbrio e a variação máxima sobre o preço alfa.
+func (uc *elasticityUseCaseImpl) SuggestPrice(sku string, variacaoMaxima float64) (entities.PriceSuggestion, error) {
+	if variacaoMaxima <= 0 {
+		variacaoMaxima = entities.VariacaoMaximaPadrao
+	}
+	if variacaoMaxima >= 1 {
+		return entities.PriceSuggestion{}, fmt.Errorf("%w: variacaoMaxima deve ser menor que 1", ErrInvalidRequest)
+	}
+
+	result, err := uc.priceUC.CalculatePrice(entities.PriceRequest{Sku: sku})
+	if err != nil {
+		return entities.PriceSuggestion{}, err
+	}
+	s := entities.PriceSuggestion{
+		Sku:             sku,
+		PrecoAlfa:       result.ValorFinal,
+		PrecoEquilibrio: round2(result.PrecoEquilibrio),
+		PrecoSugerido:   result.ValorFinal,
+		VariacaoMaxima:  variacaoMaxima,
+		MargemAlfa:      result.MargemAoPreco(result.ValorFinal),
+	}
+
+	estimativa, err := uc.usableEstimate(sku, result.Cost.Departamento)
+	if err != nil {
+		return s, err
+	}
+	if estimativa == nil {
+		s.MargemSugerida = s.MargemAlfa
+		s.Motivo = "sem elasticidade estimada com confiança suficiente: mantido o preço alfa"
+		return s, nil
+	}
+	s.Estimativa = estimativa
+
+	minimo := math.Max(result.PrecoEquilibrio, result.ValorFinal*(1-variacaoMaxima))
+	maximo := result.ValorFinal * (1 + variacaoMaxima)
+	e := estimativa.Elasticidade
+	var alvo float64
+	if e < -1 {
+		s.PrecoOtimo = round2(result.PrecoEquilibrio * e / (1 + e))
+		alvo = s.PrecoOtimo
+		s.Motivo = fmt.Sprintf("elasticidade %.2f (%s, confiança %s): ótimo em Peq·e/(1+e)",
+			e, estimativa.Escopo, estimativa.Confianca)
+	} else {
+		// Demanda inelástica: o lucro cresce com o preço até o limite de variação
+		alvo = maximo
+		s.Limitado = true
+		s.Motivo = fmt.Sprintf("elasticidade %.2f (%s, confiança %s) inelástica: limitado à variação máxima",
+			e, estimativa.Escopo, estimativa.Confianca)
+	}
+
+	switch {
+	case alvo > maximo:
+		alvo = maximo
+		s.Limitado = true
+	case alvo < minimo:
+		alvo = minimo
+		s.Limitado = true
+	}
+	s.PrecoSugerido = round2(alvo)
+	if s.PrecoSugerido < result.PrecoEquilibrio {
+		s.PrecoSugerido = math.Ceil(result.PrecoEquilibrio*100) / 100
+	}
+	s.MargemSugerida = result.MargemAoPreco(s.PrecoSugerido)
+	return s, nil
+}
+
+// usableEstimate devolve a estimativa utilizável do SKU ou do departamento, ou nil
+func (uc *elasticityUseCaseImpl) usableEstimate(sku string, departamento int) (*entities.ElasticityEstimate, error) {
+	escopos := [][2]string{
+		{entities.EscopoSku, sku},
+		{entities.EscopoDepartamento, strconv.Itoa(departamento)},
+	}
+	for _, esc := range escopos {
+		e, err := uc.elasticityRepo.GetElasticityEstimate(esc[0], esc[1])
+		if errors.Is(err, repositories.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if e.Utilizavel() {
+			return &e, nil
+		}
+	}
+	return nil, nil
+}
+
+// weeklyDemandPoints agrega as vendas de cada SKU por semana ISO com preço médio ponderado
+func weeklyDemandPoints(vendas []entities.SaleLine) map[string][]pontoDemanda {
+	type semana struct {
+		quantidade, receita float64
+	}
+	porSku := map[string]map[int]*semana{}
+	for _, v := range vendas {
+		if v.Quantidade <= 0 || v.PrecoUnitario <= 0 {
+			continue
+		}
+		ano, sem := v.Data.ISOWeek()
+		chave := ano*100 + sem
+		if porSku[v.Sku] == nil {
+			porSku[v.Sku] = map[int]*semana{}
+		}
+		s, ok := porSku[v.Sku][chave]
+		if !ok {
+			s = &semana{}
+			porSku[v.Sku][chave] = s
+		}
+		s.quantidade += v.Quantidade
+		s.receita += v.Receita()
+	}
+
+	pontos := map[string][]pontoDemanda{}
+	for sku, semanas := range porSku {
+		for _, s := range semanas {
+			pontos[sku] = append(pontos[sku], pontoDemanda{
+				x: math.Log(s.receita / s.quantidade),
+				y: math.Log(s.quantidade),
+			})
+		}
+	}
+	return pontos
+}
+
+// regressao é o ajuste log-log: coeficiente, erro padrão, R² dentro dos grupos, número de
+// observações e graus de liberdade dos resíduos
+type regressao struct {
+	coef, erroPadrao, r2 float64
+	n, gl                int
+}
+
+// logLogRegression ajusta a inclinação comum dos grupos, cada um com o próprio intercepto.
+// Com um único grupo é a regressão simples. ok falso sem graus de liberdade ou variação de preço.
+func logLogRegression(grupos [][]pontoDemanda) (regressao, bool) {
+	var sxx, sxy, syy float64
+	var demeaned []pontoDemanda
+	for _, g := range grupos {
+		if len(g) == 0 {
+			continue
+		}
+		var mx, my float64
+		for _, p := range g {
+			mx += p.x
+			my += p.y
+		}
+		mx /= float64(len(g))
+		my /= float64(len(g))
+		for _, p := range g {
+			d := pontoDemanda{x: p.x - mx, y: p.y - my}
+			sxx += d.x * d.x
+			sxy += d.x * d.y
+			syy += d.y * d.y
+			demeaned = append(demeaned, d)
+		}
+	}
+	reg := regressao{n: len(demeaned)}
+	reg.gl = reg.n - len(grupos) - 1
+	if reg.gl <= 0 || sxx <= 0 || math.Sqrt(sxx/float64(reg.n)) < variacaoMinimaPreco {
+		return reg, false
+	}
+
+	reg.coef = sxy / sxx
+	var ssr float64
+	for _, d := range demeaned {
+		r := d.y - reg.coef*d.x
+		ssr += r * r
+	}
+	reg.erroPadrao = math.Sqrt(ssr / float64(reg.gl) / sxx)
+	if syy > 0 {
+		reg.r2 = 1 - ssr/syy
+	}
+	return reg, true
+}
@@ -0,0 +1,106 @@
+package usecase
+
+import (
+	"math"
+	"testing"
+
+	"calculator/domain/entities"
+)
+
+func quase(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+// Dois SKUs com interceptos diferentes e a mesma inclinação: depois de tirar a média de
+// cada grupo, sxx = 4, sxy = -6 e a soma dos resíduos ao quadrado é 1/3 com 3 graus de
+// liberdade (6 observações, 2 interceptos e a inclinação).
+func TestLogLogRegressionDentroDosGrupos(t *testing.T) {
+	grupos := [][]pontoDemanda{
+		{{x: 0, y: 1}, {x: 1, y: 0}, {x: 2, y: -2}},
+		{{x: 0, y: 5}, {x: 1, y: 3}, {x: 2, y: 2}},
+	}
+
+	reg, ok := logLogRegression(grupos)
+	if !ok {
+		t.Fatal("regressão recusada")
+	}
+	if reg.n != 6 || reg.gl != 3 {
+		t.Fatalf("n = %d, gl = %d", reg.n, reg.gl)
+	}
+	if !quase(reg.coef, -1.5) {
+		t.Errorf("coef = %v, esperado -1.5", reg.coef)
+	}
+	// sqrt((1/3) / 3 / 4)
+	if !quase(reg.erroPadrao, 1.0/6) {
+		t.Errorf("erro padrão = %v, esperado 1/6", reg.erroPadrao)
+	}
+	// 1 - (1/3) / (84/9)
+	if !quase(reg.r2, 27.0/28) {
+		t.Errorf("r2 = %v, esperado 27/28", reg.r2)
+	}
+}
+
+// Sem os efeitos fixos a diferença de nível entre os SKUs inverteria o sinal
+func TestLogLogRegressionIgnoraNivelEntreGrupos(t *testing.T) {
+	grupos := [][]pontoDemanda{
+		{{x: 0, y: 1}, {x: 0.1, y: 0.9}, {x: 0.2, y: 0.8}},
+		{{x: 1, y: 5}, {x: 1.1, y: 4.9}, {x: 1.2, y: 4.8}},
+	}
+
+	reg, ok := logLogRegression(grupos)
+	if !ok {
+		t.Fatal("regressão recusada")
+	}
+	if !quase(reg.coef, -1) || !quase(reg.erroPadrao, 0) {
+		t.Errorf("coef = %v, erro padrão = %v", reg.coef, reg.erroPadrao)
+	}
+}
+
+func TestLogLogRegressionSemGrausDeLiberdade(t *testing.T) {
+	if _, ok := logLogRegression([][]pontoDemanda{{{x: 0, y: 1}, {x: 1, y: 0}}}); ok {
+		t.Error("duas observações não deixam grau de liberdade")
+	}
+	if _, ok := logLogRegression([][]pontoDemanda{{{x: 1, y: 1}, {x: 1, y: 2}, {x: 1, y: 3}}}); ok {
+		t.Error("sem variação de preço não há estimativa")
+	}
+}
+
+// Com 3 graus de liberdade t = 9 passa o valor crítico de 99% (5,841) e o intervalo
+// de 95% usa 3,182 em vez de 1,96
+func TestEstimativaComIntervaloT(t *testing.T) {
+	e := entities.ElasticityEstimate{Elasticidade: -1.5, ErroPadrao: 1.0 / 6, GrausLiberdade: 3}
+	e.CalcularIntervalo()
+
+	if !quase(e.IntervaloMin, -1.5-3.182/6) || !quase(e.IntervaloMax, -1.5+3.182/6) {
+		t.Errorf("intervalo = [%v, %v]", e.IntervaloMin, e.IntervaloMax)
+	}
+	if c := entities.ClassificarConfianca(-1.5, 1.0/6, 3); c != entities.ConfiancaAlta {
+		t.Errorf("confiança = %s, esperada alta", c)
+	}
+	// t = 3 fica abaixo do valor crítico de 95% com 3 graus de liberdade (3,182)
+	if c := entities.ClassificarConfianca(-0.5, 1.0/6, 3); c != entities.ConfiancaBaixa {
+		t.Errorf("confiança = %s, esperada baixa", c)
+	}
+	// com a normal t = 3 seria alta
+	if c := entities.ClassificarConfianca(-0.5, 1.0/6, 0); c != entities.ConfiancaAlta {
+		t.Errorf("confiança = %s, esperada alta pela normal", c)
+	}
+}
+
+func TestValorCriticoTAcimaDaTabela(t *testing.T) {
+	// Expansão de Cornish-Fisher contra os valores tabelados de 40 e 120 graus
+	casos := []struct {
+		gl, nivel int
+		esperado  float64
+	}{
+		{40, entities.Nivel95, 2.021},
+		{120, entities.Nivel95, 1.980},
+		{40, entities.Nivel99, 2.704},
+		{120, entities.Nivel99, 2.617},
+	}
+	for _, c := range casos {
+		if v := entities.ValorCriticoT(c.gl, c.nivel); math.Abs(v-c.esperado) > 0.002 {
+			t.Errorf("t(%d, %d) = %.4f, esperado %.3f", c.gl, c.nivel, v, c.esperado)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"calculator/domain/entities"
+	"calculator/domain/repositories"
+
+	"github.com/sirupsen/logrus"
+)
+
+// FirebirdCacheUseCase mantém a última leitura boa do Firebird e a entrega quando ele
+// não responde, desde que não passe da idade máxima
+type FirebirdCacheUseCase interface {
+	Refresh() (entities.FirebirdCacheInfo, error)
+	// StartAutoRefresh atualiza o cache já na partida e depois a cada intervalo
+	StartAutoRefresh(interval time.Duration, stop <-chan struct{})
+	// CostFire e PerfilFiscal devolvem a leitura guardada e a data dela;
+	// ErrUnavailable sem cache ou com cache acima da idade máxima
+	CostFire(sku string) (entities.CostFire, time.Time, error)
+	PerfilFiscal(produto int) (entities.PerfilFiscal, time.Time, error)
+	Status() entities.FirebirdCacheStatus
+}
+
+// firebirdCacheUseCaseImpl implementa FirebirdCacheUseCase
+type firebirdCacheUseCaseImpl struct {
+	cacheRepo   repositories.FirebirdCacheRepository
+	idadeMaxima time.Duration
+	now         func() time.Time
+
+	mu     sync.Mutex
+	status entities.FirebirdCacheStatus
+}
+
+// NewFirebirdCacheUseCase cria o cache do Firebird com a idade máxima aceita no cálculo
+func NewFirebirdCacheUseCase(cr repositories.FirebirdCacheRepository, idadeMaxima time.Duration) FirebirdCacheUseCase {
+	return &firebirdCacheUseCaseImpl{
+		cacheRepo:   cr,
+		idadeMaxima: idadeMaxima,
+		now:         time.Now,
+		status:      entities.FirebirdCacheStatus{IdadeMaximaSegundos: idadeMaxima.Seconds()},
+	}
+}
+
+// Refresh regrava o cache com a leitura atual do Firebird
+func (uc *firebirdCacheUseCaseImpl) Refresh() (entities.FirebirdCacheInfo, error) {
+	info, err := uc.cacheRepo.RefreshFirebirdCache()
+
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	if err != nil {
+		uc.status.Falhas++
+		uc.status.UltimoErro = err.Error()
+		return info, err
+	}
+	uc.status.Atualizacoes++
+	uc.status.UltimoErro = ""
+	uc.status.Ultima = &info
+
+	logrus.WithFields(logrus.Fields{
+		"custos_fire":    info.CustosFire,
+		"perfis_fiscais": info.PerfisFiscais,
+		"duracao_ms":     info.DuracaoMs,
+	}).Info("Cache do Firebird atualizado")
+	return info, nil
+}
+
+// StartAutoRefresh atualiza em segundo plano; a primeira leitura não atrasa a partida
+func (uc *firebirdCacheUseCaseImpl) StartAutoRefresh(interval time.Duration, stop <-chan struct{}) {
+	if interval <= 0 {
+		return
+	}
+	go func() {
+		refresh := func() {
+			if _, err := uc.Refresh(); err != nil {
+				logrus.WithError(err).Warn("Falha ao atualizar o cache do Firebird, mantendo a leitura anterior")
+			}
+		}
+		refresh()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				refresh()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// CostFire devolve o CostFire guardado do SKU
+func (uc *firebirdCacheUseCaseImpl) CostFire(sku string) (entities.CostFire, time.Time, error) {
+	cf, em, err := uc.cacheRepo.GetCachedCostFire(sku)
+	return cf, em, uc.check("CostFire do sku "+sku, em, err)
+}
+
+// PerfilFiscal devolve o perfil fiscal guardado do produto
+func (uc *firebirdCacheUseCaseImpl) PerfilFiscal(produto int) (entities.PerfilFiscal, time.Time, error) {
+	p, em, err := uc.cacheRepo.GetCachedPerfilFiscal(produto)
+	return p, em, uc.check(fmt.Sprintf("perfil fiscal do produto %d", produto), em, err)
+}
+
+// check recusa a leitura ausente ou mais velha que a idade máxima e conta os usos
+func (uc *firebirdCacheUseCaseImpl) check(dado string, em time.Time, err error) error {
+	switch {
+	case errors.Is(err, repositories.ErrNotFound):
+		err = fmt.Errorf("%w: Firebird fora do ar e %s sem leitura em cache", repositories.ErrUnavailable, dado)
+	case err != nil:
+		return err
+	default:
+		if idade := uc.now().Sub(em); idade > uc.idadeMaxima {
+			err = fmt.Errorf("%w: Firebird fora do ar e o %s em cache tem %s, acima do máximo de %s",
+				repositories.ErrUnavailable, dado, idade.Round(time.Second), uc.idadeMaxima)
+		}
+	}
+
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	if err != nil {
+		uc.status.Recusas++
+		return err
+	}
+	uc.status.Usos++
+	return nil
+}
+
+// Status informa a última atualização e os contadores de uso
+func (uc *firebirdCacheUseCaseImpl) Status() entities.FirebirdCacheStatus {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	return uc.status
+}
@@ -0,0 +1,74 @@
+package usecase
+
+import (
+	"sync/atomic"
+	"time"
+
+	"calculator/domain/entities"
+	"calculator/domain/repositories"
+
+	"github.com/sirupsen/logrus"
+)
+
+// FreightUseCase mantém em memória as tabelas de frete e cota o envio de um produto
+type FreightUseCase interface {
+	Reload() (entities.FreightTables, error)
+	Tables() entities.FreightTables
+	// StartAutoReload recarrega as tabelas periodicamente até o canal stop ser fechado
+	StartAutoReload(interval time.Duration, stop <-chan struct{})
+}
+
+// freightUseCaseImpl implementa FreightUseCase
+type freightUseCaseImpl struct {
+	freightRepo repositories.FreightRepository
+	tables      atomic.Value // entities.FreightTables
+}
+
+// NewFreightUseCase cria o caso de uso de frete; as tabelas começam vazias até o primeiro Reload
+func NewFreightUseCase(fr repositories.FreightRepository) FreightUseCase {
+	uc := &freightUseCaseImpl{freightRepo: fr}
+	uc.tables.Store(entities.FreightTables{Subsidios: map[string]entities.RegraFreteSubsidiado{}})
+	return uc
+}
+
+// Reload carrega as tabelas da fonte configurada e troca a versão em uso
+func (uc *freightUseCaseImpl) Reload() (entities.FreightTables, error) {
+	t, err := uc.freightRepo.LoadFreightTables()
+	if err != nil {
+		return entities.FreightTables{}, err
+	}
+	uc.tables.Store(t)
+
+	logrus.WithFields(logrus.Fields{
+		"fonte":     t.Fonte,
+		"faixas":    len(t.Faixas),
+		"subsidios": len(t.Subsidios),
+	}).Info("Tabelas de frete carregadas")
+	return t, nil
+}
+
+// Tables devolve as tabelas em uso
+func (uc *freightUseCaseImpl) Tables() entities.FreightTables {
+	return uc.tables.Load().(entities.FreightTables)
+}
+
+// StartAutoReload recarrega as tabelas a cada intervalo em segundo plano
+func (uc *freightUseCaseImpl) StartAutoReload(interval time.Duration, stop <-chan struct{}) {
+	if interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if _, err := uc.Reload(); err != nil {
+					logrus.WithError(err).Warn("Falha ao recarregar tabelas de frete, mantendo a versão anterior")
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
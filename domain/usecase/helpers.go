@@ -0,0 +1,50 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"calculator/domain/entities"
+	"calculator/domain/repositories"
+)
+
+// ErrInvalidRequest indica dados de entrada inválidos (o controller responde 400)
+var ErrInvalidRequest = errors.New("requisição inválida")
+
+// ErrUnauthorized indica requisição sem usuário autenticado (o controller responde 401)
+var ErrUnauthorized = errors.New("usuário não autenticado")
+
+// ErrForbidden indica usuário sem permissão para a ação (o controller responde 403)
+var ErrForbidden = errors.New("ação não permitida ao usuário")
+
+// newID gera um identificador aleatório no formato UUID v4
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("erro ao gerar ID: %w", err)
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	h := hex.EncodeToString(b)
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:], nil
+}
+
+// round2 arredonda valores monetários para centavos
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// requireFresh recusa o cálculo feito com o cache do Firebird: orçamentos, recálculos, jobs e
+// diferenças são gravados ou viram propostas, e não podem herdar dados desatualizados
+func requireFresh(result entities.PriceResult) error {
+	d := result.Desatualizado
+	if d == nil {
+		return nil
+	}
+	return fmt.Errorf("%w: cálculo com dados desatualizados do Firebird (%s, %.0fs)",
+		repositories.ErrUnavailable, strings.Join(d.Dados, ", "), d.IdadeSegundos)
+}
@@ -0,0 +1,380 @@
+package usecase
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"calculator/domain/entities"
+	"calculator/domain/repositories"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Execução dos jobs em lote
+const (
+	// maxSkusJob limita o tamanho de um job
+	maxSkusJob = 200000
+	// loteJob é o número de itens calculados e gravados por vez
+	loteJob = 100
+	// reservaJob é renovada a cada lote; vencida, outra instância retoma o job
+	reservaJob = 2 * time.Minute
+	// intervaloJobs é a espera entre buscas quando não há job a executar
+	intervaloJobs = 5 * time.Second
+	// limiteErrosJob é o número de erros devolvidos na consulta do job
+	limiteErrosJob = 100
+)
+
+// JobUseCase executa cálculos de preço em lote de forma assíncrona
+type JobUseCase interface {
+	CreatePriceJob(req entities.PriceJobRequest) (entities.PriceJob, error)
+	GetJob(id string) (entities.PriceJob, error)
+	CancelJob(id string) (entities.PriceJob, error)
+	// EachResult percorre os resultados em ordem; itens ainda pendentes também são entregues
+	EachResult(id string, fn func(entities.PriceJobItem) error) error
+	// CreateReportJob grava um relatório assíncrono com o filtro; o resultado fica no job
+	CreateReportJob(usuario, tipo string, filtro interface{}) (entities.PriceJob, error)
+	// GetReport devolve o job do tipo e o resultado, nil enquanto não concluído
+	GetReport(id, tipo string) (entities.PriceJob, json.RawMessage, error)
+	// RegisterReport associa o tipo de relatório a quem o executa; chamar antes de Start
+	RegisterReport(tipo string, fn ReportRunner)
+	// Start executa os jobs em segundo plano até o canal stop ser fechado
+	Start(concorrencia int, stop <-chan struct{})
+}
+
+// ReportRunner executa um relatório a partir do filtro gravado no job
+type ReportRunner func(filtro json.RawMessage) (interface{}, error)
+
+// jobUseCaseImpl implementa JobUseCase
+type jobUseCaseImpl struct {
+	jobRepo    repositories.JobRepository
+	priceUC    PriceUseCase
+	relatorios map[string]ReportRunner
+	now        func() time.Time
+	sinal      chan struct{}
+}
+
+// NewJobUseCase cria o caso de uso de jobs em lote
+func NewJobUseCase(jr repositories.JobRepository, pu PriceUseCase) JobUseCase {
+	return &jobUseCaseImpl{
+		jobRepo:    jr,
+		priceUC:    pu,
+		relatorios: map[string]ReportRunner{},
+		now:        time.Now,
+		sinal:      make(chan struct{}, 1),
+	}
+}
+
+// CreatePriceJob valida as opções e grava o job com um item por SKU
+func (uc *jobUseCaseImpl) CreatePriceJob(req entities.PriceJobRequest) (entities.PriceJob, error) {
+	if req.Usuario == "" {
+		return entities.PriceJob{}, fmt.Errorf("%w: usuario é obrigatório", ErrInvalidRequest)
+	}
+	if req.TipoCliente != "" && !entities.TipoClienteValido(req.TipoCliente) {
+		return entities.PriceJob{}, fmt.Errorf("%w: tipo_cliente inválido", ErrInvalidRequest)
+	}
+	if req.Quantidade < 0 {
+		return entities.PriceJob{}, fmt.Errorf("%w: quantidade não pode ser negativa", ErrInvalidRequest)
+	}
+	skus := make([]string, 0, len(req.Skus))
+	for _, sku := range req.Skus {
+		if sku = strings.TrimSpace(sku); sku != "" {
+			skus = append(skus, sku)
+		}
+	}
+	if len(skus) == 0 {
+		return entities.PriceJob{}, fmt.Errorf("%w: nenhum sku informado", ErrInvalidRequest)
+	}
+	if len(skus) > maxSkusJob {
+		return entities.PriceJob{}, fmt.Errorf("%w: no máximo %d skus por job", ErrInvalidRequest, maxSkusJob)
+	}
+
+	id, err := newID()
+	if err != nil {
+		return entities.PriceJob{}, err
+	}
+	req.Skus = nil
+	job := entities.PriceJob{
+		ID:         id,
+		Tipo:       entities.JobPreco,
+		Status:     entities.JobPendente,
+		Usuario:    req.Usuario,
+		Parametros: req,
+		Total:      len(skus),
+		CriadoEm:   uc.now(),
+	}
+	if err := uc.jobRepo.CreateJob(job, skus); err != nil {
+		return job, err
+	}
+	uc.notify()
+	return job, nil
+}
+
+// CreateReportJob grava o relatório como um job de um único passo
+func (uc *jobUseCaseImpl) CreateReportJob(usuario, tipo string, filtro interface{}) (entities.PriceJob, error) {
+	if usuario == "" {
+		return entities.PriceJob{}, fmt.Errorf("%w: usuario é obrigatório", ErrInvalidRequest)
+	}
+	if _, ok := uc.relatorios[tipo]; !ok {
+		return entities.PriceJob{}, fmt.Errorf("CreateReportJob: tipo de relatório %q não registrado", tipo)
+	}
+	corpo, err := json.Marshal(filtro)
+	if err != nil {
+		return entities.PriceJob{}, fmt.Errorf("CreateReportJob marshal: %w", err)
+	}
+	id, err := newID()
+	if err != nil {
+		return entities.PriceJob{}, err
+	}
+	job := entities.PriceJob{
+		ID:       id,
+		Tipo:     tipo,
+		Status:   entities.JobPendente,
+		Usuario:  usuario,
+		Filtro:   corpo,
+		Total:    1,
+		CriadoEm: uc.now(),
+	}
+	if err := uc.jobRepo.CreateJob(job, nil); err != nil {
+		return job, err
+	}
+	uc.notify()
+	return job, nil
+}
+
+// GetReport trata job de outro tipo como inexistente
+func (uc *jobUseCaseImpl) GetReport(id, tipo string) (entities.PriceJob, json.RawMessage, error) {
+	job, err := uc.GetJob(id)
+	if err != nil {
+		return job, nil, err
+	}
+	if job.Tipo != tipo {
+		return job, nil, repositories.ErrNotFound
+	}
+	if job.Status != entities.JobConcluido {
+		return job, nil, nil
+	}
+	resultado, err := uc.jobRepo.GetReport(id)
+	if err != nil {
+		return job, nil, err
+	}
+	return job, resultado, nil
+}
+
+// RegisterReport registra o executor do tipo de relatório
+func (uc *jobUseCaseImpl) RegisterReport(tipo string, fn ReportRunner) {
+	uc.relatorios[tipo] = fn
+}
+
+// GetJob devolve o job com progresso, previsão e os primeiros erros
+func (uc *jobUseCaseImpl) GetJob(id string) (entities.PriceJob, error) {
+	job, err := uc.jobRepo.GetJob(id, limiteErrosJob)
+	if err != nil {
+		return job, err
+	}
+	job.CalcularProgresso(uc.now())
+	return job, nil
+}
+
+// CancelJob interrompe o job; os itens já calculados continuam disponíveis
+func (uc *jobUseCaseImpl) CancelJob(id string) (entities.PriceJob, error) {
+	if err := uc.jobRepo.CancelJob(id); err != nil {
+		return entities.PriceJob{}, err
+	}
+	return uc.GetJob(id)
+}
+
+// EachResult percorre os itens do job
+func (uc *jobUseCaseImpl) EachResult(id string, fn func(entities.PriceJobItem) error) error {
+	if _, err := uc.jobRepo.GetJob(id, 0); err != nil {
+		return err
+	}
+	return uc.jobRepo.EachJobItem(id, fn)
+}
+
+// Start busca um job por vez e o executa até terminar, ser cancelado ou o serviço parar.
+// Jobs interrompidos por uma parada são retomados do primeiro item pendente.
+func (uc *jobUseCaseImpl) Start(concorrencia int, stop <-chan struct{}) {
+	if concorrencia <= 0 {
+		return
+	}
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+
+			job, ok, err := uc.jobRepo.ClaimJob(reservaJob)
+			if err != nil {
+				logrus.WithError(err).Warn("Falha ao buscar jobs de cálculo")
+			}
+			if ok {
+				if job.Tipo == entities.JobPreco {
+					err = uc.run(job, concorrencia, stop)
+				} else {
+					err = uc.runReport(job, stop)
+				}
+				if err == nil {
+					continue
+				}
+			}
+			select {
+			case <-uc.sinal:
+			case <-time.After(intervaloJobs):
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// runReport executa o relatório renovando a reserva enquanto ele roda. Com o serviço
+// parando o job é liberado e recomeça do início em outra execução.
+func (uc *jobUseCaseImpl) runReport(job entities.PriceJob, stop <-chan struct{}) error {
+	log := logrus.WithFields(logrus.Fields{"job": job.ID, "tipo": job.Tipo})
+	fn, ok := uc.relatorios[job.Tipo]
+	if !ok {
+		return uc.jobRepo.SaveReport(job.ID, nil, "tipo de relatório não registrado nesta instância")
+	}
+	log.Info("Executando relatório")
+
+	type saida struct {
+		resultado interface{}
+		err       error
+	}
+	fim := make(chan saida, 1)
+	go func() {
+		resultado, err := fn(job.Filtro)
+		fim <- saida{resultado, err}
+	}()
+
+	ticker := time.NewTicker(reservaJob / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			uc.release(job.ID)
+			return nil
+		case <-ticker.C:
+			status, err := uc.jobRepo.RenewJob(job.ID, reservaJob)
+			if err != nil {
+				log.WithError(err).Warn("Falha ao renovar a reserva do relatório")
+			} else if status != entities.JobExecutando {
+				// Cancelado: o resultado é descartado quando o cálculo terminar
+				log.WithField("status", status).Info("Relatório interrompido")
+				return nil
+			}
+		case s := <-fim:
+			if s.err != nil {
+				log.WithError(s.err).Warn("Relatório encerrado com erro")
+				return uc.jobRepo.SaveReport(job.ID, nil, s.err.Error())
+			}
+			corpo, err := json.Marshal(s.resultado)
+			if err != nil {
+				return uc.jobRepo.SaveReport(job.ID, nil, fmt.Sprintf("resultado inválido: %v", err))
+			}
+			if err := uc.jobRepo.SaveReport(job.ID, corpo, ""); err != nil {
+				log.WithError(err).Error("Falha ao gravar o relatório")
+				uc.release(job.ID)
+				return err
+			}
+			log.Info("Relatório concluído")
+			return nil
+		}
+	}
+}
+
+// run processa os itens pendentes em lotes. Em erro de banco o job é liberado e será
+// retomado na próxima busca, do primeiro item pendente.
+func (uc *jobUseCaseImpl) run(job entities.PriceJob, concorrencia int, stop <-chan struct{}) error {
+	log := logrus.WithFields(logrus.Fields{"job": job.ID, "total": job.Total, "processados": job.Processados})
+	log.Info("Executando job de cálculo")
+
+	for {
+		select {
+		case <-stop:
+			uc.release(job.ID)
+			return nil
+		default:
+		}
+
+		itens, err := uc.jobRepo.GetPendingItems(job.ID, loteJob)
+		if err != nil {
+			log.WithError(err).Error("Falha ao ler itens do job")
+			uc.release(job.ID)
+			return err
+		}
+		if len(itens) == 0 {
+			if err := uc.jobRepo.FinishJob(job.ID, entities.JobConcluido); err != nil {
+				log.WithError(err).Error("Falha ao encerrar o job")
+				return err
+			}
+			log.Info("Job de cálculo concluído")
+			return nil
+		}
+
+		uc.calculateItems(job.Parametros, itens, concorrencia)
+		status, err := uc.jobRepo.SaveItemResults(job.ID, itens, reservaJob)
+		if err != nil {
+			log.WithError(err).Error("Falha ao gravar resultados do job")
+			uc.release(job.ID)
+			return err
+		}
+		if status != entities.JobExecutando {
+			log.WithField("status", status).Info("Job interrompido")
+			return nil
+		}
+	}
+}
+
+// calculateItems calcula o lote com até concorrencia cálculos simultâneos
+func (uc *jobUseCaseImpl) calculateItems(opts entities.PriceJobRequest, itens []entities.PriceJobItem, concorrencia int) {
+	var wg sync.WaitGroup
+	vagas := make(chan struct{}, concorrencia)
+	for i := range itens {
+		wg.Add(1)
+		vagas <- struct{}{}
+		go func(it *entities.PriceJobItem) {
+			defer func() { <-vagas; wg.Done() }()
+			result, err := uc.priceUC.CalculatePrice(entities.PriceRequest{
+				Sku:         it.Sku,
+				TipoCliente: opts.TipoCliente,
+				Quantidade:  opts.Quantidade,
+				UF:          opts.UF,
+				Canal:       opts.Canal,
+			})
+			if err == nil {
+				err = requireFresh(result)
+			}
+			if err != nil {
+				it.Status = entities.ItemErro
+				it.Erro = err.Error()
+				return
+			}
+			it.Status = entities.ItemOk
+			it.Preco = result.ValorFinal
+			it.PrecoAVista = result.PrecoAVista
+			it.PrecoEquilibrio = result.PrecoEquilibrio
+		}(&itens[i])
+	}
+	wg.Wait()
+}
+
+// notify acorda a busca de jobs sem bloquear
+func (uc *jobUseCaseImpl) notify() {
+	select {
+	case uc.sinal <- struct{}{}:
+	default:
+	}
+}
+
+// release libera a reserva; se falhar, o job é retomado quando a reserva vencer
+func (uc *jobUseCaseImpl) release(id string) {
+	if err := uc.jobRepo.ReleaseJob(id); err != nil {
+		logrus.WithError(err).WithField("job", id).Warn("Falha ao liberar o job")
+	}
+}
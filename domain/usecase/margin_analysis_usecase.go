@@ -0,0 +1,207 @@
+package usecase
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"calculator/domain/entities"
+	"calculator/domain/repositories"
+)
+
+// MarginAnalysisUseCase compara a margem realizada nas vendas com a prevista pelo preço alfa
+type MarginAnalysisUseCase interface {
+	AnalyzeMargins(filter entities.SalesFilter) (entities.MarginAnalysis, error)
+	// StartAnalysis agenda a análise como job; cada venda é recalculada
+	StartAnalysis(usuario string, filter entities.SalesFilter) (entities.PriceJob, error)
+	// GetAnalysis devolve o job e a análise, nil enquanto não concluída
+	GetAnalysis(id string) (entities.PriceJob, *entities.MarginAnalysis, error)
+}
+
+// marginAnalysisUseCaseImpl implementa MarginAnalysisUseCase
+type marginAnalysisUseCaseImpl struct {
+	salesRepo repositories.SalesRepository
+	priceUC   PriceUseCase
+	jobUC     JobUseCase
+}
+
+// NewMarginAnalysisUseCase cria a análise de margem realizada x prevista e a registra nos jobs
+func NewMarginAnalysisUseCase(sr repositories.SalesRepository, pu PriceUseCase, ju JobUseCase) MarginAnalysisUseCase {
+	uc := &marginAnalysisUseCaseImpl{salesRepo: sr, priceUC: pu, jobUC: ju}
+	ju.RegisterReport(entities.JobAnaliseMargem, uc.runReport)
+	return uc
+}
+
+// StartAnalysis valida o período e grava o job
+func (uc *marginAnalysisUseCaseImpl) StartAnalysis(usuario string, filter entities.SalesFilter) (entities.PriceJob, error) {
+	if err := validateSalesPeriod(filter); err != nil {
+		return entities.PriceJob{}, err
+	}
+	return uc.jobUC.CreateReportJob(usuario, entities.JobAnaliseMargem, filter)
+}
+
+// GetAnalysis lê o resultado gravado pelo job
+func (uc *marginAnalysisUseCaseImpl) GetAnalysis(id string) (entities.PriceJob, *entities.MarginAnalysis, error) {
+	job, corpo, err := uc.jobUC.GetReport(id, entities.JobAnaliseMargem)
+	if err != nil || corpo == nil {
+		return job, nil, err
+	}
+	var analise entities.MarginAnalysis
+	if err := json.Unmarshal(corpo, &analise); err != nil {
+		return job, nil, fmt.Errorf("GetAnalysis unmarshal: %w", err)
+	}
+	return job, &analise, nil
+}
+
+// runReport executa a análise do job
+func (uc *marginAnalysisUseCaseImpl) runReport(filtro json.RawMessage) (interface{}, error) {
+	var filter entities.SalesFilter
+	if err := json.Unmarshal(filtro, &filter); err != nil {
+		return nil, fmt.Errorf("filtro inválido: %w", err)
+	}
+	return uc.AnalyzeMargins(filter)
+}
+
+// AnalyzeMargins recalcula cada venda com o custo vigente na data da venda, o canal e o
+// destino. A margem realizada é a do preço praticado e a prevista é a do preço alfa.
+// Parâmetros, impostos e frete não têm histórico: o cálculo usa os atuais e a análise
+// sai marcada como estimativa (ParametrosAtuais).
+func (uc *marginAnalysisUseCaseImpl) AnalyzeMargins(filter entities.SalesFilter) (entities.MarginAnalysis, error) {
+	if err := validateSalesPeriod(filter); err != nil {
+		return entities.MarginAnalysis{}, err
+	}
+
+	vendas, err := uc.salesRepo.GetSales(filter)
+	if err != nil {
+		return entities.MarginAnalysis{}, err
+	}
+
+	analise := entities.MarginAnalysis{
+		De:               filter.De,
+		Ate:              filter.Ate,
+		ParametrosAtuais: true,
+		Total:            entities.MarginAnalysisRow{Chave: "total"},
+	}
+	porSku := map[string]*entities.MarginAnalysisRow{}
+	porDepartamento := map[string]*entities.MarginAnalysisRow{}
+	porCanal := map[string]*entities.MarginAnalysisRow{}
+	calculos := newSaleCalculator(uc.priceUC, nil)
+
+	for _, venda := range vendas {
+		result, err := calculos.price(venda)
+		if err != nil {
+			analise.Erros = append(analise.Erros, entities.SaleError{
+				Documento: venda.Documento, Sku: venda.Sku, Data: venda.Data, Erro: err.Error(),
+			})
+			continue
+		}
+		realizada := result.MargemAoPreco(venda.PrecoUnitario)
+		prevista := result.MargemAoPreco(result.ValorFinal)
+
+		canal := venda.Canal
+		if canal == "" {
+			canal = entities.CanalPadrao
+		}
+		analise.Total.Adicionar(venda, realizada, prevista)
+		marginRow(porSku, venda.Sku).Adicionar(venda, realizada, prevista)
+		marginRow(porDepartamento, strconv.Itoa(result.Cost.Departamento)).Adicionar(venda, realizada, prevista)
+		marginRow(porCanal, canal).Adicionar(venda, realizada, prevista)
+	}
+
+	analise.PorSku = sortedMarginRows(porSku)
+	analise.PorDepartamento = sortedMarginRows(porDepartamento)
+	analise.PorCanal = sortedMarginRows(porCanal)
+	return analise, nil
+}
+
+// validateSalesPeriod recusa o período invertido
+func validateSalesPeriod(filter entities.SalesFilter) error {
+	if !filter.De.IsZero() && !filter.Ate.IsZero() && filter.Ate.Before(filter.De) {
+		return fmt.Errorf("%w: ate anterior a de", ErrInvalidRequest)
+	}
+	return nil
+}
+
+// marginRow devolve a linha do agrupamento, criando-a na primeira venda
+func marginRow(grupo map[string]*entities.MarginAnalysisRow, chave string) *entities.MarginAnalysisRow {
+	row, ok := grupo[chave]
+	if !ok {
+		row = &entities.MarginAnalysisRow{Chave: chave}
+		grupo[chave] = row
+	}
+	return row
+}
+
+// sortedMarginRows ordena pelo maior desvio absoluto, onde a fórmula mais erra
+func sortedMarginRows(grupo map[string]*entities.MarginAnalysisRow) []entities.MarginAnalysisRow {
+	rows := make([]entities.MarginAnalysisRow, 0, len(grupo))
+	for _, r := range grupo {
+		rows = append(rows, *r)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		di, dj := rows[i].Desvio, rows[j].Desvio
+		if di < 0 {
+			di = -di
+		}
+		if dj < 0 {
+			dj = -dj
+		}
+		if di != dj {
+			return di > dj
+		}
+		return rows[i].Chave < rows[j].Chave
+	})
+	return rows
+}
+
+// saleCalculator recalcula o preço das vendas reaproveitando cálculos do mesmo
+// SKU, dia, canal, destino e quantidade
+type saleCalculator struct {
+	priceUC PriceUseCase
+	// params substitui os parâmetros do config_params quando informado
+	params *entities.Parameters
+	cache  map[string]saleCalculation
+}
+
+// saleCalculation guarda o resultado ou o erro de um cálculo
+type saleCalculation struct {
+	result entities.PriceResult
+	err    error
+}
+
+// newSaleCalculator cria o calculador com cache vazio
+func newSaleCalculator(pu PriceUseCase, params *entities.Parameters) *saleCalculator {
+	return &saleCalculator{priceUC: pu, params: params, cache: map[string]saleCalculation{}}
+}
+
+// price calcula o preço alfa da venda com o custo da data da venda
+func (c *saleCalculator) price(venda entities.SaleLine) (entities.PriceResult, error) {
+	quantidade := int(venda.Quantidade)
+	if quantidade < 1 {
+		quantidade = 1
+	}
+	uf := strings.ToUpper(strings.TrimSpace(venda.UF))
+	if !entities.UFs[uf] {
+		uf = ""
+	}
+
+	chave := strings.Join([]string{venda.Sku, venda.Data.Format("2006-01-02"), venda.Canal, uf, strconv.Itoa(quantidade)}, "|")
+	if cached, ok := c.cache[chave]; ok {
+		return cached.result, cached.err
+	}
+
+	req := entities.PriceRequest{
+		Sku:        venda.Sku,
+		Quantidade: quantidade,
+		DataCusto:  venda.Data,
+		UF:         uf,
+		Canal:      venda.Canal,
+		Parametros: c.params,
+	}
+	var calc saleCalculation
+	calc.result, calc.err = c.priceUC.CalculatePrice(req)
+	c.cache[chave] = calc
+	return calc.result, calc.err
+}
@@ -0,0 +1,186 @@
+package usecase
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"calculator/domain/entities"
+	"calculator/domain/repositories"
+	"calculator/internal/csvutil"
+)
+
+// MarkdownUseCase propõe remarcações de preço pela idade do estoque
+type MarkdownUseCase interface {
+	// ProposeMarkdowns calcula a lista proposta; sem posições informadas usa o estoque do serviço
+	ProposeMarkdowns(positions []entities.StockPosition) ([]entities.MarkdownProposal, error)
+	// ApplyMarkdowns aplica as regras aos preços já calculados; devolve só os SKUs com regra
+	ApplyMarkdowns(results map[string]entities.PriceResult) (map[string]entities.MarkdownProposal, error)
+	// ParseStockPositions lê um arquivo de estoque (produto;quantidade;data_entrada)
+	ParseStockPositions(in io.Reader) ([]entities.StockPosition, error)
+}
+
+// markdownUseCaseImpl implementa MarkdownUseCase
+type markdownUseCaseImpl struct {
+	costRepo repositories.CostRepository
+	priceUC  PriceUseCase
+	// now permite fixar a data de referência da idade do estoque
+	now func() time.Time
+}
+
+// NewMarkdownUseCase cria o motor de remarcação
+func NewMarkdownUseCase(cr repositories.CostRepository, pu PriceUseCase) MarkdownUseCase {
+	return &markdownUseCaseImpl{costRepo: cr, priceUC: pu, now: time.Now}
+}
+
+// ProposeMarkdowns aplica a regra de idade de cada posição sobre o preço alfa atual.
+// O preço proposto nunca fica abaixo do preço de equilíbrio (lucro zero).
+func (uc *markdownUseCaseImpl) ProposeMarkdowns(positions []entities.StockPosition) ([]entities.MarkdownProposal, error) {
+	if positions == nil {
+		var err error
+		positions, err = uc.costRepo.GetStockPositions(nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	regras, err := uc.rules()
+	if err != nil {
+		return nil, err
+	}
+
+	hoje := uc.now()
+	propostas := make([]entities.MarkdownProposal, 0, len(positions))
+	for _, pos := range positions {
+		p, ok := newMarkdown(pos, regras, hoje)
+		if !ok {
+			continue
+		}
+
+		result, err := uc.priceUC.CalculatePrice(entities.PriceRequest{Sku: pos.Produto})
+		if err != nil {
+			// Um SKU com problema não derruba a lista inteira
+			p.Erro = err.Error()
+			propostas = append(propostas, p)
+			continue
+		}
+		applyMarkdown(&p, result)
+		propostas = append(propostas, p)
+	}
+	return propostas, nil
+}
+
+// ApplyMarkdowns busca o estoque só dos SKUs informados e aplica a regra de idade sobre o
+// preço recebido, com o mesmo limite de equilíbrio de ProposeMarkdowns
+func (uc *markdownUseCaseImpl) ApplyMarkdowns(results map[string]entities.PriceResult) (map[string]entities.MarkdownProposal, error) {
+	remarcados := map[string]entities.MarkdownProposal{}
+	if len(results) == 0 {
+		return remarcados, nil
+	}
+	skus := make([]string, 0, len(results))
+	for sku := range results {
+		skus = append(skus, sku)
+	}
+	positions, err := uc.costRepo.GetStockPositions(skus)
+	if err != nil {
+		return nil, err
+	}
+	regras, err := uc.rules()
+	if err != nil {
+		return nil, err
+	}
+
+	hoje := uc.now()
+	for _, pos := range positions {
+		result, ok := results[pos.Produto]
+		if !ok {
+			continue
+		}
+		p, ok := newMarkdown(pos, regras, hoje)
+		if !ok {
+			continue
+		}
+		applyMarkdown(&p, result)
+		remarcados[pos.Produto] = p
+	}
+	return remarcados, nil
+}
+
+// rules devolve as regras cadastradas ou, sem cadastro, as regras padrão
+func (uc *markdownUseCaseImpl) rules() ([]entities.MarkdownRule, error) {
+	regras, err := uc.costRepo.GetMarkdownRules()
+	if err != nil {
+		return nil, err
+	}
+	if len(regras) == 0 {
+		regras = entities.MarkdownRulesPadrao()
+	}
+	return regras, nil
+}
+
+// newMarkdown monta a linha da posição; false quando não há estoque ou regra aplicável
+func newMarkdown(pos entities.StockPosition, regras []entities.MarkdownRule, hoje time.Time) (entities.MarkdownProposal, bool) {
+	if pos.Quantidade <= 0 || pos.DataEntrada.IsZero() {
+		return entities.MarkdownProposal{}, false
+	}
+	dias := int(hoje.Sub(pos.DataEntrada).Hours() / 24)
+	regra, ok := entities.SelecionarMarkdown(regras, dias)
+	if !ok {
+		return entities.MarkdownProposal{}, false
+	}
+	return entities.MarkdownProposal{
+		Sku:           pos.Produto,
+		Quantidade:    pos.Quantidade,
+		DataEntrada:   pos.DataEntrada,
+		DiasEstoque:   dias,
+		DescontoRegra: regra.Desconto,
+	}, true
+}
+
+// applyMarkdown aplica o desconto da regra ao preço calculado, limitado ao equilíbrio
+func applyMarkdown(p *entities.MarkdownProposal, result entities.PriceResult) {
+	p.PrecoAtual = result.ValorFinal
+	p.PrecoEquilibrio = round2(result.PrecoEquilibrio)
+	p.PrecoProposto = round2(result.ValorFinal * (1 - p.DescontoRegra))
+	if p.PrecoProposto < result.PrecoEquilibrio {
+		// Arredonda para cima para não cruzar o equilíbrio pelos centavos
+		p.PrecoProposto = math.Ceil(result.PrecoEquilibrio*100) / 100
+		if p.PrecoProposto > p.PrecoAtual {
+			p.PrecoProposto = p.PrecoAtual
+		}
+		p.LimitadoEquilibrio = true
+	}
+	if p.PrecoAtual > 0 {
+		p.DescontoAplicado = 1 - p.PrecoProposto/p.PrecoAtual
+	}
+
+	p.MargemAtual = result.MargemAoPreco(p.PrecoAtual)
+	p.MargemProposta = result.MargemAoPreco(p.PrecoProposto)
+	p.ImpactoMargemUnidade = round2(p.MargemProposta*p.PrecoProposto - p.MargemAtual*p.PrecoAtual)
+	p.ImpactoMargemTotal = round2(p.ImpactoMargemUnidade * p.Quantidade)
+}
+
+// ParseStockPositions lê o estoque informado em CSV com cabeçalho
+func (uc *markdownUseCaseImpl) ParseStockPositions(in io.Reader) ([]entities.StockPosition, error) {
+	positions := []entities.StockPosition{}
+	err := csvutil.Parse(in, "estoque", func(row *csvutil.Row) error {
+		p := entities.StockPosition{
+			Produto:     row.Str("produto"),
+			Quantidade:  row.Num("quantidade"),
+			DataEntrada: row.Date("data_entrada"),
+		}
+		if err := row.Err(); err != nil {
+			return err
+		}
+		if p.Produto == "" || p.DataEntrada.IsZero() {
+			return fmt.Errorf("linha %d: produto e data_entrada são obrigatórios", row.Line)
+		}
+		positions = append(positions, p)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+	}
+	return positions, nil
+}
@@ -0,0 +1,160 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+
+	"calculator/domain/entities"
+	"calculator/domain/repositories"
+	"calculator/internal/nfe"
+
+	"github.com/sirupsen/logrus"
+)
+
+// NfeImportUseCase define a importação de NF-e de compra para atualizar o custo médio
+type NfeImportUseCase interface {
+	ImportNfe(xmlData []byte) (entities.NfeImportResult, error)
+}
+
+// nfeImportUseCaseImpl implementa NfeImportUseCase
+type nfeImportUseCaseImpl struct {
+	costRepo repositories.CostRepository
+	cmpUC    CmpUseCase
+	priceUC  PriceUseCase
+}
+
+// NewNfeImportUseCase cria o caso de uso de importação de NF-e
+func NewNfeImportUseCase(cr repositories.CostRepository, cmpUC CmpUseCase, priceUC PriceUseCase) NfeImportUseCase {
+	return &nfeImportUseCaseImpl{
+		costRepo: cr,
+		cmpUC:    cmpUC,
+		priceUC:  priceUC,
+	}
+}
+
+// ImportNfe lê a NF-e, vincula os itens aos produtos, grava um novo índice de custo médio
+// por produto e recalcula o preço dos SKUs afetados
+func (uc *nfeImportUseCaseImpl) ImportNfe(xmlData []byte) (entities.NfeImportResult, error) {
+	nota, err := nfe.Parse(xmlData)
+	if err != nil {
+		return entities.NfeImportResult{}, fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+	}
+
+	imported, err := uc.costRepo.PurchaseImported(nota.Chave)
+	if err != nil {
+		return entities.NfeImportResult{}, err
+	}
+	if imported {
+		return entities.NfeImportResult{}, fmt.Errorf("NF-e %s: %w", nota.Chave, repositories.ErrAlreadyExists)
+	}
+
+	result := entities.NfeImportResult{
+		Chave:      nota.Chave,
+		Numero:     nota.Numero,
+		Fornecedor: nota.EmitenteNome,
+	}
+
+	var entries []entities.PurchaseEntry
+	for _, item := range nota.Itens {
+		// Devolução, transferência, bonificação e remessas não formam custo
+		if !item.Compra() {
+			result.IgnoradosCfop = append(result.IgnoradosCfop, entities.NfeUnmatchedItem{
+				NumeroItem:       item.NumeroItem,
+				CodigoFornecedor: item.CodigoFornecedor,
+				EAN:              item.EAN,
+				Descricao:        item.Descricao,
+				Cfop:             item.CFOP,
+			})
+			continue
+		}
+
+		// Sem quantidade o item não pondera o custo e geraria um índice repetido
+		if item.Quantidade <= 0 {
+			result.IgnoradosQuantidade = append(result.IgnoradosQuantidade, entities.NfeUnmatchedItem{
+				NumeroItem:       item.NumeroItem,
+				CodigoFornecedor: item.CodigoFornecedor,
+				EAN:              item.EAN,
+				Descricao:        item.Descricao,
+				Cfop:             item.CFOP,
+			})
+			continue
+		}
+
+		produto, err := uc.costRepo.FindProductByCodes(item.EAN, nota.EmitenteCNPJ, item.CodigoFornecedor)
+		if errors.Is(err, repositories.ErrNotFound) {
+			result.NaoLocalizados = append(result.NaoLocalizados, entities.NfeUnmatchedItem{
+				NumeroItem:       item.NumeroItem,
+				CodigoFornecedor: item.CodigoFornecedor,
+				EAN:              item.EAN,
+				Descricao:        item.Descricao,
+			})
+			continue
+		}
+		if err != nil {
+			return entities.NfeImportResult{}, err
+		}
+
+		entries = append(entries, entities.PurchaseEntry{
+			ChaveNfe:         nota.Chave,
+			NumeroItem:       item.NumeroItem,
+			Produto:          produto,
+			Fornecedor:       nota.EmitenteCNPJ,
+			DataEmissao:      nota.DataEmissao,
+			Quantidade:       item.Quantidade,
+			CustoNF:          item.CustoNF(),
+			CreditoIcms:      item.Icms,
+			CreditoPisCofins: item.Pis + item.Cofins,
+			Ipi:              item.Ipi,
+		})
+	}
+
+	// Vários itens do mesmo produto geram um único índice novo, calculado sobre o índice
+	// vigente lido na transação que grava a importação
+	var novos []entities.CmpIndex
+	if len(entries) > 0 {
+		novos, err = uc.costRepo.SavePurchaseImport(entries, func(atuais map[string]entities.CmpIndex) ([]entities.CmpIndex, error) {
+			n, err := uc.cmpUC.ComputeIndices(entries, atuais)
+			if err != nil {
+				return nil, fmt.Errorf("erro ao calcular custo médio: %w", err)
+			}
+			return n, nil
+		})
+		if err != nil {
+			return entities.NfeImportResult{}, fmt.Errorf("erro ao gravar importação da NF-e: %w", err)
+		}
+	}
+	indices := map[string]entities.CmpIndex{}
+	for _, c := range novos {
+		indices[c.Produto] = c
+	}
+	for _, e := range entries {
+		result.Importados = append(result.Importados, entities.NfeImportedItem{
+			NumeroItem: e.NumeroItem,
+			Sku:        e.Produto,
+			Entrada:    e,
+			NovoIndice: indices[e.Produto],
+		})
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"chave":           nota.Chave,
+		"importados":      len(result.Importados),
+		"nao_localizados": len(result.NaoLocalizados),
+		"ignorados_cfop":  len(result.IgnoradosCfop),
+		"ignorados_qtd":   len(result.IgnoradosQuantidade),
+	}).Info("NF-e de compra importada")
+
+	// Recalcula o preço dos SKUs com custo novo
+	for _, c := range novos {
+		repriced := entities.RepricedSku{Sku: c.Produto}
+		price, err := uc.priceUC.CalculatePrice(entities.PriceRequest{Sku: c.Produto})
+		if err != nil {
+			repriced.Erro = err.Error()
+		} else {
+			repriced.Preco = price.ValorFinal
+		}
+		result.Precos = append(result.Precos, repriced)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,111 @@
+package usecase
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"calculator/domain/entities"
+	"calculator/domain/repositories"
+)
+
+// PriceDiffUseCase compara os preços do ERP com os preços calculados
+type PriceDiffUseCase interface {
+	DiffPrices(filter entities.PriceDiffFilter) ([]entities.PriceDiff, error)
+	// StartDiff agenda o relatório como job; a tabela inteira não cabe em uma requisição
+	StartDiff(usuario string, filter entities.PriceDiffFilter) (entities.PriceJob, error)
+	// GetDiff devolve o job e o relatório, nil enquanto não concluído
+	GetDiff(id string) (entities.PriceJob, []entities.PriceDiff, error)
+}
+
+// priceDiffUseCaseImpl implementa PriceDiffUseCase
+type priceDiffUseCaseImpl struct {
+	erpPriceRepo repositories.ErpPriceRepository
+	priceUC      PriceUseCase
+	jobUC        JobUseCase
+}
+
+// NewPriceDiffUseCase cria o relatório de diferenças contra o ERP e o registra nos jobs
+func NewPriceDiffUseCase(er repositories.ErpPriceRepository, pu PriceUseCase, ju JobUseCase) PriceDiffUseCase {
+	uc := &priceDiffUseCaseImpl{erpPriceRepo: er, priceUC: pu, jobUC: ju}
+	ju.RegisterReport(entities.JobDiferencaErp, uc.runReport)
+	return uc
+}
+
+// StartDiff valida o filtro e grava o job
+func (uc *priceDiffUseCaseImpl) StartDiff(usuario string, filter entities.PriceDiffFilter) (entities.PriceJob, error) {
+	if filter.Limite < 0 {
+		return entities.PriceJob{}, fmt.Errorf("%w: limite não pode ser negativo", ErrInvalidRequest)
+	}
+	if filter.Tabela == "" {
+		filter.Tabela = entities.TabelaPrecoPadrao
+	}
+	return uc.jobUC.CreateReportJob(usuario, entities.JobDiferencaErp, filter)
+}
+
+// GetDiff lê o resultado gravado pelo job
+func (uc *priceDiffUseCaseImpl) GetDiff(id string) (entities.PriceJob, []entities.PriceDiff, error) {
+	job, corpo, err := uc.jobUC.GetReport(id, entities.JobDiferencaErp)
+	if err != nil || corpo == nil {
+		return job, nil, err
+	}
+	var relatorio []entities.PriceDiff
+	if err := json.Unmarshal(corpo, &relatorio); err != nil {
+		return job, nil, fmt.Errorf("GetDiff unmarshal: %w", err)
+	}
+	return job, relatorio, nil
+}
+
+// runReport executa o relatório do job
+func (uc *priceDiffUseCaseImpl) runReport(filtro json.RawMessage) (interface{}, error) {
+	var filter entities.PriceDiffFilter
+	if err := json.Unmarshal(filtro, &filter); err != nil {
+		return nil, fmt.Errorf("filtro inválido: %w", err)
+	}
+	return uc.DiffPrices(filter)
+}
+
+// DiffPrices calcula o preço proposto de cada item da tabela do ERP e mantém os
+// itens cuja variação atinge o limite. Itens com erro de cálculo sempre aparecem.
+func (uc *priceDiffUseCaseImpl) DiffPrices(filter entities.PriceDiffFilter) ([]entities.PriceDiff, error) {
+	if filter.Tabela == "" {
+		filter.Tabela = entities.TabelaPrecoPadrao
+	}
+
+	atuais, err := uc.erpPriceRepo.GetErpPrices(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	relatorio := make([]entities.PriceDiff, 0, len(atuais))
+	for _, atual := range atuais {
+		d := entities.PriceDiff{
+			Sku:          atual.Sku,
+			Departamento: atual.Departamento,
+			Tabela:       atual.Tabela,
+			PrecoAtual:   atual.Preco,
+		}
+
+		result, err := uc.priceUC.CalculatePrice(entities.PriceRequest{Sku: atual.Sku})
+		if err == nil {
+			err = requireFresh(result)
+		}
+		if err != nil {
+			d.Erro = err.Error()
+			relatorio = append(relatorio, d)
+			continue
+		}
+		d.PrecoProposto = result.ValorFinal
+		d.MargemAtual = result.MargemAoPreco(atual.Preco)
+		d.MargemProposta = result.MargemAoPreco(result.ValorFinal)
+		if atual.Preco > 0 {
+			d.Variacao = result.ValorFinal/atual.Preco - 1
+		}
+
+		if atual.Preco > 0 && math.Abs(d.Variacao) < filter.Limite {
+			continue
+		}
+		relatorio = append(relatorio, d)
+	}
+	return relatorio, nil
+}
@@ -2,8 +2,11 @@ package usecase
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"calculator/domain/entities"
@@ -15,30 +18,67 @@ import (
 type PriceUseCase interface {
 	CalculateAlphaPrice(sku string) (float64, string, error)
 	CalculateAlphaPriceWithUserPrice(sku string, userPrice float64) (float64, string, error)
+	CalculatePrice(req entities.PriceRequest) (entities.PriceResult, error)
+	GeneratePriceList(skus []string, tipoCliente string) ([]entities.PriceListItem, error)
+	// GetParameters devolve os parâmetros atuais do config_params
+	GetParameters() (entities.Parameters, error)
 }
 
 // priceUseCaseImpl implementa PriceUseCase
 type priceUseCaseImpl struct {
 	productRepo    repositories.ProductRepository
 	productService *firebird.ProductService
+	freightUC      FreightUseCase
+	// snapshotUC é nil quando o modo snapshot está desativado
+	snapshotUC SnapshotUseCase
+	// firebirdCache é nil quando o cálculo não usa o cache com o Firebird fora do ar
+	firebirdCache FirebirdCacheUseCase
+	// ufOrigem é a UF do estabelecimento que emite a venda
+	ufOrigem string
 }
 
-// NewPriceUseCase "injeta" o repositório para o caso de uso
-func NewPriceUseCase(pr repositories.ProductRepository, ps *firebird.ProductService) PriceUseCase {
+// NewPriceUseCase "injeta" o repositório para o caso de uso; com o modo snapshot
+// ativo os dados vêm da memória e os bancos ficam para o que não estiver na carga.
+// Com o Firebird fora do ar, CostFire e perfil fiscal vêm do cache (fc), se informado.
+// ufOrigem separa a venda interna da interestadual no ICMS por destino.
+func NewPriceUseCase(pr repositories.ProductRepository, ps *firebird.ProductService, fu FreightUseCase, su SnapshotUseCase, fc FirebirdCacheUseCase, ufOrigem string) PriceUseCase {
 	return &priceUseCaseImpl{
 		productRepo:    pr,
 		productService: ps,
+		freightUC:      fu,
+		snapshotUC:     su,
+		firebirdCache:  fc,
+		ufOrigem:       ufOrigem,
 	}
 }
 
+// priceSource são as fontes de dados de um cálculo: o snapshot em uso ou os bancos
+type priceSource struct {
+	repo   repositories.ProductRepository
+	perfil func(produto int) (entities.PerfilFiscal, error)
+	// snapshot é nil quando os dados vêm dos bancos
+	snapshot *entities.SnapshotInfo
+}
+
+// source fixa a fonte no início do cálculo para não misturar duas cargas do snapshot
+func (uc *priceUseCaseImpl) source() priceSource {
+	if uc.snapshotUC != nil {
+		if snap, ok := uc.snapshotUC.Current(); ok {
+			info := snap.Info().ComIdade(time.Now())
+			return priceSource{repo: snap, perfil: snap.GetPerfilFiscal, snapshot: &info}
+		}
+	}
+	return priceSource{repo: uc.productRepo, perfil: uc.productService.GetPerfilFiscal}
+}
+
 func (uc *priceUseCaseImpl) CalculateAlphaPrice(sku string) (float64, string, error) {
 	return uc.CalculateAlphaPriceWithUserPrice(sku, 0)
 }
 
  
-func SimulateProfit(precoDigitado, custoMedio, custoMedioNF, frete, rebate, res1, i9, operacao, comissao, fcp float64) (float64, error) {
-	// L1 = custoMedio + (CustoMedioNF * 0.01)
-	L1 := custoMedio + (custoMedioNF * 0.01)
+func SimulateProfit(precoDigitado, custoMedio, custosUnitarios, frete, rebate, res1, i9, operacao, comissao, fcp, percentuaisVenda float64) (float64, error) {
+	// L1 = custoMedio + custosUnitarios (provisão, embalagem, fulfillment)
+	L1 := custoMedio + custosUnitarios
 
 	// L2 = frete - (frete * rebate)
 	L2 := frete - (frete * rebate)
@@ -46,8 +86,8 @@ func SimulateProfit(precoDigitado, custoMedio, custoMedioNF, frete, rebate, res1
 	// L3 = L1 + L2
 	L3 := L1 + L2
 
-	// L4 = preco_digitado * (res1 + i9 + operacao + comissao + fcp)
-	L4 := precoDigitado * (res1 + i9 + operacao + comissao + fcp)
+	// L4 = preco_digitado * (res1 + i9 + operacao + comissao + fcp + percentuaisVenda)
+	L4 := precoDigitado * (res1 + i9 + operacao + comissao + fcp + percentuaisVenda)
 
 	// L5 = L4 + L3
 	L5 := L4 + L3
@@ -68,59 +108,581 @@ func SimulateProfit(precoDigitado, custoMedio, custoMedioNF, frete, rebate, res1
 }
 
 
+// priceData reúne os dados buscados nos bancos para calcular o preço de um SKU
+type priceData struct {
+	input  entities.PriceInput
+	params entities.Parameters
+	cost   entities.CostFire
+	icms   entities.IcmsVenda
+	// inputMedio guarda o custo médio quando a base de custo escolhida é outra
+	inputMedio entities.PriceInput
+	custoBase  entities.CustoBase
+	// dims é nil quando o produto não tem peso e medidas cadastrados
+	dims *entities.ProdutoDimensoes
+	// rebate é nil quando nenhum acordo se aplica e vale o rebate global
+	rebate *entities.RebateAgreement
+	// perfis de pagamento do canal (à vista e parcelado), nil quando não configurados
+	pagamentoAVista    *entities.PaymentProfile
+	pagamentoParcelado *entities.PaymentProfile
+	// snapshot é a carga usada, nil quando os dados vieram dos bancos
+	snapshot *entities.SnapshotInfo
+	// desatualizado é nil quando nada veio do cache do Firebird
+	desatualizado *entities.DadosDesatualizados
+	// regraST é nil quando o produto não tem substituição tributária para a UF
+	regraST *entities.RegraST
+}
+
 // CalculateAlphaPrice é o método que orquestra a busca de dados e executa a fórmula de cálculo
 func (uc *priceUseCaseImpl) CalculateAlphaPriceWithUserPrice(sku string, userPrice float64) (float64, string, error) {
-	 
+	result, err := uc.CalculatePrice(entities.PriceRequest{Sku: sku, UserPrice: userPrice})
+	if err != nil {
+		return 0, "", err
+	}
+
+	// Serializar o mapa em JSON
+	calculationDetailsJSON, err := json.MarshalIndent(result.Detalhes, "", "  ")
+	if err != nil {
+		return 0, "", fmt.Errorf("erro ao serializar detalhes do cálculo em JSON: %w", err)
+	}
+
+	// Retornar o valor final e o JSON como string
+	return result.ValorFinal, string(calculationDetailsJSON), nil
+}
+
+// CalculatePrice calcula o preço considerando tipo de cliente e faixa de quantidade
+func (uc *priceUseCaseImpl) CalculatePrice(req entities.PriceRequest) (entities.PriceResult, error) {
+	req = normalizeRequest(req)
+	src := uc.source()
+
+	data, err := uc.loadPriceData(src, req)
+	if err != nil {
+		return entities.PriceResult{}, err
+	}
+
+	faixas, err := uc.faixasVolume(src, req.TipoCliente, data.params.LucroPadraoDesejado)
+	if err != nil {
+		return entities.PriceResult{}, err
+	}
+	faixa, _ := entities.SelecionarFaixa(faixas, req.Quantidade)
+
+	result, err := uc.priceFromData(data, req, faixa)
+	if err != nil {
+		return entities.PriceResult{}, err
+	}
+
+	// Logar o rastro gerado
+	logrus.WithField("calculation_details", result.Detalhes).Info("Detalhes completos do cálculo gerados")
+
+	return result, nil
+}
+
+// GetParameters lê os parâmetros da mesma fonte do cálculo
+func (uc *priceUseCaseImpl) GetParameters() (entities.Parameters, error) {
+	return uc.source().repo.GetParameters()
+}
+
+// GeneratePriceList gera a lista de preços B2B de um segmento com todas as faixas de quantidade
+func (uc *priceUseCaseImpl) GeneratePriceList(skus []string, tipoCliente string) ([]entities.PriceListItem, error) {
+	if tipoCliente == "" {
+		tipoCliente = entities.ClienteContribuinte
+	}
+
+	src := uc.source()
+	lista := make([]entities.PriceListItem, 0, len(skus))
+	for _, sku := range skus {
+		item := entities.PriceListItem{Sku: sku, TipoCliente: tipoCliente}
+		if src.snapshot != nil {
+			item.SnapshotIdadeSegundos = src.snapshot.IdadeSegundos
+		}
+
+		data, err := uc.loadPriceData(src, entities.PriceRequest{Sku: sku})
+		if err != nil {
+			// Um SKU com problema não derruba a lista inteira
+			item.Erro = err.Error()
+			lista = append(lista, item)
+			continue
+		}
+		item.Desatualizado = data.desatualizado
+
+		faixas, err := uc.faixasVolume(src, tipoCliente, data.params.LucroPadraoDesejado)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, faixa := range faixas {
+			req := entities.PriceRequest{Sku: sku, TipoCliente: tipoCliente, Quantidade: faixa.QuantidadeMinima}
+			result, err := uc.priceFromData(data, req, faixa)
+			if err != nil {
+				item.Erro = err.Error()
+				break
+			}
+			item.Faixas = append(item.Faixas, entities.PriceListFaixa{
+				QuantidadeMinima: faixa.QuantidadeMinima,
+				LucroDesejado:    faixa.LucroDesejado,
+				Preco:            result.ValorFinal,
+			})
+		}
+		lista = append(lista, item)
+	}
+	return lista, nil
+}
+
+// dataCusto descreve no rastro a data do custo médio usado
+func dataCusto(data time.Time) string {
+	if data.IsZero() {
+		return "atual"
+	}
+	return data.Format("2006-01-02")
+}
+
+// origemParametros descreve no rastro de onde vieram os parâmetros
+func origemParametros(req entities.PriceRequest) string {
+	if req.Parametros != nil {
+		return "informados"
+	}
+	return "config_params"
+}
+
+// normalizeRequest aplica os valores padrão da requisição
+func normalizeRequest(req entities.PriceRequest) entities.PriceRequest {
+	if req.TipoCliente == "" {
+		req.TipoCliente = entities.ClienteConsumidorFinal
+	}
+	if req.Quantidade <= 0 {
+		req.Quantidade = 1
+	}
+	req.UF = strings.ToUpper(strings.TrimSpace(req.UF))
+	return req
+}
+
+// faixasVolume busca as faixas cadastradas do segmento ou usa as faixas padrão
+func (uc *priceUseCaseImpl) faixasVolume(src priceSource, tipoCliente string, lucroPadrao float64) ([]entities.FaixaVolume, error) {
+	faixas, err := src.repo.GetFaixasVolume(tipoCliente)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao GetFaixasVolume: %w", err)
+	}
+	if len(faixas) == 0 {
+		faixas = entities.FaixasVolumePadrao(tipoCliente, lucroPadrao)
+	}
+	return faixas, nil
+}
+
+// loadPriceData busca na fonte (bancos ou snapshot) todos os dados usados pelo cálculo
+func (uc *priceUseCaseImpl) loadPriceData(src priceSource, req entities.PriceRequest) (priceData, error) {
+	sku := req.Sku
+
 	// Converter SKU para int
 	produto, err := strconv.Atoi(sku)
 	if err != nil {
-		return 0, "", fmt.Errorf("erro ao converter SKU para int: %w", err)
+		return priceData{}, fmt.Errorf("erro ao converter SKU para int: %w", err)
 	}
 
 	// 1. Buscar do repositório: dados do productscmp → retorna PriceInput (parcial)
-	priceInp, err := uc.productRepo.GetProductCmpValues(sku)
+	var priceInp entities.PriceInput
+	if req.DataCusto.IsZero() {
+		priceInp, err = src.repo.GetProductCmpValues(sku)
+	} else {
+		priceInp, err = src.repo.GetProductCmpValuesAt(sku, req.DataCusto)
+	}
+	if err != nil {
+		return priceData{}, fmt.Errorf("erro ao GetProductCmpValues: %w", err)
+	}
+
+	// 2. Buscar parâmetros padrão, salvo quando a requisição traz os seus
+	var params entities.Parameters
+	if req.Parametros != nil {
+		params = *req.Parametros
+	} else {
+		params, err = src.repo.GetParameters()
+		if err != nil {
+			return priceData{}, fmt.Errorf("erro ao GetParameters: %w", err)
+		}
+	}
+
+	// 3. Buscar CostFire (comissão, frete, departamento) no Firebird; fora do ar, no cache
+	var desatualizado *entities.DadosDesatualizados
+	costF, err := src.repo.GetCostFire(sku)
+	if errors.Is(err, repositories.ErrUnavailable) && uc.firebirdCache != nil {
+		var em time.Time
+		if costF, em, err = uc.firebirdCache.CostFire(sku); err == nil {
+			desatualizado = entities.AdicionarDesatualizado(desatualizado, entities.DadoCustoFire, em, time.Now())
+		}
+	}
+	if err != nil {
+		return priceData{}, fmt.Errorf("erro ao GetCostFire: %w", err)
+	}
+
+	// 4. Definir a base de custo (requisição, departamento ou custo médio)
+	custoBase, baseInp, err := uc.resolveCostBasis(src, req, priceInp, costF)
+	if err != nil {
+		return priceData{}, err
+	}
+	priceMedio := priceInp
+	priceInp = baseInp
+
+	// 5. Buscar peso e medidas para o frete por transportadora
+	var dims *entities.ProdutoDimensoes
+	d, err := src.repo.GetProductDimensions(sku)
+	if err == nil {
+		dims = &d
+	} else if !errors.Is(err, repositories.ErrNotFound) {
+		return priceData{}, fmt.Errorf("erro ao GetProductDimensions: %w", err)
+	}
+
+	// 6. Buscar o acordo de rebate do fornecedor/marca vigente
+	var rebate *entities.RebateAgreement
+	dataRebate := req.DataCusto
+	if dataRebate.IsZero() {
+		dataRebate = time.Now()
+	}
+	acordos, err := src.repo.GetRebateAgreements(sku, dataRebate)
+	if err != nil {
+		return priceData{}, fmt.Errorf("erro ao GetRebateAgreements: %w", err)
+	}
+	if acordo, ok := entities.SelecionarAcordoRebate(acordos); ok {
+		rebate = &acordo
+	}
+
+	// 7. Buscar os custos de meios de pagamento do canal
+	perfis, err := src.repo.GetPaymentProfiles(req.Canal)
 	if err != nil {
-		return 0, "", fmt.Errorf("erro ao GetProductCmpValues: %w", err)
+		return priceData{}, fmt.Errorf("erro ao GetPaymentProfiles: %w", err)
 	}
+	aVista, parcelado := entities.SelecionarPagamentos(perfis)
 
-	// 2. Buscar parâmetros padrão
-	params, err := uc.productRepo.GetParameters()
+	// 8. Buscar os componentes de custo do departamento/canal
+	componentes, ok, err := src.repo.GetCostComponents(costF.Departamento, req.Canal)
 	if err != nil {
-		return 0, "", fmt.Errorf("erro ao GetParameters: %w", err)
+		return priceData{}, fmt.Errorf("erro ao GetCostComponents: %w", err)
+	}
+	if !ok {
+		componentes = entities.ComponentesPadrao()
 	}
 
-	// 3. Buscar CostFire (comissão, frete, departamento) no Firebird
-	costF, err := uc.productRepo.GetCostFire(sku)
+	// 9. Buscar a FCI do produto (conteúdo de importação), quando houver
+	fci, err := src.repo.GetFci(sku)
 	if err != nil {
-		return 0, "", fmt.Errorf("erro ao GetCostFire: %w", err)
+		return priceData{}, fmt.Errorf("erro ao GetFci: %w", err)
 	}
 
-	// 4. Consultar o ICMS Efetivo e Difal usando o ProductService
-	icmsVenda, difal, err := uc.productService.CalculateIcmsEfetivoEDifal(produto)
+	// 10. Consultar o perfil fiscal (fora do ar, no cache) e calcular o ICMS Efetivo e Difal
+	perfil, err := src.perfil(produto)
+	if errors.Is(err, repositories.ErrUnavailable) && uc.firebirdCache != nil {
+		var em time.Time
+		if perfil, em, err = uc.firebirdCache.PerfilFiscal(produto); err == nil {
+			desatualizado = entities.AdicionarDesatualizado(desatualizado, entities.DadoPerfilFiscal, em, time.Now())
+		}
+	}
 	if err != nil {
-		return 0, "", fmt.Errorf("erro ao consultar ICMS Efetivo e Difal: %w", err)
+		return priceData{}, fmt.Errorf("erro ao consultar ICMS Efetivo e Difal: %w", err)
+	}
+	icmsVenda, err := firebird.CalcularIcmsVenda(perfil, fci)
+	if err != nil {
+		return priceData{}, fmt.Errorf("erro ao consultar ICMS Efetivo e Difal: %w", err)
+	}
+
+	// 11. Ajustar ICMS, Difal e FCP à UF de destino; sem alíquotas cadastradas vale a origem
+	if req.UF != "" {
+		aliquotas, err := src.repo.GetAliquotaUF(req.UF)
+		if err == nil {
+			icmsVenda = icmsVenda.ParaDestino(uc.ufOrigem, aliquotas)
+		} else if !errors.Is(err, repositories.ErrNotFound) {
+			return priceData{}, fmt.Errorf("erro ao GetAliquotaUF: %w", err)
+		}
+	}
+
+	// 12. Buscar a substituição tributária do produto para a UF (venda a contribuinte)
+	var regraST *entities.RegraST
+	if req.UF != "" && req.TipoCliente == entities.ClienteContribuinte {
+		st, err := src.repo.GetRegraST(sku, req.UF)
+		if err == nil {
+			regraST = &st
+		} else if !errors.Is(err, repositories.ErrNotFound) {
+			return priceData{}, fmt.Errorf("erro ao GetRegraST: %w", err)
+		}
 	}
 
 	// Ajustar os valores no PriceInput
-	priceInp.IcmsEfetivo = icmsVenda
-	priceInp.Difal = difal
+	priceInp.IcmsEfetivo = icmsVenda.IcmsEfetivo
+	priceInp.Difal = icmsVenda.Difal
+	priceMedio.IcmsEfetivo = icmsVenda.IcmsEfetivo
+	priceMedio.Difal = icmsVenda.Difal
+	priceInp.Componentes = componentes
+	priceMedio.Componentes = componentes
 
 	logrus.WithFields(logrus.Fields{
 		"IcmsEfetivo": priceInp.IcmsEfetivo,
 		"Difal":       priceInp.Difal,
 	}).Info("ICMS Efetivo e Difal ajustados")
 
+	return priceData{
+		input:      priceInp,
+		params:     params,
+		cost:       costF,
+		icms:       icmsVenda,
+		inputMedio: priceMedio,
+		custoBase:  custoBase,
+		dims:       dims,
+		rebate:     rebate,
+
+		pagamentoAVista:    aVista,
+		pagamentoParcelado: parcelado,
+		snapshot:           src.snapshot,
+		desatualizado:      desatualizado,
+		regraST:            regraST,
+	}, nil
+}
+
+// resolveCostBasis escolhe a base de custo e devolve o PriceInput correspondente.
+// Sem dados para a base do departamento, o cálculo volta ao custo médio.
+func (uc *priceUseCaseImpl) resolveCostBasis(src priceSource, req entities.PriceRequest, medio entities.PriceInput, costF entities.CostFire) (entities.CustoBase, entities.PriceInput, error) {
+	base := entities.CustoBase{Base: req.BaseCusto, Origem: "requisicao"}
+	if base.Base == "" {
+		dept, err := src.repo.GetDepartmentCostBasis(costF.Departamento)
+		if err != nil {
+			return base, medio, fmt.Errorf("erro ao GetDepartmentCostBasis: %w", err)
+		}
+		base.Base = dept
+		base.Origem = "departamento"
+	}
+	if base.Base == "" {
+		base.Base = entities.BaseCustoMedio
+		base.Origem = "padrao"
+	}
+
+	var input entities.PriceInput
+	var err error
+	switch base.Base {
+	case entities.BaseCustoMedio:
+		input = medio
+	case entities.BaseCustoUltimaCompra:
+		var entrada entities.PurchaseEntry
+		entrada, err = src.repo.GetLastPurchase(req.Sku)
+		if err == nil {
+			input = entities.EntradaPorUnidade(entrada)
+		}
+	case entities.BaseCustoTabelaFornecedor:
+		var custo float64
+		custo, err = src.repo.GetSupplierListCost(req.Sku)
+		if err == nil {
+			input = entities.AjustarCustoNF(medio, custo)
+		}
+	case entities.BaseCustoReposicao:
+		var custo float64
+		custo, err = src.repo.GetReplacementCost(req.Sku)
+		if err == nil {
+			input = entities.AjustarCustoNF(medio, custo)
+		}
+	default:
+		return base, medio, fmt.Errorf("%w: base de custo %q desconhecida", ErrInvalidRequest, base.Base)
+	}
+
+	if errors.Is(err, repositories.ErrNotFound) {
+		if base.Origem == "requisicao" {
+			return base, medio, fmt.Errorf("%w: sku %s sem dados para a base de custo %s", ErrInvalidRequest, req.Sku, base.Base)
+		}
+		base.Observacao = fmt.Sprintf("sem dados para a base %s, usado o custo médio", base.Base)
+		base.Base = entities.BaseCustoMedio
+		input = medio
+	} else if err != nil {
+		return base, medio, fmt.Errorf("erro ao buscar custo da base %s: %w", base.Base, err)
+	}
+
+	base.CustoNF = input.CustoMedioNF
+	return base, input, nil
+}
+
+// freteDetalhe descreve o frete aplicado no cálculo
+type freteDetalhe struct {
+	origem    string
+	cotacao   entities.FreteCotacao
+	valorBase float64
+	regra     string
+}
+
+// resolveFreight define o frete pago pelo vendedor. Com destino e medidas usa a tabela
+// das transportadoras; senão o frete do np_comissao_frete. A regra de frete subsidiado
+// do canal depende do preço, então o preço é calculado nos dois cenários da regra.
+func (uc *priceUseCaseImpl) resolveFreight(data priceData, req entities.PriceRequest,
+	calc func(frete float64) (float64, error)) (float64, freteDetalhe, error) {
+
+	det := freteDetalhe{origem: "np_comissao_frete", valorBase: data.cost.Frete}
+	tables := uc.freightUC.Tables()
+
+	if data.dims != nil && (req.UF != "" || req.Cep != "") {
+		if cot, ok := tables.Cotar(data.dims.Carga(1), req.UF, req.Cep); ok {
+			det.origem = "tabela_transportadora"
+			det.cotacao = cot
+			det.valorBase = cot.Valor
+		}
+	}
+
+	regra, ok := tables.Subsidios[req.Canal]
+	if req.Canal == "" || !ok {
+		return det.valorBase, det, nil
+	}
+
+	acima := regra.ValorVendedor(det.valorBase, regra.PrecoMinimo)
+	precoAcima, err := calc(acima)
+	if err != nil {
+		return 0, det, err
+	}
+	if precoAcima >= regra.PrecoMinimo {
+		det.regra = fmt.Sprintf("%s: preço a partir de %.2f, vendedor paga %.0f%% do frete", regra.Canal, regra.PrecoMinimo, regra.PercentualVendedor*100)
+		return acima, det, nil
+	}
+
+	abaixo := regra.ValorVendedor(det.valorBase, 0)
+	precoAbaixo, err := calc(abaixo)
+	if err != nil {
+		return 0, det, err
+	}
+	if precoAbaixo < regra.PrecoMinimo {
+		det.regra = fmt.Sprintf("%s: preço abaixo de %.2f, vendedor paga %.0f%% do frete", regra.Canal, regra.PrecoMinimo, regra.PercentualVendedorAbaixo*100)
+		return abaixo, det, nil
+	}
+
+	// Os dois cenários cruzam o preço mínimo: usa o frete que resulta no maior preço
+	det.regra = fmt.Sprintf("%s: preço na fronteira de %.2f, adotado o cenário de maior custo", regra.Canal, regra.PrecoMinimo)
+	if precoAbaixo > precoAcima {
+		return abaixo, det, nil
+	}
+	return acima, det, nil
+}
+
+// priceFromData aplica tipo de cliente e faixa de volume e executa o Cálculo Inicial Alpha
+func (uc *priceUseCaseImpl) priceFromData(data priceData, req entities.PriceRequest, faixa entities.FaixaVolume) (entities.PriceResult, error) {
+	priceInp := data.input
+	params := data.params
+	costF := data.cost
+
+	// Venda a contribuinte: o Difal é recolhido pelo destinatário
+	priceInp.DifalDestinatario = req.TipoCliente == entities.ClienteContribuinte
+	difalResponsavel := "vendedor"
+	if priceInp.DifalDestinatario {
+		difalResponsavel = "destinatario"
+	}
+
+	// FCP da UF de destino; na venda interestadual a contribuinte fica com o destinatário
+	if destino := data.icms.Destino; destino != nil {
+		params.Fcp = destino.Fcp
+		if !destino.Interna && priceInp.DifalDestinatario {
+			params.Fcp = 0
+		}
+	}
+
+	// O lucro desejado passa a ser o da faixa de quantidade
+	if faixa.QuantidadeMinima > 0 {
+		params.LucroPadraoDesejado = faixa.LucroDesejado
+	}
+
+	// Rebate do acordo com o fornecedor abate o custo de aquisição; o rebate global
+	// dos parâmetros continua valendo só para o frete
+	if data.rebate != nil {
+		priceInp.RebateAcordo = data.rebate.Percentual
+	}
+
+	// O preço de tabela cobre o parcelamento sem juros; o preço à vista usa o meio mais barato
+	var custoAVista float64
+	if data.pagamentoAVista != nil {
+		custoAVista = data.pagamentoAVista.Custo()
+	}
+	custoParcelado := custoAVista
+	parcelas := 1
+	if data.pagamentoParcelado != nil {
+		custoParcelado = data.pagamentoParcelado.Custo()
+		parcelas = data.pagamentoParcelado.Parcelas()
+	}
+	priceInp.CustoPagamento = custoParcelado
+
+	// Frete por peso/destino e regras de frete subsidiado do canal
+	frete, freteDet, err := uc.resolveFreight(data, req, func(frete float64) (float64, error) {
+		cf := costF
+		cf.Frete = frete
+		valor, _, err := alphaCalculation(priceInp, params, cf, req.UserPrice)
+		return valor, err
+	})
+	if err != nil {
+		return entities.PriceResult{}, fmt.Errorf("erro ao calcular frete: %w", err)
+	}
+	costF.Frete = frete
+
 	// Calcular o valor final e capturar as variáveis principais
-	valorFinal, lucroSimulado, calcErr := alphaCalculation(priceInp, params, costF, userPrice)
+	valorFinal, lucroSimulado, calcErr := alphaCalculation(priceInp, params, costF, req.UserPrice)
+	if calcErr != nil {
+		return entities.PriceResult{}, fmt.Errorf("erro alphaCalculation: %w", calcErr)
+	}
+
+	// Preço de equilíbrio: mesmos custos com lucro zero
+	equilibrioParams := params
+	equilibrioParams.LucroPadraoDesejado = 0
+	precoEquilibrio, _, calcErr := alphaCalculation(priceInp, equilibrioParams, costF, 0)
 	if calcErr != nil {
-		return 0, "", fmt.Errorf("erro alphaCalculation: %w", calcErr)
+		return entities.PriceResult{}, fmt.Errorf("erro alphaCalculation (equilíbrio): %w", calcErr)
 	}
 
-	// Montar o JSON com as variáveis principais
+	// Preço à vista com o custo do meio de pagamento à vista
+	precoAVista := valorFinal
+	if custoAVista != custoParcelado {
+		aVistaInp := priceInp
+		aVistaInp.CustoPagamento = custoAVista
+		precoAVista, _, calcErr = alphaCalculation(aVistaInp, params, costF, req.UserPrice)
+		if calcErr != nil {
+			return entities.PriceResult{}, fmt.Errorf("erro alphaCalculation (à vista): %w", calcErr)
+		}
+	}
+
+	// Com outra base de custo, mostra também o preço pelo custo médio
+	precoCustoMedio := valorFinal
+	if data.custoBase.Base != entities.BaseCustoMedio {
+		medioInp := data.inputMedio
+		medioInp.DifalDestinatario = priceInp.DifalDestinatario
+		medioInp.CustoPagamento = priceInp.CustoPagamento
+		medioInp.RebateAcordo = priceInp.RebateAcordo
+		precoCustoMedio, _, calcErr = alphaCalculation(medioInp, params, costF, req.UserPrice)
+		if calcErr != nil {
+			return entities.PriceResult{}, fmt.Errorf("erro alphaCalculation (custo médio): %w", calcErr)
+		}
+	}
+
+	// ICMS-ST da venda a contribuinte, cobrado além do preço; precisa da alíquota interna do destino
+	var icmsSt *entities.IcmsSt
+	stObservacao := ""
+	if data.regraST != nil && priceInp.DifalDestinatario {
+		if data.icms.Destino != nil {
+			st := entities.CalcularSt(valorFinal, *data.regraST, data.icms, params.Fcp)
+			icmsSt = &st
+		} else {
+			stObservacao = fmt.Sprintf("produto com ST para %s, mas a UF não tem alíquotas em icms_uf: ST não calculado", req.UF)
+		}
+	}
+
+	// Montar o rastro com as variáveis principais
 	calculationDetails := map[string]interface{}{
-		"sku":             sku,
+		"sku":             req.Sku,
 		"icms_efetivo":    priceInp.IcmsEfetivo,
 		"difal":           priceInp.Difal,
+		"origem_produto":  data.icms.Origem.Origem.Codigo,
+		"origem_descricao": data.icms.Origem.Origem.Descricao,
+		"conteudo_importacao": data.icms.Origem.ConteudoImportacao,
+		"aliquota_interestadual": data.icms.Origem.AliquotaInterestadual,
+		"origem_motivo":   data.icms.Origem.Motivo,
+		"tipo_cliente":    req.TipoCliente,
+		"difal_responsavel": difalResponsavel,
+		"quantidade":      req.Quantidade,
+		"uf_destino":      req.UF,
+		"icms_destino":    data.icms.Destino,
+		"icms_st":         icmsSt,
+		"icms_st_observacao": stObservacao,
+		"data_custo":      dataCusto(req.DataCusto),
+		"base_custo":      data.custoBase.Base,
+		"parametros":      origemParametros(req),
+		"base_custo_origem": data.custoBase.Origem,
+		"base_custo_observacao": data.custoBase.Observacao,
+		"custo_base_nf":   data.custoBase.CustoNF,
+		"preco_custo_medio": precoCustoMedio,
+		"preco_equilibrio": precoEquilibrio,
+		"faixa_quantidade_minima": faixa.QuantidadeMinima,
 		"icms_medio_calc": priceInp.IcmsMedio * 0.4,
 		"pis_cofins_calc": priceInp.PisCofinsMedio * 0.4,
 		"custo_medio_liq": priceInp.CustoMedioLiq ,
@@ -130,23 +692,69 @@ func (uc *priceUseCaseImpl) CalculateAlphaPriceWithUserPrice(sku string, userPri
 		"lucro_padrao":    params.LucroPadraoDesejado,
 		"fcp":             params.Fcp,
 		"frete":           costF.Frete,
+		"frete_origem":    freteDet.origem,
+		"frete_tabela":    freteDet.valorBase,
+		"frete_transportadora": freteDet.cotacao.Transportadora,
+		"frete_peso_taxado": freteDet.cotacao.PesoTaxado,
+		"frete_canal":     req.Canal,
+		"frete_regra_subsidio": freteDet.regra,
+		"cep_destino":     req.Cep,
+		"pagamento_a_vista": data.pagamentoAVista,
+		"pagamento_parcelado": data.pagamentoParcelado,
+		"custo_pagamento_a_vista": custoAVista,
+		"custo_pagamento_parcelado": custoParcelado,
+		"preco_a_vista":   precoAVista,
+		"preco_parcelado": valorFinal,
+		"parcelas":        parcelas,
+		"valor_parcela":   valorFinal / float64(parcelas),
 		"rebate":          params.Rebate,
+		"rebate_acordo":   data.rebate,
+		"rebate_acordo_valor": priceInp.CustoMedioNF * priceInp.RebateAcordo,
 		"custo_medio_nf":  priceInp.CustoMedioNF,
+		"componentes_escopo": priceInp.Componentes.Escopo,
+		"provisao_custo_nf": priceInp.CustoMedioNF * priceInp.Componentes.ProvisaoCustoNF,
+		"embalagem":       priceInp.Componentes.Embalagem,
+		"fulfillment":     priceInp.Componentes.Fulfillment,
+		"taxa_devolucao":  priceInp.Componentes.TaxaDevolucao,
+		"taxa_chargeback": priceInp.Componentes.TaxaChargeback,
 		"Preço Tabela U02":     valorFinal,
 		"Lucro Simulado":  lucroSimulado, 
 	}
 
-	// Serializar o mapa em JSON
-	calculationDetailsJSON, err := json.MarshalIndent(calculationDetails, "", "  ")
-	if err != nil {
-		return 0, "", fmt.Errorf("erro ao serializar detalhes do cálculo em JSON: %w", err)
+	// Com o modo snapshot, informa a idade dos dados usados
+	calculationDetails["fonte_dados"] = entities.FonteDadosBancos
+	if data.snapshot != nil {
+		calculationDetails["fonte_dados"] = entities.FonteDadosSnapshot
+		calculationDetails["snapshot_carregado_em"] = data.snapshot.CarregadoEm
+		calculationDetails["snapshot_idade_segundos"] = data.snapshot.IdadeSegundos
+	}
+	// Com o Firebird fora do ar, informa o que veio do cache e a idade da leitura
+	calculationDetails["desatualizado"] = data.desatualizado != nil
+	if data.desatualizado != nil {
+		calculationDetails["dados_desatualizados"] = data.desatualizado
 	}
 
-	// Logar o JSON gerado
-	logrus.WithField("calculation_details", string(calculationDetailsJSON)).Info("Detalhes completos do cálculo gerados")
-
-	// Retornar o valor final e o JSON como string
-	return valorFinal, string(calculationDetailsJSON), nil
+	return entities.PriceResult{
+		Sku:           req.Sku,
+		Request:       req,
+		Input:         priceInp,
+		Params:        params,
+		Cost:          costF,
+		Icms:          data.icms,
+		Faixa:         faixa,
+		ValorFinal:    valorFinal,
+		LucroSimulado: lucroSimulado,
+		PrecoAVista:   precoAVista,
+		Parcelas:      parcelas,
+		ValorParcela:  valorFinal / float64(parcelas),
+		CustoUnitarioTotal: custoUnitarioTotal(priceInp, params, costF),
+		PrecoEquilibrio:    precoEquilibrio,
+		St:                 icmsSt,
+		Dimensoes:          data.dims,
+		Snapshot:           data.snapshot,
+		Desatualizado:      data.desatualizado,
+		Detalhes:      calculationDetails,
+	}, nil
 }
 
 
@@ -169,8 +777,8 @@ func alphaCalculation(pi entities.PriceInput, pm entities.Parameters, cf entitie
 		"pisCofinsCalc":    pisCofinsCalc,
 	}).Info("Valores calculados de ICMS e Pis/Cofins")
 
-	// Cálculo do custo médio
-	custoMedio := pi.CustoMedioLiq + icmsMedioCalc + pisCofinsCalc
+	// Cálculo do custo médio, descontado o rebate do acordo sobre o custo NF
+	custoMedio := pi.CustoMedioLiq + icmsMedioCalc + pisCofinsCalc - (pi.CustoMedioNF * pi.RebateAcordo)
 	logrus.WithField("custoMedio", custoMedio).Info("Custo médio calculado")
 
 	// Cálculo de i1
@@ -192,7 +800,12 @@ func alphaCalculation(pi entities.PriceInput, pm entities.Parameters, cf entitie
 	// Cálculo de i6, i7, i8, i9
 	i6 := pi.IcmsEfetivo * 0.60
 	i7 := (pi.IcmsEfetivo - pi.Difal) * 0.60
-	i8 := i7 + pi.Difal
+	// Na venda a contribuinte o Difal não compõe o custo do vendedor
+	difalVenda := pi.Difal
+	if pi.DifalDestinatario {
+		difalVenda = 0
+	}
+	i8 := i7 + difalVenda
 	i9 := (i8 + i6) / 2
 	logrus.WithFields(logrus.Fields{
 		"i6": i6,
@@ -207,8 +820,12 @@ func alphaCalculation(pi entities.PriceInput, pm entities.Parameters, cf entitie
 		"Comissao":          comissao,
 		"LucroPadraoDesejado": pm.LucroPadraoDesejado,
 		"Fcp":               pm.Fcp,
+		"CustoPagamento":    pi.CustoPagamento,
+		"TaxaDevolucao":     pi.Componentes.TaxaDevolucao,
+		"TaxaChargeback":    pi.Componentes.TaxaChargeback,
 	}).Info("Calculando res3")
-	res3 := pm.Operacao + comissao + pm.LucroPadraoDesejado + pm.Fcp
+	percentuaisVenda := pi.CustoPagamento + pi.Componentes.PercentualVenda()
+	res3 := pm.Operacao + comissao + pm.LucroPadraoDesejado + pm.Fcp + percentuaisVenda
 	logrus.WithField("res3", res3).Info("Valor de res3 calculado")
 
 	// Cálculo do imposto
@@ -238,13 +855,27 @@ func alphaCalculation(pi entities.PriceInput, pm entities.Parameters, cf entitie
 		"message":"Iniciando agora simulador de lucro",
 	}).Info()
 
-	simulatorProfit, err:= SimulateProfit(userPrice, custoMedio, pi.CustoMedioNF, cf.Frete, pm.Rebate, res1, i9, pm.Operacao, comissao, pm.Fcp) 
+	// Provisão sobre o custo NF, embalagem e fulfillment por unidade
+	custosUnitarios := pi.Componentes.CustoUnitario(pi.CustoMedioNF)
+	logrus.WithFields(logrus.Fields{
+		"ProvisaoCustoNF": pi.CustoMedioNF * pi.Componentes.ProvisaoCustoNF,
+		"Embalagem":       pi.Componentes.Embalagem,
+		"Fulfillment":     pi.Componentes.Fulfillment,
+	}).Info("Componentes de custo por unidade")
+
+	simulatorProfit, err:= SimulateProfit(userPrice, custoMedio, custosUnitarios, cf.Frete, pm.Rebate, res1, i9, pm.Operacao, comissao, pm.Fcp, percentuaisVenda)
 	if err != nil {
 		return 0 ,0 ,fmt.Errorf("erro ao calcular o lucro simulado: %w", err)
 	}
 
-	valorFinal := (custoMedio + (pi.CustoMedioNF * 0.01) + (cf.Frete - (cf.Frete * pm.Rebate))) / imposto
+	valorFinal := custoUnitarioTotal(pi, pm, cf) / imposto
 	logrus.WithField("valorFinal", valorFinal).Info("Valor final calculado")
 
 	return valorFinal, simulatorProfit, nil
-}
\ No newline at end of file
+}
+// custoUnitarioTotal é o numerador do Cálculo Inicial Alpha: custo médio menos o rebate
+// do acordo, componentes por unidade e frete descontado o rebate global
+func custoUnitarioTotal(pi entities.PriceInput, pm entities.Parameters, cf entities.CostFire) float64 {
+	custoMedio := pi.CustoMedioLiq + (pi.IcmsMedio * 0.4) + (pi.PisCofinsMedio * 0.4) - (pi.CustoMedioNF * pi.RebateAcordo)
+	return custoMedio + pi.Componentes.CustoUnitario(pi.CustoMedioNF) + (cf.Frete - (cf.Frete * pm.Rebate))
+}
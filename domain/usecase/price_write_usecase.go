@@ -0,0 +1,384 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"calculator/domain/entities"
+	"calculator/domain/repositories"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Registro da situação depois do commit no ERP
+const (
+	tentativasStatusGravacao = 3
+	esperaStatusGravacao     = time.Second
+)
+
+// Publicação das gravações no BI (sysuni)
+const (
+	// lotePublicacao é o número de gravações reservadas por rodada
+	lotePublicacao = 5
+	// tempoPublicacao limita cada publicação no SQL Server
+	tempoPublicacao = 30 * time.Second
+	// reservaPublicacao cobre o lote inteiro no tempo limite; vencida, outra instância pode publicar
+	reservaPublicacao = lotePublicacao * tempoPublicacao * 2
+)
+
+// PriceWriteUseCase grava preços aprovados na tabela de preços do ERP
+type PriceWriteUseCase interface {
+	// WritePrices grava o lote em uma transação; em dry-run só calcula e registra o antes/depois
+	WritePrices(req entities.PriceWriteRequest) (entities.PriceWriteRun, error)
+	GetWriteRun(id string) (entities.PriceWriteRun, error)
+	// RollbackWriteRun devolve os preços anteriores de uma gravação aplicada
+	RollbackWriteRun(id, usuario string) (entities.PriceWriteRun, error)
+	// PublishWriteRun coloca de novo na fila de publicação do BI (sysuni) uma gravação aplicada ou revertida
+	PublishWriteRun(id string) (entities.PriceWriteRun, error)
+	// PublishDue publica as gravações vencidas da fila e devolve quantas foram publicadas
+	PublishDue() (int, error)
+	// StartPublisher publica periodicamente até o canal stop ser fechado
+	StartPublisher(interval time.Duration, stop <-chan struct{})
+}
+
+// priceWriteUseCaseImpl implementa PriceWriteUseCase
+type priceWriteUseCaseImpl struct {
+	erpPriceRepo   repositories.ErpPriceRepository
+	priceWriteRepo repositories.PriceWriteRepository
+	publisher      repositories.PricePublisher
+	now            func() time.Time
+	// espera é o intervalo entre as tentativas de registrar a situação
+	espera time.Duration
+}
+
+// NewPriceWriteUseCase cria o caso de uso de gravação de preços no ERP; as gravações
+// aplicadas e revertidas entram na fila de publicação do destino de BI
+func NewPriceWriteUseCase(er repositories.ErpPriceRepository, pw repositories.PriceWriteRepository, pub repositories.PricePublisher) PriceWriteUseCase {
+	return &priceWriteUseCaseImpl{erpPriceRepo: er, priceWriteRepo: pw, publisher: pub, now: time.Now, espera: esperaStatusGravacao}
+}
+
+// WritePrices lê o preço vigente de cada item, monta o antes/depois e grava tudo ou nada.
+// O log é salvo como pendente antes da gravação para não perder o antes/depois se o
+// Postgres falhar depois do commit no ERP; nesse caso o rollback reconhece a gravação
+// pendente pelos preços vigentes.
+func (uc *priceWriteUseCaseImpl) WritePrices(req entities.PriceWriteRequest) (entities.PriceWriteRun, error) {
+	if req.Usuario == "" {
+		return entities.PriceWriteRun{}, fmt.Errorf("%w: usuario é obrigatório", ErrInvalidRequest)
+	}
+	if len(req.Itens) == 0 {
+		return entities.PriceWriteRun{}, fmt.Errorf("%w: nenhum item informado", ErrInvalidRequest)
+	}
+
+	changes, inalterados, err := uc.buildChanges(req.Itens)
+	if err != nil {
+		return entities.PriceWriteRun{}, err
+	}
+
+	id, err := newID()
+	if err != nil {
+		return entities.PriceWriteRun{}, err
+	}
+	run := entities.PriceWriteRun{
+		ID:          id,
+		CriadoEm:    uc.now(),
+		Usuario:     req.Usuario,
+		DryRun:      req.DryRun,
+		Status:      entities.GravacaoPendente,
+		Alteracoes:  changes,
+		Inalterados: inalterados,
+	}
+	if req.DryRun {
+		run.Status = entities.GravacaoSimulada
+	}
+	if err := uc.priceWriteRepo.SaveWriteRun(run); err != nil {
+		return run, err
+	}
+	if req.DryRun {
+		return run, nil
+	}
+
+	if len(changes) > 0 {
+		if err := uc.erpPriceRepo.ApplyErpPrices(changes); err != nil {
+			if !errors.Is(err, repositories.ErrIndeterminate) {
+				return run, uc.recordFailure(&run, err)
+			}
+			// O commit pode ter chegado ao ERP: decide pelos preços vigentes
+			novos, anteriores, serr := uc.erpState(changes)
+			switch {
+			case serr == nil && anteriores:
+				return run, uc.recordFailure(&run, err)
+			case serr != nil || !novos:
+				logrus.WithError(err).WithField("gravacao", run.ID).
+					Error("Commit no ERP sem resposta e preços não conferem; a gravação segue pendente até o rollback")
+				return run, err
+			}
+			logrus.WithError(err).WithField("gravacao", run.ID).Warn("Commit no ERP sem resposta, mas os preços novos estão gravados")
+		}
+	}
+
+	run.Status = entities.GravacaoAplicada
+	if err := uc.recordStatus(run.ID, run.Status, ""); err != nil {
+		logrus.WithError(err).WithField("gravacao", run.ID).
+			Error("Preços gravados no ERP sem registro da situação; a gravação segue pendente até o rollback")
+		return run, err
+	}
+	return run, nil
+}
+
+// recordFailure registra a gravação que não chegou ao ERP como falha
+func (uc *priceWriteUseCaseImpl) recordFailure(run *entities.PriceWriteRun, err error) error {
+	run.Status = entities.GravacaoFalhou
+	run.Erro = err.Error()
+	if uerr := uc.priceWriteRepo.UpdateWriteRunStatus(run.ID, run.Status, run.Erro, ""); uerr != nil {
+		return fmt.Errorf("%v (e ao registrar a falha: %v)", err, uerr)
+	}
+	return err
+}
+
+// GetWriteRun busca o log de uma gravação
+func (uc *priceWriteUseCaseImpl) GetWriteRun(id string) (entities.PriceWriteRun, error) {
+	return uc.priceWriteRepo.GetWriteRun(id)
+}
+
+// RollbackWriteRun reverte todos os itens da gravação em uma transação. Se algum preço
+// foi alterado depois da gravação, nada é revertido (ErrConflict). Uma gravação pendente
+// ou com falha é aceita quando todos os preços novos estão no ERP: o commit ocorreu e só
+// a resposta ou o registro se perdeu. Já revertida no ERP sem registro, só a situação é gravada.
+func (uc *priceWriteUseCaseImpl) RollbackWriteRun(id, usuario string) (entities.PriceWriteRun, error) {
+	if usuario == "" {
+		return entities.PriceWriteRun{}, fmt.Errorf("%w: usuario é obrigatório", ErrInvalidRequest)
+	}
+	run, err := uc.priceWriteRepo.GetWriteRun(id)
+	if err != nil {
+		return run, err
+	}
+	switch run.Status {
+	case entities.GravacaoAplicada:
+	case entities.GravacaoPendente, entities.GravacaoFalhou:
+		novos, _, err := uc.erpState(run.Alteracoes)
+		if err != nil {
+			return run, err
+		}
+		if !novos {
+			return run, fmt.Errorf("%w: gravação %s %s sem os preços novos no ERP", repositories.ErrConflict, id, run.Status)
+		}
+		logrus.WithFields(logrus.Fields{"gravacao": id, "situacao": run.Status}).Warn("Gravação encontrada aplicada no ERP; revertendo")
+	default:
+		return run, fmt.Errorf("%w: gravação %s está %s e não pode ser revertida", ErrInvalidRequest, id, run.Status)
+	}
+
+	reverso := make([]entities.PriceWriteChange, len(run.Alteracoes))
+	for i, c := range run.Alteracoes {
+		c.PrecoAnterior, c.PrecoNovo = c.PrecoNovo, c.PrecoAnterior
+		reverso[i] = c
+	}
+	if len(reverso) > 0 {
+		if err := uc.erpPriceRepo.ApplyErpPrices(reverso); err != nil {
+			if !errors.Is(err, repositories.ErrConflict) {
+				return run, err
+			}
+			_, anteriores, serr := uc.erpState(run.Alteracoes)
+			if serr != nil || !anteriores {
+				return run, err
+			}
+		}
+	}
+
+	run.Status = entities.GravacaoRevertida
+	run.RevertidoPor = usuario
+	if err := uc.recordStatus(run.ID, run.Status, usuario); err != nil {
+		return run, err
+	}
+	return run, nil
+}
+
+// recordStatus registra a situação após o commit no ERP, com novas tentativas
+func (uc *priceWriteUseCaseImpl) recordStatus(id, status, revertidoPor string) error {
+	var err error
+	for tentativa := 1; tentativa <= tentativasStatusGravacao; tentativa++ {
+		if err = uc.priceWriteRepo.UpdateWriteRunStatus(id, status, "", revertidoPor); err == nil {
+			return nil
+		}
+		if tentativa < tentativasStatusGravacao {
+			time.Sleep(uc.espera * time.Duration(tentativa))
+		}
+	}
+	return err
+}
+
+// erpState compara os preços vigentes no ERP com as alterações: novos indica que todos
+// estão no preço novo e anteriores, que todos estão no preço anterior
+func (uc *priceWriteUseCaseImpl) erpState(changes []entities.PriceWriteChange) (novos, anteriores bool, err error) {
+	porTabela := map[string][]string{}
+	for _, c := range changes {
+		porTabela[c.Tabela] = append(porTabela[c.Tabela], c.Sku)
+	}
+	vigentes := map[string]float64{}
+	for tabela, skus := range porTabela {
+		atuais, err := uc.erpPriceRepo.GetErpPrices(entities.PriceDiffFilter{Tabela: tabela, Skus: skus})
+		if err != nil {
+			return false, false, err
+		}
+		for _, a := range atuais {
+			vigentes[tabela+"/"+a.Sku] = a.Preco
+		}
+	}
+
+	novos, anteriores = true, true
+	for _, c := range changes {
+		preco, ok := vigentes[c.Tabela+"/"+c.Sku]
+		novos = novos && ok && math.Abs(preco-c.PrecoNovo) < 0.005
+		anteriores = anteriores && ok && math.Abs(preco-c.PrecoAnterior) < 0.005
+	}
+	return novos, anteriores, nil
+}
+
+// PublishWriteRun publica novamente uma gravação, por exemplo após o sysuni voltar.
+// Simuladas, falhas e pendentes nunca são publicadas.
+func (uc *priceWriteUseCaseImpl) PublishWriteRun(id string) (entities.PriceWriteRun, error) {
+	run, err := uc.priceWriteRepo.GetWriteRun(id)
+	if err != nil {
+		return run, err
+	}
+	if run.Status != entities.GravacaoAplicada && run.Status != entities.GravacaoRevertida {
+		return run, fmt.Errorf("%w: gravação %s está %s e não é publicada", ErrInvalidRequest, id, run.Status)
+	}
+	return run, uc.priceWriteRepo.EnqueuePublication(id)
+}
+
+// PublishDue reserva um lote da fila e publica cada gravação com tempo limite. Falhas
+// voltam para a fila com backoff exponencial, sem limite de tentativas; a publicação é
+// um MERGE e pode se repetir.
+func (uc *priceWriteUseCaseImpl) PublishDue() (int, error) {
+	fila, err := uc.priceWriteRepo.ClaimDuePublications(lotePublicacao, reservaPublicacao)
+	if err != nil {
+		return 0, err
+	}
+
+	publicadas := 0
+	for _, p := range fila {
+		run, err := uc.priceWriteRepo.GetWriteRun(p.RunID)
+		if err == nil {
+			ctx, cancel := context.WithTimeout(context.Background(), tempoPublicacao)
+			err = uc.publisher.PublishPriceRun(ctx, run)
+			cancel()
+		}
+		if err != nil {
+			tentativas := p.Tentativas + 1
+			proxima := uc.now().Add(webhookBackoff(tentativas))
+			if uerr := uc.priceWriteRepo.MarkPublishFailed(p.RunID, tentativas, err.Error(), proxima); uerr != nil {
+				return publicadas, uerr
+			}
+			logrus.WithError(err).WithFields(logrus.Fields{"gravacao": p.RunID, "tentativas": tentativas}).
+				Warn("Falha ao publicar a gravação de preços no sysuni, nova tentativa agendada")
+			continue
+		}
+
+		if err := uc.priceWriteRepo.MarkPublished(p.RunID, uc.now()); err != nil {
+			return publicadas, err
+		}
+		publicadas++
+	}
+	return publicadas, nil
+}
+
+// StartPublisher roda PublishDue a cada intervalo, emendando rodadas enquanto houver lote cheio
+func (uc *priceWriteUseCaseImpl) StartPublisher(interval time.Duration, stop <-chan struct{}) {
+	if interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				for {
+					n, err := uc.PublishDue()
+					if err != nil {
+						logrus.WithError(err).Warn("Falha ao publicar gravações de preço")
+						break
+					}
+					if n < lotePublicacao {
+						break
+					}
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// buildChanges valida os itens e lê os preços vigentes agrupando por tabela
+func (uc *priceWriteUseCaseImpl) buildChanges(itens []entities.PriceWriteItem) ([]entities.PriceWriteChange, []string, error) {
+	porTabela := map[string]map[string]float64{}
+	for _, item := range itens {
+		if item.Sku == "" || item.Preco <= 0 {
+			return nil, nil, fmt.Errorf("%w: sku e preco positivo são obrigatórios", ErrInvalidRequest)
+		}
+		tabela := item.Tabela
+		if tabela == "" {
+			tabela = entities.TabelaPrecoPadrao
+		}
+		if porTabela[tabela] == nil {
+			porTabela[tabela] = map[string]float64{}
+		}
+		if _, dup := porTabela[tabela][item.Sku]; dup {
+			return nil, nil, fmt.Errorf("%w: sku %s repetido na tabela %s", ErrInvalidRequest, item.Sku, tabela)
+		}
+		porTabela[tabela][item.Sku] = round2(item.Preco)
+	}
+
+	tabelas := make([]string, 0, len(porTabela))
+	for t := range porTabela {
+		tabelas = append(tabelas, t)
+	}
+	sort.Strings(tabelas)
+
+	var changes []entities.PriceWriteChange
+	var inalterados, ausentes []string
+	for _, tabela := range tabelas {
+		novos := porTabela[tabela]
+		skus := make([]string, 0, len(novos))
+		for sku := range novos {
+			skus = append(skus, sku)
+		}
+		atuais, err := uc.erpPriceRepo.GetErpPrices(entities.PriceDiffFilter{Tabela: tabela, Skus: skus})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		encontrados := map[string]bool{}
+		for _, a := range atuais {
+			encontrados[a.Sku] = true
+			novo := novos[a.Sku]
+			if math.Abs(novo-a.Preco) < 0.005 {
+				inalterados = append(inalterados, a.Sku)
+				continue
+			}
+			changes = append(changes, entities.PriceWriteChange{
+				Sku:           a.Sku,
+				Produto:       a.Produto,
+				Tabela:        tabela,
+				PrecoAnterior: a.Preco,
+				PrecoNovo:     novo,
+			})
+		}
+		for _, sku := range skus {
+			if !encontrados[sku] {
+				ausentes = append(ausentes, tabela+"/"+sku)
+			}
+		}
+	}
+	if len(ausentes) > 0 {
+		sort.Strings(ausentes)
+		return nil, nil, fmt.Errorf("%w: itens sem preço no ERP: %s", ErrInvalidRequest, strings.Join(ausentes, ", "))
+	}
+	return changes, inalterados, nil
+}
@@ -0,0 +1,343 @@
+package usecase
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"calculator/domain/entities"
+	"calculator/domain/repositories"
+)
+
+// ProposalUseCase conduz as propostas de preço pelo fluxo de aprovação até a publicação
+type ProposalUseCase interface {
+	CreateProposals(req entities.ProposalCreateRequest) ([]entities.PriceProposal, error)
+	// ProposeRepricing submete em nome do sistema os preços do recálculo automático
+	ProposeRepricing(itens []entities.ProposalDraft) ([]entities.PriceProposal, error)
+	GetProposal(id string) (entities.PriceProposal, error)
+	ListProposals(status string) ([]entities.PriceProposal, error)
+	// Transition aplica submeter, aprovar ou rejeitar; o resultado é informado por proposta
+	Transition(req entities.ProposalTransitionRequest) ([]entities.ProposalResult, error)
+	// Publish grava as propostas aprovadas no ERP em um único lote
+	Publish(req entities.ProposalPublishRequest) (entities.PriceWriteRun, error)
+}
+
+// proposalUseCaseImpl implementa ProposalUseCase
+type proposalUseCaseImpl struct {
+	proposalRepo repositories.ProposalRepository
+	erpPriceRepo repositories.ErpPriceRepository
+	priceWriteUC PriceWriteUseCase
+	now          func() time.Time
+}
+
+// NewProposalUseCase cria o fluxo de aprovação; a publicação usa a gravação no ERP
+func NewProposalUseCase(pr repositories.ProposalRepository, er repositories.ErpPriceRepository, pw PriceWriteUseCase) ProposalUseCase {
+	return &proposalUseCaseImpl{proposalRepo: pr, erpPriceRepo: er, priceWriteUC: pw, now: time.Now}
+}
+
+// CreateProposals calcula a variação sobre o preço vigente no ERP e o papel exigido.
+// Com Submeter as propostas já entram pendentes, passando pela aprovação automática.
+func (uc *proposalUseCaseImpl) CreateProposals(req entities.ProposalCreateRequest) ([]entities.PriceProposal, error) {
+	propostas, err := uc.newProposals(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.proposalRepo.SaveProposals(propostas); err != nil {
+		return nil, err
+	}
+	return propostas, nil
+}
+
+// ProposeRepricing cria as propostas já submetidas, com a aprovação automática dentro do
+// limite. Itens já no preço do ERP ou com proposta aberta no mesmo preço são ignorados,
+// então o mesmo recálculo repetido não duplica propostas.
+func (uc *proposalUseCaseImpl) ProposeRepricing(itens []entities.ProposalDraft) ([]entities.PriceProposal, error) {
+	if len(itens) == 0 {
+		return nil, nil
+	}
+	propostas, err := uc.newProposals(entities.ProposalCreateRequest{
+		Usuario:    entities.UsuarioSistema,
+		Submeter:   true,
+		Comentario: "recálculo automático",
+		Itens:      itens,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	skus := make([]string, len(propostas))
+	for i, p := range propostas {
+		skus[i] = p.Sku
+	}
+	abertas, err := uc.proposalRepo.ListOpenProposals(skus)
+	if err != nil {
+		return nil, err
+	}
+	abertos := map[string][]float64{}
+	for _, p := range abertas {
+		abertos[p.Tabela+"/"+p.Sku] = append(abertos[p.Tabela+"/"+p.Sku], p.PrecoProposto)
+	}
+
+	novas := propostas[:0]
+	for _, p := range propostas {
+		if p.PrecoAtual > 0 && math.Abs(p.PrecoProposto-p.PrecoAtual) < 0.005 {
+			continue
+		}
+		repetida := false
+		for _, preco := range abertos[p.Tabela+"/"+p.Sku] {
+			repetida = repetida || math.Abs(preco-p.PrecoProposto) < 0.005
+		}
+		if !repetida {
+			novas = append(novas, p)
+		}
+	}
+	if len(novas) == 0 {
+		return nil, nil
+	}
+	if err := uc.proposalRepo.SaveProposals(novas); err != nil {
+		return nil, err
+	}
+	return novas, nil
+}
+
+// newProposals monta as propostas sem gravá-las
+func (uc *proposalUseCaseImpl) newProposals(req entities.ProposalCreateRequest) ([]entities.PriceProposal, error) {
+	if req.Usuario == "" {
+		return nil, fmt.Errorf("%w: usuario é obrigatório", ErrInvalidRequest)
+	}
+	if len(req.Itens) == 0 {
+		return nil, fmt.Errorf("%w: nenhum item informado", ErrInvalidRequest)
+	}
+	if req.Submeter && strings.TrimSpace(req.Comentario) == "" {
+		return nil, fmt.Errorf("%w: comentario é obrigatório para submeter", ErrInvalidRequest)
+	}
+
+	regras, err := uc.approvalRules()
+	if err != nil {
+		return nil, err
+	}
+
+	porTabela := map[string][]string{}
+	for i, item := range req.Itens {
+		if item.Sku == "" || item.PrecoProposto <= 0 {
+			return nil, fmt.Errorf("%w: sku e preco_proposto positivo são obrigatórios", ErrInvalidRequest)
+		}
+		if item.Tabela == "" {
+			req.Itens[i].Tabela = entities.TabelaPrecoPadrao
+		}
+		porTabela[req.Itens[i].Tabela] = append(porTabela[req.Itens[i].Tabela], item.Sku)
+	}
+	atuais := map[string]float64{}
+	for tabela, skus := range porTabela {
+		precos, err := uc.erpPriceRepo.GetErpPrices(entities.PriceDiffFilter{Tabela: tabela, Skus: skus})
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range precos {
+			atuais[tabela+"/"+p.Sku] = p.Preco
+		}
+	}
+
+	agora := uc.now()
+	propostas := make([]entities.PriceProposal, 0, len(req.Itens))
+	for _, item := range req.Itens {
+		id, err := newID()
+		if err != nil {
+			return nil, err
+		}
+		p := entities.PriceProposal{
+			ID:            id,
+			Sku:           item.Sku,
+			Tabela:        item.Tabela,
+			Origem:        item.Origem,
+			PrecoAtual:    atuais[item.Tabela+"/"+item.Sku],
+			PrecoProposto: round2(item.PrecoProposto),
+			Status:        entities.PropostaRascunho,
+			CriadoPor:     req.Usuario,
+			CriadoEm:      agora,
+			AtualizadoEm:  agora,
+			Eventos: []entities.ProposalEvent{{
+				Para: entities.PropostaRascunho, Usuario: req.Usuario, Em: agora,
+			}},
+		}
+		// Sem preço no ERP a proposta segue a regra de maior variação
+		variacao := math.Inf(1)
+		if p.PrecoAtual > 0 {
+			p.Variacao = p.PrecoProposto/p.PrecoAtual - 1
+			variacao = p.Variacao
+		}
+		p.PapelExigido = entities.PapelExigido(regras, variacao)
+
+		if req.Submeter {
+			submitProposal(&p, req.Usuario, req.Comentario, agora)
+		}
+		propostas = append(propostas, p)
+	}
+	return propostas, nil
+}
+
+// GetProposal busca a proposta com o histórico
+func (uc *proposalUseCaseImpl) GetProposal(id string) (entities.PriceProposal, error) {
+	return uc.proposalRepo.GetProposal(id)
+}
+
+// ListProposals lista as propostas de uma situação
+func (uc *proposalUseCaseImpl) ListProposals(status string) ([]entities.PriceProposal, error) {
+	return uc.proposalRepo.ListProposals(status)
+}
+
+// Transition valida a ação e o papel do revisor para cada proposta. Aprovar e rejeitar
+// exigem o papel definido pela regra, e quem criou a proposta não a aprova; toda ação
+// exige comentário.
+func (uc *proposalUseCaseImpl) Transition(req entities.ProposalTransitionRequest) ([]entities.ProposalResult, error) {
+	if req.Usuario == "" {
+		return nil, fmt.Errorf("%w: usuario é obrigatório", ErrInvalidRequest)
+	}
+	if len(req.Ids) == 0 {
+		return nil, fmt.Errorf("%w: nenhuma proposta informada", ErrInvalidRequest)
+	}
+	switch req.Acao {
+	case entities.AcaoSubmeter, entities.AcaoAprovar, entities.AcaoRejeitar:
+	default:
+		return nil, fmt.Errorf("%w: acao deve ser submeter, aprovar ou rejeitar", ErrInvalidRequest)
+	}
+	if strings.TrimSpace(req.Comentario) == "" {
+		return nil, fmt.Errorf("%w: comentario é obrigatório para %s", ErrInvalidRequest, req.Acao)
+	}
+
+	resultados := make([]entities.ProposalResult, 0, len(req.Ids))
+	for _, id := range req.Ids {
+		res := entities.ProposalResult{ID: id}
+		status, err := uc.transitionOne(id, req)
+		if err != nil {
+			res.Erro = err.Error()
+		}
+		res.Status = status
+		resultados = append(resultados, res)
+	}
+	return resultados, nil
+}
+
+// transitionOne aplica a ação a uma proposta e devolve a situação final
+func (uc *proposalUseCaseImpl) transitionOne(id string, req entities.ProposalTransitionRequest) (string, error) {
+	p, err := uc.proposalRepo.GetProposal(id)
+	if err != nil {
+		return "", err
+	}
+	proximo, ok := entities.ProximoStatusProposta(p.Status, req.Acao)
+	if !ok {
+		return p.Status, fmt.Errorf("%w: proposta %s não permite %s", ErrInvalidRequest, p.Status, req.Acao)
+	}
+	papel := ""
+	if req.Acao != entities.AcaoSubmeter {
+		if req.Acao == entities.AcaoAprovar && req.Usuario == p.CriadoPor {
+			return p.Status, fmt.Errorf("%w: %s criou a proposta e não pode aprová-la", ErrForbidden, req.Usuario)
+		}
+		papel = p.PapelExigido
+		if papel != "" && !(entities.Identidade{Papeis: req.Papeis}).TemPapel(papel) {
+			return p.Status, fmt.Errorf("%w: variação de %.2f%% exige revisor com papel %s",
+				ErrForbidden, p.Variacao*100, papel)
+		}
+	}
+
+	agora := uc.now()
+	ev := entities.ProposalEvent{
+		De: p.Status, Para: proximo, Usuario: req.Usuario, Papel: papel, Comentario: req.Comentario, Em: agora,
+	}
+	if err := uc.proposalRepo.TransitionProposal(id, ev, ""); err != nil {
+		return p.Status, err
+	}
+	p.Status = proximo
+
+	// Ao submeter, a regra pode aprovar automaticamente
+	if req.Acao == entities.AcaoSubmeter && p.PapelExigido == "" {
+		ev := autoApprovalEvent(p, agora)
+		if err := uc.proposalRepo.TransitionProposal(id, ev, ""); err != nil {
+			return p.Status, err
+		}
+		p.Status = ev.Para
+	}
+	return p.Status, nil
+}
+
+// Publish grava todas as propostas aprovadas em um lote; em dry-run não muda as situações.
+// Só quem tem o papel de publicador grava na tabela do ERP.
+func (uc *proposalUseCaseImpl) Publish(req entities.ProposalPublishRequest) (entities.PriceWriteRun, error) {
+	if req.Usuario == "" {
+		return entities.PriceWriteRun{}, fmt.Errorf("%w: usuario é obrigatório", ErrInvalidRequest)
+	}
+	if !(entities.Identidade{Papeis: req.Papeis}).TemPapel(entities.PapelPublicador) {
+		return entities.PriceWriteRun{}, fmt.Errorf("%w: publicar propostas exige o papel %s", ErrForbidden, entities.PapelPublicador)
+	}
+	if len(req.Ids) == 0 {
+		return entities.PriceWriteRun{}, fmt.Errorf("%w: nenhuma proposta informada", ErrInvalidRequest)
+	}
+
+	propostas := make([]entities.PriceProposal, 0, len(req.Ids))
+	itens := make([]entities.PriceWriteItem, 0, len(req.Ids))
+	for _, id := range req.Ids {
+		p, err := uc.proposalRepo.GetProposal(id)
+		if err != nil {
+			return entities.PriceWriteRun{}, err
+		}
+		if p.Status != entities.PropostaAprovada {
+			return entities.PriceWriteRun{}, fmt.Errorf("%w: proposta %s está %s", ErrInvalidRequest, id, p.Status)
+		}
+		propostas = append(propostas, p)
+		itens = append(itens, entities.PriceWriteItem{Sku: p.Sku, Tabela: p.Tabela, Preco: p.PrecoProposto})
+	}
+
+	run, err := uc.priceWriteUC.WritePrices(entities.PriceWriteRequest{Usuario: req.Usuario, DryRun: req.DryRun, Itens: itens})
+	if err != nil || req.DryRun {
+		return run, err
+	}
+
+	agora := uc.now()
+	for _, p := range propostas {
+		ev := entities.ProposalEvent{
+			De: p.Status, Para: entities.PropostaPublicada, Usuario: req.Usuario,
+			Comentario: "gravação " + run.ID, Em: agora,
+		}
+		if err := uc.proposalRepo.TransitionProposal(p.ID, ev, run.ID); err != nil {
+			return run, fmt.Errorf("preços gravados na gravação %s, mas a proposta %s não foi atualizada: %w", run.ID, p.ID, err)
+		}
+	}
+	return run, nil
+}
+
+// submitProposal leva uma proposta nova de rascunho a pendente, aprovando-a quando a regra permitir
+func submitProposal(p *entities.PriceProposal, usuario, comentario string, em time.Time) {
+	p.Eventos = append(p.Eventos, entities.ProposalEvent{
+		De: p.Status, Para: entities.PropostaPendente, Usuario: usuario, Comentario: comentario, Em: em,
+	})
+	p.Status = entities.PropostaPendente
+	if p.PapelExigido == "" {
+		ev := autoApprovalEvent(*p, em)
+		p.Eventos = append(p.Eventos, ev)
+		p.Status = ev.Para
+	}
+}
+
+// autoApprovalEvent é a aprovação feita pelo sistema dentro do limite da regra
+func autoApprovalEvent(p entities.PriceProposal, em time.Time) entities.ProposalEvent {
+	return entities.ProposalEvent{
+		De:         entities.PropostaPendente,
+		Para:       entities.PropostaAprovada,
+		Usuario:    entities.UsuarioSistema,
+		Comentario: fmt.Sprintf("aprovação automática: variação de %.2f%% dentro do limite", p.Variacao*100),
+		Em:         em,
+	}
+}
+
+// approvalRules busca as regras cadastradas ou usa as regras padrão
+func (uc *proposalUseCaseImpl) approvalRules() ([]entities.ApprovalRule, error) {
+	regras, err := uc.proposalRepo.GetApprovalRules()
+	if err != nil {
+		return nil, err
+	}
+	if len(regras) == 0 {
+		regras = entities.RegrasAprovacaoPadrao()
+	}
+	return regras, nil
+}
@@ -0,0 +1,198 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"calculator/domain/entities"
+	"calculator/domain/repositories"
+
+	"github.com/sirupsen/logrus"
+)
+
+// validadePadraoDias é a validade do orçamento quando não informada
+const validadePadraoDias = 7
+
+// QuoteUseCase define os métodos de orçamento com vários itens
+type QuoteUseCase interface {
+	CreateQuote(req entities.QuoteRequest) (entities.Quote, error)
+	GetQuote(id string) (entities.Quote, error)
+}
+
+// quoteUseCaseImpl implementa QuoteUseCase
+type quoteUseCaseImpl struct {
+	priceUC   PriceUseCase
+	quoteRepo repositories.QuoteRepository
+	freightUC FreightUseCase
+}
+
+// NewQuoteUseCase cria o caso de uso de orçamentos a partir do cálculo de preço
+func NewQuoteUseCase(priceUC PriceUseCase, qr repositories.QuoteRepository, fu FreightUseCase) QuoteUseCase {
+	return &quoteUseCaseImpl{
+		priceUC:   priceUC,
+		quoteRepo: qr,
+		freightUC: fu,
+	}
+}
+
+// CreateQuote calcula cada item no nível do pedido, abre os impostos e salva o orçamento
+func (uc *quoteUseCaseImpl) CreateQuote(req entities.QuoteRequest) (entities.Quote, error) {
+	req.UF = strings.ToUpper(strings.TrimSpace(req.UF))
+	if !entities.UFs[req.UF] {
+		return entities.Quote{}, fmt.Errorf("%w: uf %q inválida", ErrInvalidRequest, req.UF)
+	}
+	if req.TipoCliente == "" {
+		req.TipoCliente = entities.ClienteConsumidorFinal
+	}
+	if !entities.TipoClienteValido(req.TipoCliente) {
+		return entities.Quote{}, fmt.Errorf("%w: tipo_cliente %q inválido", ErrInvalidRequest, req.TipoCliente)
+	}
+	if len(req.Itens) == 0 {
+		return entities.Quote{}, fmt.Errorf("%w: orçamento sem itens", ErrInvalidRequest)
+	}
+	if req.ValidadeDias <= 0 {
+		req.ValidadeDias = validadePadraoDias
+	}
+
+	id, err := newID()
+	if err != nil {
+		return entities.Quote{}, err
+	}
+	agora := time.Now()
+	quote := entities.Quote{
+		ID:          id,
+		CriadoEm:    agora,
+		ValidoAte:   agora.AddDate(0, 0, req.ValidadeDias),
+		UF:          req.UF,
+		Cep:         req.Cep,
+		Canal:       req.Canal,
+		TipoCliente: req.TipoCliente,
+		Vigente:     true,
+	}
+
+	results := make([]entities.PriceResult, 0, len(req.Itens))
+	for _, it := range req.Itens {
+		if it.Sku == "" || it.Quantidade <= 0 {
+			return entities.Quote{}, fmt.Errorf("%w: item com sku %q e quantidade %d", ErrInvalidRequest, it.Sku, it.Quantidade)
+		}
+
+		result, err := uc.priceUC.CalculatePrice(entities.PriceRequest{
+			Sku:         it.Sku,
+			TipoCliente: req.TipoCliente,
+			Quantidade:  it.Quantidade,
+			UF:          req.UF,
+			Cep:         req.Cep,
+			Canal:       req.Canal,
+		})
+		if err == nil {
+			err = requireFresh(result)
+		}
+		if err != nil {
+			return entities.Quote{}, fmt.Errorf("erro ao calcular o item %s: %w", it.Sku, err)
+		}
+
+		item := entities.QuoteItem{
+			Sku:           it.Sku,
+			Quantidade:    it.Quantidade,
+			PrecoUnitario: round2(result.ValorFinal),
+		}
+		item.Impostos = quoteItemTaxes(result, item.PrecoUnitario*float64(it.Quantidade))
+		quote.Itens = append(quote.Itens, item)
+		results = append(results, result)
+	}
+
+	// Frete calculado uma vez para o pedido e rateado entre os itens pelo valor
+	quote.Frete = uc.orderFreight(req, results)
+	bases := make([]float64, len(quote.Itens))
+	for i, item := range quote.Itens {
+		bases[i] = item.Impostos.ValorTotal
+	}
+	for i, frete := range entities.RatearFrete(quote.Frete.Valor, bases) {
+		quote.Itens[i].Impostos.Frete = frete
+		quote.Totais = quote.Totais.Add(quote.Itens[i].Impostos)
+	}
+
+	if err := uc.quoteRepo.SaveQuote(quote); err != nil {
+		return entities.Quote{}, fmt.Errorf("erro ao salvar orçamento: %w", err)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"quote_id": quote.ID,
+		"itens":    len(quote.Itens),
+		"total":    quote.Totais.ValorTotal,
+	}).Info("Orçamento gerado")
+
+	return quote, nil
+}
+
+// GetQuote recupera um orçamento salvo e informa se ainda está na validade
+func (uc *quoteUseCaseImpl) GetQuote(id string) (entities.Quote, error) {
+	quote, err := uc.quoteRepo.GetQuote(id)
+	if err != nil {
+		return quote, err
+	}
+	quote.Vigente = !quote.Expirado(time.Now())
+	return quote, nil
+}
+
+// orderFreight cota a carga somada do pedido na tabela das transportadoras. Sem peso e
+// medidas de algum item, ou sem faixa para o destino, soma o frete unitário de cada item.
+func (uc *quoteUseCaseImpl) orderFreight(req entities.QuoteRequest, results []entities.PriceResult) entities.QuoteFreight {
+	var carga entities.Carga
+	completa := true
+	comissao := 0.0
+	for _, r := range results {
+		comissao += r.Cost.Frete * float64(r.Request.Quantidade)
+		if r.Dimensoes == nil {
+			completa = false
+			continue
+		}
+		carga = carga.Somar(r.Dimensoes.Carga(r.Request.Quantidade))
+	}
+
+	frete := entities.QuoteFreight{Origem: entities.FreteComissao, Carga: carga, Valor: round2(comissao)}
+	if completa && uc.freightUC != nil {
+		if cot, ok := uc.freightUC.Tables().Cotar(carga, req.UF, req.Cep); ok {
+			frete.Origem = entities.FreteTransportadora
+			frete.Transportadora = cot.Transportadora
+			frete.PesoTaxado = cot.PesoTaxado
+			frete.Valor = round2(cot.Valor)
+		}
+	}
+	return frete
+}
+
+// quoteItemTaxes abre os impostos do item com as mesmas alíquotas do cálculo de preço,
+// já ajustadas à UF de destino quando ela tem alíquotas cadastradas
+func quoteItemTaxes(result entities.PriceResult, valorTotal float64) entities.QuoteTaxes {
+	aliquotaIcms := result.Input.IcmsEfetivo - result.Input.Difal
+	aliquotaDifal := result.Input.Difal
+	if result.Input.DifalDestinatario {
+		// Difal recolhido pelo cliente contribuinte, fora do documento do vendedor
+		aliquotaDifal = 0
+	}
+
+	icms := valorTotal * aliquotaIcms
+	// ICMS excluído da base de PIS/COFINS
+	basePisCofins := (valorTotal - icms) * result.Params.RedutorPadrao
+
+	var icmsSt, fcpSt float64
+	if result.St != nil {
+		// ST calculado sobre o preço unitário arredondado
+		st := entities.CalcularSt(round2(result.ValorFinal), entities.RegraST{Mva: result.St.Mva}, result.Icms, result.Params.Fcp)
+		icmsSt = st.Valor * float64(result.Request.Quantidade)
+		fcpSt = st.Fcp * float64(result.Request.Quantidade)
+	}
+
+	return entities.QuoteTaxes{
+		ValorTotal: round2(valorTotal),
+		Icms:       round2(icms),
+		Difal:      round2(valorTotal * aliquotaDifal),
+		Fcp:        round2(valorTotal * result.Params.Fcp),
+		Pis:        round2(basePisCofins * result.Params.AliquotaPis),
+		Cofins:     round2(basePisCofins * result.Params.AliquotaCofins),
+		IcmsSt:     round2(icmsSt),
+		FcpSt:      round2(fcpSt),
+	}
+}
@@ -0,0 +1,468 @@
+package usecase
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"calculator/domain/entities"
+	"calculator/domain/repositories"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Recálculo automático
+const (
+	// tamanhoLoteRecalculo é o número de SKUs gravados por transação
+	tamanhoLoteRecalculo = 200
+)
+
+// RepricingUseCase recalcula os preços afetados por mudanças no custo ou nos parâmetros
+type RepricingUseCase interface {
+	// HandleChange enfileira a mudança recebida do Postgres; não bloqueia
+	HandleChange(ev entities.ChangeEvent)
+	// RepriceBatch recalcula os SKUs, grava os preços calculados e propõe os que mudaram
+	RepriceBatch(skus []string, motivo string) (entities.RepricingBatchResult, error)
+	// Start processa a fila em segundo plano até o canal stop ser fechado; retry é a espera
+	// para repetir uma varredura que falhou
+	Start(retry time.Duration, stop <-chan struct{})
+	Status() entities.RepricingStatus
+}
+
+// repricingUseCaseImpl implementa RepricingUseCase com uma fila em memória sem repetição
+type repricingUseCaseImpl struct {
+	repricingRepo repositories.RepricingRepository
+	priceUC       PriceUseCase
+	// markdownUC aplica a remarcação por idade do estoque; nil desliga
+	markdownUC MarkdownUseCase
+	proposalUC ProposalUseCase
+	now        func() time.Time
+
+	mu        sync.Mutex
+	pendentes map[string]string // sku → motivo
+	ordem     []string
+	// indices guarda o índice de custo lido antes do recálculo, registrado depois dele
+	indices map[string]int
+	varrer  bool
+	// todos pede o recálculo de todos os produtos por mudança no config_params
+	todos  bool
+	retry  time.Duration
+	sinal  chan struct{}
+	status entities.RepricingStatus
+}
+
+// NewRepricingUseCase cria o recálculo automático; a fila só é processada após Start e é
+// alimentada pelo ouvinte das notificações do Postgres por HandleChange.
+// Os preços recalculados chegam ao ERP pelas propostas, nunca diretamente.
+func NewRepricingUseCase(rr repositories.RepricingRepository, pu PriceUseCase, mu MarkdownUseCase, po ProposalUseCase) RepricingUseCase {
+	return &repricingUseCaseImpl{
+		repricingRepo: rr,
+		priceUC:       pu,
+		markdownUC:    mu,
+		proposalUC:    po,
+		now:           time.Now,
+		pendentes:     map[string]string{},
+		indices:       map[string]int{},
+		sinal:         make(chan struct{}, 1),
+	}
+}
+
+// HandleChange registra o produto, a mudança de parâmetros ou o pedido de varredura.
+// As consultas ficam para o processamento da fila, fora da goroutine do ouvinte.
+func (uc *repricingUseCaseImpl) HandleChange(ev entities.ChangeEvent) {
+	uc.mu.Lock()
+	switch {
+	case ev.Perdidos:
+		uc.varrer = true
+	case ev.Todos:
+		uc.todos = true
+	case ev.Produto != "":
+		uc.enqueueLocked(ev.Produto, entities.MotivoIndiceCusto)
+	}
+	uc.mu.Unlock()
+	uc.notify()
+}
+
+// RepriceBatch calcula cada SKU com a requisição padrão, aplica a remarcação por idade do
+// estoque e grava em lotes. Os preços diferentes do ERP viram propostas submetidas pelo
+// sistema. SKUs com erro são devolvidos no resultado e não impedem os demais.
+func (uc *repricingUseCaseImpl) RepriceBatch(skus []string, motivo string) (entities.RepricingBatchResult, error) {
+	var res entities.RepricingBatchResult
+	if len(skus) == 0 {
+		return res, fmt.Errorf("%w: nenhum sku informado", ErrInvalidRequest)
+	}
+	if motivo == "" {
+		motivo = entities.MotivoManual
+	}
+
+	for inicio := 0; inicio < len(skus); inicio += tamanhoLoteRecalculo {
+		fim := inicio + tamanhoLoteRecalculo
+		if fim > len(skus) {
+			fim = len(skus)
+		}
+		results := make(map[string]entities.PriceResult, fim-inicio)
+		for _, sku := range skus[inicio:fim] {
+			result, err := uc.priceUC.CalculatePrice(entities.PriceRequest{Sku: sku})
+			if err == nil {
+				err = requireFresh(result)
+			}
+			if err != nil {
+				res.Erros = append(res.Erros, entities.RepricingError{Sku: sku, Erro: err.Error()})
+				continue
+			}
+			results[sku] = result
+		}
+		remarcados, err := uc.markdowns(results)
+		if err != nil {
+			return res, err
+		}
+
+		precos := make([]entities.ComputedPrice, 0, len(results))
+		itens := make([]entities.ProposalDraft, 0, len(results))
+		for _, sku := range skus[inicio:fim] {
+			result, ok := results[sku]
+			if !ok {
+				continue
+			}
+			cp := entities.ComputedPrice{
+				Produto:         sku,
+				Preco:           result.ValorFinal,
+				PrecoAVista:     result.PrecoAVista,
+				PrecoEquilibrio: result.PrecoEquilibrio,
+				CustoUnitario:   result.CustoUnitarioTotal,
+				Motivo:          motivo,
+				CalculadoEm:     uc.now(),
+			}
+			if m, ok := remarcados[sku]; ok && m.DescontoAplicado > 0 {
+				cp.Preco = m.PrecoProposto
+				cp.PrecoAVista = round2(result.PrecoAVista * (1 - m.DescontoAplicado))
+				cp.DescontoMarkdown = m.DescontoAplicado
+			}
+			precos = append(precos, cp)
+			origem := entities.OrigemRecalculo
+			if cp.DescontoMarkdown > 0 {
+				origem = entities.OrigemMarkdown
+			}
+			itens = append(itens, entities.ProposalDraft{Sku: sku, PrecoProposto: cp.Preco, Origem: origem})
+		}
+		if len(precos) > 0 {
+			if err := uc.repricingRepo.SaveComputedPrices(precos); err != nil {
+				return res, err
+			}
+		}
+		res.Recalculados += len(precos)
+
+		propostas, err := uc.proposalUC.ProposeRepricing(itens)
+		if err != nil {
+			return res, err
+		}
+		res.Propostas += len(propostas)
+	}
+
+	uc.mu.Lock()
+	uc.status.Recalculados += res.Recalculados
+	uc.status.Propostas += res.Propostas
+	uc.status.Erros += len(res.Erros)
+	uc.status.UltimoLote = uc.now()
+	uc.mu.Unlock()
+	return res, nil
+}
+
+// markdowns aplica as regras de idade do estoque aos preços do lote
+func (uc *repricingUseCaseImpl) markdowns(results map[string]entities.PriceResult) (map[string]entities.MarkdownProposal, error) {
+	if uc.markdownUC == nil {
+		return nil, nil
+	}
+	return uc.markdownUC.ApplyMarkdowns(results)
+}
+
+// Start processa a fila alimentada pelo ouvinte. A varredura só roda quando o ouvinte
+// conecta ou reconecta e cobre as notificações perdidas nesse intervalo.
+func (uc *repricingUseCaseImpl) Start(retry time.Duration, stop <-chan struct{}) {
+	if retry <= 0 {
+		retry = time.Minute
+	}
+	uc.mu.Lock()
+	uc.status.Ativo = true
+	uc.retry = retry
+	uc.mu.Unlock()
+
+	go func() {
+		for {
+			select {
+			case <-uc.sinal:
+				uc.drain(stop)
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// Status devolve a situação da fila
+func (uc *repricingUseCaseImpl) Status() entities.RepricingStatus {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	s := uc.status
+	s.Pendentes = len(uc.ordem)
+	return s
+}
+
+// drain processa a fila até esvaziar. A varredura e a mudança de parâmetros só rodam com a
+// fila vazia, e o hash dos parâmetros só é registrado quando tudo o que a mudança enfileirou
+// foi gravado; uma falha deixa o hash antigo e a próxima varredura recalcula todos de novo.
+func (uc *repricingUseCaseImpl) drain(stop <-chan struct{}) {
+	var hash string
+	falhou := false
+	for {
+		select {
+		case <-stop:
+			return
+		default:
+		}
+
+		if lote := uc.next(); len(lote) > 0 {
+			if !uc.processBatch(lote) {
+				falhou = true
+			}
+			continue
+		}
+
+		if hash != "" && !falhou {
+			if err := uc.repricingRepo.SaveParametersHash(hash); err != nil {
+				logrus.WithError(err).Warn("Falha ao registrar os parâmetros recalculados")
+			}
+		}
+		hash, falhou = "", false
+
+		varrer, todos := uc.takeTasks()
+		switch {
+		case varrer:
+			h, err := uc.scan()
+			if err != nil {
+				logrus.WithError(err).Warn("Falha na varredura de mudanças para recálculo; nova tentativa em instantes")
+				uc.retryLater(entities.ChangeEvent{Perdidos: true})
+				continue
+			}
+			hash = h
+		case todos:
+			h, err := uc.enqueueParameters()
+			if err != nil {
+				logrus.WithError(err).Warn("Falha ao listar produtos para recálculo; nova tentativa em instantes")
+				uc.retryLater(entities.ChangeEvent{Todos: true})
+				continue
+			}
+			hash = h
+		default:
+			return
+		}
+	}
+}
+
+// retryLater reenvia o evento depois da espera configurada em Start
+func (uc *repricingUseCaseImpl) retryLater(ev entities.ChangeEvent) {
+	uc.mu.Lock()
+	retry := uc.retry
+	uc.mu.Unlock()
+	time.AfterFunc(retry, func() { uc.HandleChange(ev) })
+}
+
+// next retira da fila o próximo lote de SKUs
+func (uc *repricingUseCaseImpl) next() map[string]string {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	n := len(uc.ordem)
+	if n > tamanhoLoteRecalculo {
+		n = tamanhoLoteRecalculo
+	}
+	lote := make(map[string]string, n)
+	for _, sku := range uc.ordem[:n] {
+		lote[sku] = uc.pendentes[sku]
+		delete(uc.pendentes, sku)
+	}
+	uc.ordem = uc.ordem[n:]
+	return lote
+}
+
+// takeTasks consome os pedidos de varredura e de recálculo de todos; a varredura já cobre
+// a mudança de parâmetros, então o segundo fica para depois dela
+func (uc *repricingUseCaseImpl) takeTasks() (varrer, todos bool) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	if uc.varrer {
+		uc.varrer = false
+		return true, false
+	}
+	todos = uc.todos
+	uc.todos = false
+	return false, todos
+}
+
+// processBatch recalcula um lote da fila agrupando os SKUs pelo motivo e registra os
+// índices de custo recalculados; false se algum grupo não foi gravado
+func (uc *repricingUseCaseImpl) processBatch(lote map[string]string) bool {
+	if err := uc.loadIndices(lote); err != nil {
+		logrus.WithError(err).Warn("Falha ao ler os índices de custo do lote; a varredura da reconexão os recalcula")
+	}
+	porMotivo := map[string][]string{}
+	for sku, motivo := range lote {
+		porMotivo[motivo] = append(porMotivo[motivo], sku)
+	}
+	ok := true
+	for motivo, skus := range porMotivo {
+		res, err := uc.RepriceBatch(skus, motivo)
+		if err != nil {
+			logrus.WithError(err).WithField("skus", len(skus)).Error("Falha ao gravar preços recalculados")
+			uc.forgetIndices(skus)
+			ok = false
+			// Os índices não foram registrados: a varredura encontra os SKUs de novo
+			uc.retryLater(entities.ChangeEvent{Perdidos: true})
+			continue
+		}
+		falhas := map[string]bool{}
+		for _, e := range res.Erros {
+			logrus.WithField("sku", e.Sku).Warn("Falha ao recalcular preço: " + e.Erro)
+			falhas[e.Sku] = true
+		}
+		if err := uc.markCostsSeen(skus, falhas); err != nil {
+			logrus.WithError(err).Warn("Falha ao registrar os índices de custo recalculados")
+		}
+	}
+	return ok
+}
+
+// loadIndices lê, antes do recálculo, o índice vigente dos SKUs vindos das notificações.
+// Um índice gravado depois da leitura chega em nova notificação e recoloca o SKU na fila.
+func (uc *repricingUseCaseImpl) loadIndices(lote map[string]string) error {
+	uc.mu.Lock()
+	var faltando []string
+	for sku := range lote {
+		if _, ok := uc.indices[sku]; !ok {
+			faltando = append(faltando, sku)
+		}
+	}
+	uc.mu.Unlock()
+	if len(faltando) == 0 {
+		return nil
+	}
+	indices, err := uc.repricingRepo.GetCostIndices(faltando)
+	if err != nil {
+		return err
+	}
+	uc.mu.Lock()
+	for _, c := range indices {
+		if _, ok := uc.indices[c.Produto]; !ok {
+			uc.indices[c.Produto] = c.Indice
+		}
+	}
+	uc.mu.Unlock()
+	return nil
+}
+
+// markCostsSeen registra o índice lido na varredura dos SKUs recalculados; os que falharam
+// voltam na próxima varredura
+func (uc *repricingUseCaseImpl) markCostsSeen(skus []string, falhas map[string]bool) error {
+	uc.mu.Lock()
+	var vistos []entities.CostChange
+	for _, sku := range skus {
+		if indice, ok := uc.indices[sku]; ok && !falhas[sku] {
+			vistos = append(vistos, entities.CostChange{Produto: sku, Indice: indice})
+		}
+		delete(uc.indices, sku)
+	}
+	uc.mu.Unlock()
+	if len(vistos) == 0 {
+		return nil
+	}
+	return uc.repricingRepo.MarkCostsSeen(vistos)
+}
+
+// forgetIndices descarta os índices de um lote não gravado
+func (uc *repricingUseCaseImpl) forgetIndices(skus []string) {
+	uc.mu.Lock()
+	for _, sku := range skus {
+		delete(uc.indices, sku)
+	}
+	uc.mu.Unlock()
+}
+
+// scan enfileira os produtos com índice de custo ainda não recalculado e, se os parâmetros
+// mudaram, todos. Devolve o hash dos parâmetros a registrar depois do recálculo, vazio sem
+// mudança.
+func (uc *repricingUseCaseImpl) scan() (string, error) {
+	mudancas, err := uc.repricingRepo.GetCostChanges()
+	if err != nil {
+		return "", err
+	}
+	atual, registrado, err := uc.repricingRepo.GetParametersHash()
+	if err != nil {
+		return "", err
+	}
+	parametros := atual != registrado
+	if parametros {
+		if err := uc.enqueueAll(); err != nil {
+			return "", err
+		}
+	}
+
+	uc.mu.Lock()
+	for _, m := range mudancas {
+		uc.enqueueLocked(m.Produto, entities.MotivoIndiceCusto)
+		uc.indices[m.Produto] = m.Indice
+	}
+	uc.status.UltimaVarredura = uc.now()
+	uc.mu.Unlock()
+	if len(mudancas) > 0 || parametros {
+		logrus.WithFields(logrus.Fields{"produtos": len(mudancas), "parametros": parametros}).
+			Info("Varredura de mudanças para recálculo concluída")
+	}
+	if !parametros {
+		return "", nil
+	}
+	return atual, nil
+}
+
+// enqueueParameters atende à notificação do config_params: enfileira todos os produtos e
+// devolve o hash dos parâmetros a registrar depois do recálculo
+func (uc *repricingUseCaseImpl) enqueueParameters() (string, error) {
+	atual, _, err := uc.repricingRepo.GetParametersHash()
+	if err != nil {
+		return "", err
+	}
+	if err := uc.enqueueAll(); err != nil {
+		return "", err
+	}
+	return atual, nil
+}
+
+// enqueueAll enfileira todos os produtos com índice de custo
+func (uc *repricingUseCaseImpl) enqueueAll() error {
+	produtos, err := uc.repricingRepo.GetAllProducts()
+	if err != nil {
+		return err
+	}
+	uc.mu.Lock()
+	for _, sku := range produtos {
+		uc.enqueueLocked(sku, entities.MotivoParametros)
+	}
+	uc.mu.Unlock()
+	return nil
+}
+
+// enqueueLocked adiciona o SKU uma única vez; a mudança de parâmetros prevalece como motivo
+func (uc *repricingUseCaseImpl) enqueueLocked(sku, motivo string) {
+	if _, ok := uc.pendentes[sku]; !ok {
+		uc.ordem = append(uc.ordem, sku)
+	}
+	if uc.pendentes[sku] != entities.MotivoParametros {
+		uc.pendentes[sku] = motivo
+	}
+}
+
+// notify acorda o processamento sem bloquear
+func (uc *repricingUseCaseImpl) notify() {
+	select {
+	case uc.sinal <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,117 @@
+package usecase
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"calculator/domain/entities"
+	"calculator/domain/repositories"
+
+	"github.com/sirupsen/logrus"
+)
+
+// SnapshotUseCase mantém em memória os dados do cálculo de preço e troca a versão
+// em uso de uma vez a cada carga
+type SnapshotUseCase interface {
+	Reload() (entities.SnapshotInfo, error)
+	// Current devolve o snapshot em uso; false enquanto nenhuma carga teve sucesso
+	Current() (repositories.PricingSnapshot, bool)
+	Status() entities.SnapshotStatus
+	// StartAutoReload recarrega o snapshot periodicamente até o canal stop ser fechado
+	StartAutoReload(interval time.Duration, stop <-chan struct{})
+}
+
+// snapshotAtual embrulha o snapshot para o atomic.Value, que não aceita nil
+type snapshotAtual struct {
+	snap repositories.PricingSnapshot
+}
+
+// snapshotUseCaseImpl implementa SnapshotUseCase
+type snapshotUseCaseImpl struct {
+	snapshotRepo repositories.SnapshotRepository
+	atual        atomic.Value // snapshotAtual
+	// reload impede duas cargas simultâneas (intervalo e recarga manual)
+	reload     sync.Mutex
+	mu         sync.Mutex
+	cargas     int64
+	falhas     int64
+	ultimoErro string
+	now        func() time.Time
+}
+
+// NewSnapshotUseCase cria o modo snapshot; o cálculo usa os bancos até o primeiro Reload
+func NewSnapshotUseCase(sr repositories.SnapshotRepository) SnapshotUseCase {
+	uc := &snapshotUseCaseImpl{snapshotRepo: sr, now: time.Now}
+	uc.atual.Store(snapshotAtual{})
+	return uc
+}
+
+// Reload carrega uma nova versão; em caso de falha a versão anterior continua em uso
+func (uc *snapshotUseCaseImpl) Reload() (entities.SnapshotInfo, error) {
+	uc.reload.Lock()
+	defer uc.reload.Unlock()
+
+	snap, err := uc.snapshotRepo.LoadSnapshot()
+	uc.mu.Lock()
+	if err != nil {
+		uc.falhas++
+		uc.ultimoErro = err.Error()
+		uc.mu.Unlock()
+		return entities.SnapshotInfo{}, err
+	}
+	uc.cargas++
+	uc.ultimoErro = ""
+	uc.mu.Unlock()
+	uc.atual.Store(snapshotAtual{snap: snap})
+
+	info := snap.Info()
+	logrus.WithFields(logrus.Fields{
+		"custos":         info.Custos,
+		"custos_fire":    info.CustosFire,
+		"perfis_fiscais": info.PerfisFiscais,
+		"duracao_ms":     info.DuracaoCargaMs,
+	}).Info("Snapshot de preços carregado")
+	return info, nil
+}
+
+// Current devolve o snapshot em uso
+func (uc *snapshotUseCaseImpl) Current() (repositories.PricingSnapshot, bool) {
+	a := uc.atual.Load().(snapshotAtual)
+	return a.snap, a.snap != nil
+}
+
+// Status informa a carga em uso, com a idade, e os contadores de cargas
+func (uc *snapshotUseCaseImpl) Status() entities.SnapshotStatus {
+	uc.mu.Lock()
+	st := entities.SnapshotStatus{Ativo: true, Cargas: uc.cargas, Falhas: uc.falhas, UltimoErro: uc.ultimoErro}
+	uc.mu.Unlock()
+
+	if snap, ok := uc.Current(); ok {
+		info := snap.Info().ComIdade(uc.now())
+		st.Atual = &info
+		st.ConsultasBanco = snap.ConsultasBanco()
+	}
+	return st
+}
+
+// StartAutoReload recarrega o snapshot a cada intervalo em segundo plano
+func (uc *snapshotUseCaseImpl) StartAutoReload(interval time.Duration, stop <-chan struct{}) {
+	if interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if _, err := uc.Reload(); err != nil {
+					logrus.WithError(err).Warn("Falha ao recarregar o snapshot de preços, mantendo a versão anterior")
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
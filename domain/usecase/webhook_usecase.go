@@ -0,0 +1,194 @@
+package usecase
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"calculator/domain/entities"
+	"calculator/domain/repositories"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Entrega dos webhooks
+const (
+	// loteEntregas é o número de entregas reservadas por rodada
+	loteEntregas = 50
+	// paraleloEntregas limita os envios simultâneos do lote
+	paraleloEntregas = 10
+	// reservaEntrega deve cobrir o envio do lote; vencida, outra instância pode reenviar.
+	// Com o timeout de 15s do envio, o lote leva no máximo 50/10 × 15s = 75s.
+	reservaEntrega = 5 * time.Minute
+	// maxTentativasWebhook leva a entrega para as mensagens mortas
+	maxTentativasWebhook  = 8
+	backoffInicialWebhook = 30 * time.Second
+	backoffMaximoWebhook  = time.Hour
+	// limiteMensagensMortas é o tamanho máximo da listagem
+	limiteMensagensMortas = 500
+)
+
+// WebhookUseCase entrega os eventos de alteração de preço aos destinos cadastrados
+type WebhookUseCase interface {
+	CreateSubscription(s entities.WebhookSubscription) (entities.WebhookSubscription, error)
+	ListSubscriptions() ([]entities.WebhookSubscription, error)
+	// DispatchDue envia as entregas vencidas e devolve quantas foram concluídas
+	DispatchDue() (int, error)
+	// StartDispatcher envia periodicamente até o canal stop ser fechado
+	StartDispatcher(interval time.Duration, stop <-chan struct{})
+	ListDeadLetters() ([]entities.WebhookDelivery, error)
+	// Replay reenvia eventos, inclusive mensagens mortas; devolve o número de entregas agendadas
+	Replay(req entities.EventReplayRequest) (int, error)
+}
+
+// webhookUseCaseImpl implementa WebhookUseCase
+type webhookUseCaseImpl struct {
+	outboxRepo repositories.OutboxRepository
+	sender     repositories.WebhookSender
+	now        func() time.Time
+}
+
+// NewWebhookUseCase cria o despachante do outbox
+func NewWebhookUseCase(or repositories.OutboxRepository, ws repositories.WebhookSender) WebhookUseCase {
+	return &webhookUseCaseImpl{outboxRepo: or, sender: ws, now: time.Now}
+}
+
+// CreateSubscription valida e grava o destino; eventos anteriores só chegam por replay
+func (uc *webhookUseCaseImpl) CreateSubscription(s entities.WebhookSubscription) (entities.WebhookSubscription, error) {
+	if s.Nome == "" || s.Segredo == "" {
+		return s, fmt.Errorf("%w: nome e segredo são obrigatórios", ErrInvalidRequest)
+	}
+	u, err := url.Parse(s.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return s, fmt.Errorf("%w: url deve ser http ou https", ErrInvalidRequest)
+	}
+	s.Ativo = true
+	return uc.outboxRepo.SaveSubscription(s)
+}
+
+// ListSubscriptions lista os destinos cadastrados
+func (uc *webhookUseCaseImpl) ListSubscriptions() ([]entities.WebhookSubscription, error) {
+	return uc.outboxRepo.ListSubscriptions()
+}
+
+// DispatchDue reserva um lote e envia as entregas em paralelo, no máximo paraleloEntregas
+// por vez. Falhas voltam com backoff exponencial até maxTentativasWebhook; a entrega é pelo
+// menos uma vez, e o destino usa o id do evento para descartar repetições.
+func (uc *webhookUseCaseImpl) DispatchDue() (int, error) {
+	entregas, err := uc.outboxRepo.ClaimDueDeliveries(loteEntregas, reservaEntrega)
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		mu        sync.Mutex
+		wg        sync.WaitGroup
+		entregues int
+		primeiro  error
+	)
+	sem := make(chan struct{}, paraleloEntregas)
+	for _, d := range entregas {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(d entities.WebhookDelivery) {
+			defer func() { <-sem; wg.Done() }()
+			ok, err := uc.deliver(d)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil && primeiro == nil {
+				primeiro = err
+			}
+			if ok {
+				entregues++
+			}
+		}(d)
+	}
+	wg.Wait()
+	return entregues, primeiro
+}
+
+// deliver envia uma entrega e registra o resultado; devolve true quando o destino aceitou
+func (uc *webhookUseCaseImpl) deliver(d entities.WebhookDelivery) (bool, error) {
+	corpo, err := json.Marshal(d.Evento)
+	if err != nil {
+		return false, fmt.Errorf("DispatchDue marshal: %w", err)
+	}
+
+	if err := uc.sender.Send(d, corpo); err != nil {
+		tentativas := d.Tentativas + 1
+		morta := tentativas >= maxTentativasWebhook
+		proxima := uc.now().Add(webhookBackoff(tentativas))
+		if uerr := uc.outboxRepo.MarkFailed(d.Evento.ID, d.AssinaturaID, tentativas, err.Error(), proxima, morta); uerr != nil {
+			return false, uerr
+		}
+		log := logrus.WithError(err).WithFields(logrus.Fields{
+			"evento": d.Evento.ID, "destino": d.URL, "tentativas": tentativas,
+		})
+		if morta {
+			log.Error("Webhook movido para as mensagens mortas")
+		} else {
+			log.Warn("Falha ao entregar webhook, nova tentativa agendada")
+		}
+		return false, nil
+	}
+
+	if err := uc.outboxRepo.MarkDelivered(d.Evento.ID, d.AssinaturaID, uc.now()); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// StartDispatcher roda DispatchDue a cada intervalo, emendando rodadas enquanto houver lote cheio
+func (uc *webhookUseCaseImpl) StartDispatcher(interval time.Duration, stop <-chan struct{}) {
+	if interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				for {
+					n, err := uc.DispatchDue()
+					if err != nil {
+						logrus.WithError(err).Warn("Falha ao despachar webhooks")
+						break
+					}
+					if n < loteEntregas {
+						break
+					}
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// ListDeadLetters lista as entregas que esgotaram as tentativas
+func (uc *webhookUseCaseImpl) ListDeadLetters() ([]entities.WebhookDelivery, error) {
+	return uc.outboxRepo.ListDeadLetters(limiteMensagensMortas)
+}
+
+// Replay exige os ids dos eventos ou a data inicial
+func (uc *webhookUseCaseImpl) Replay(req entities.EventReplayRequest) (int, error) {
+	if len(req.EventoIDs) == 0 && req.Desde.IsZero() {
+		return 0, fmt.Errorf("%w: informe evento_ids ou desde", ErrInvalidRequest)
+	}
+	return uc.outboxRepo.ReplayEvents(req)
+}
+
+// webhookBackoff dobra a espera a cada tentativa: 30s, 1min, 2min... até 1h
+func webhookBackoff(tentativas int) time.Duration {
+	d := backoffInicialWebhook
+	for i := 1; i < tentativas; i++ {
+		d *= 2
+		if d >= backoffMaximoWebhook {
+			return backoffMaximoWebhook
+		}
+	}
+	return d
+}
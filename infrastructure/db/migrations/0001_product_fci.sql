@@ -0,0 +1,7 @@
+-- Ficha de Conteúdo de Importação (FCI) por produto
+CREATE TABLE IF NOT EXISTS product_fci (
+    produto             TEXT PRIMARY KEY,
+    numero_fci          TEXT NOT NULL,
+    conteudo_importacao NUMERIC(7,4) NOT NULL CHECK (conteudo_importacao BETWEEN 0 AND 1),
+    data_emissao        DATE NOT NULL DEFAULT CURRENT_DATE
+);
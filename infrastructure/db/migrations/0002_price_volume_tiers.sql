@@ -0,0 +1,7 @@
+-- Faixas de quantidade com lucro desejado por tipo de cliente (segmento)
+CREATE TABLE IF NOT EXISTS price_volume_tiers (
+    tipo_cliente      TEXT    NOT NULL CHECK (tipo_cliente IN ('consumidor_final', 'contribuinte')),
+    quantidade_minima INTEGER NOT NULL CHECK (quantidade_minima > 0),
+    lucro_desejado    NUMERIC(7,4) NOT NULL,
+    PRIMARY KEY (tipo_cliente, quantidade_minima)
+);
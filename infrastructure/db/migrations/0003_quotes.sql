@@ -0,0 +1,10 @@
+-- Orçamentos com itens e abertura de impostos (payload completo em JSON)
+CREATE TABLE IF NOT EXISTS quotes (
+    id           TEXT PRIMARY KEY,
+    criado_em    TIMESTAMPTZ NOT NULL,
+    valido_ate   TIMESTAMPTZ NOT NULL,
+    uf           CHAR(2) NOT NULL,
+    tipo_cliente TEXT NOT NULL,
+    valor_total  NUMERIC(14,2) NOT NULL,
+    payload      JSONB NOT NULL
+);
@@ -0,0 +1,29 @@
+-- Vínculo dos códigos de fornecedor e EAN com os produtos
+CREATE TABLE IF NOT EXISTS product_codes (
+    produto           TEXT NOT NULL,
+    ean               TEXT,
+    cnpj_fornecedor   TEXT,
+    codigo_fornecedor TEXT
+);
+CREATE INDEX IF NOT EXISTS product_codes_ean_idx ON product_codes (ean);
+CREATE INDEX IF NOT EXISTS product_codes_fornecedor_idx ON product_codes (cnpj_fornecedor, codigo_fornecedor);
+
+-- Entradas de compra importadas das NF-e (valores totais da linha)
+CREATE TABLE IF NOT EXISTS purchase_entries (
+    chave_nfe          CHAR(44) NOT NULL,
+    numero_item        INTEGER NOT NULL,
+    produto            TEXT NOT NULL,
+    fornecedor         TEXT NOT NULL,
+    data_emissao       TIMESTAMPTZ NOT NULL,
+    quantidade         NUMERIC(15,4) NOT NULL,
+    custo_nf           NUMERIC(15,4) NOT NULL,
+    credito_icms       NUMERIC(15,4) NOT NULL,
+    credito_pis_cofins NUMERIC(15,4) NOT NULL,
+    ipi                NUMERIC(15,4) NOT NULL,
+    importado_em       TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (chave_nfe, numero_item)
+);
+CREATE INDEX IF NOT EXISTS purchase_entries_produto_idx ON purchase_entries (produto, data_emissao);
+
+-- Estoque após cada índice, usado na ponderação do custo médio
+ALTER TABLE IF EXISTS productscmp ADD COLUMN IF NOT EXISTS quantidade NUMERIC(15,4) NOT NULL DEFAULT 0;
@@ -0,0 +1,15 @@
+-- Data de referência de cada índice de custo médio
+ALTER TABLE IF EXISTS productscmp ADD COLUMN IF NOT EXISTS data_indice TIMESTAMPTZ;
+CREATE INDEX IF NOT EXISTS productscmp_produto_data_idx ON productscmp (produto, data_indice);
+
+-- Movimentações de estoque que não são compras (vendas, devoluções, ajustes).
+-- Quantidade com sinal: saídas negativas, entradas positivas.
+CREATE TABLE IF NOT EXISTS stock_movements (
+    id         BIGSERIAL PRIMARY KEY,
+    produto    TEXT NOT NULL,
+    data       TIMESTAMPTZ NOT NULL,
+    tipo       TEXT NOT NULL CHECK (tipo IN ('venda', 'devolucao_venda', 'devolucao_compra', 'ajuste')),
+    quantidade NUMERIC(15,4) NOT NULL,
+    referencia TEXT
+);
+CREATE INDEX IF NOT EXISTS stock_movements_produto_idx ON stock_movements (produto, data);
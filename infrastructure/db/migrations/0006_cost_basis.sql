@@ -0,0 +1,22 @@
+-- Tabela de preços de fornecedor (custo bruto unitário de nota)
+CREATE TABLE IF NOT EXISTS supplier_price_list (
+    produto         TEXT NOT NULL,
+    fornecedor      TEXT NOT NULL,
+    custo           NUMERIC(15,4) NOT NULL CHECK (custo > 0),
+    vigencia_inicio DATE NOT NULL,
+    PRIMARY KEY (produto, fornecedor, vigencia_inicio)
+);
+
+-- Custo de reposição informado manualmente (custo bruto unitário de nota)
+CREATE TABLE IF NOT EXISTS replacement_costs (
+    produto      TEXT PRIMARY KEY,
+    custo        NUMERIC(15,4) NOT NULL CHECK (custo > 0),
+    informado_em TIMESTAMPTZ NOT NULL DEFAULT now(),
+    usuario      TEXT
+);
+
+-- Base de custo padrão por departamento
+CREATE TABLE IF NOT EXISTS department_cost_basis (
+    departamento INTEGER PRIMARY KEY,
+    base_custo   TEXT NOT NULL CHECK (base_custo IN ('medio', 'ultima_compra', 'tabela_fornecedor', 'reposicao'))
+);
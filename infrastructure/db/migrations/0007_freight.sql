@@ -0,0 +1,31 @@
+-- Peso e medidas da embalagem de envio por produto
+CREATE TABLE IF NOT EXISTS product_dimensions (
+    produto        TEXT PRIMARY KEY,
+    peso_kg        NUMERIC(10,3) NOT NULL,
+    altura_cm      NUMERIC(10,2) NOT NULL,
+    largura_cm     NUMERIC(10,2) NOT NULL,
+    comprimento_cm NUMERIC(10,2) NOT NULL
+);
+
+-- Tabelas de frete das transportadoras por UF ou faixa de CEP e faixa de peso
+CREATE TABLE IF NOT EXISTS freight_rates (
+    transportadora     TEXT NOT NULL,
+    uf                 CHAR(2),
+    cep_inicio         INTEGER,
+    cep_fim            INTEGER,
+    peso_min_kg        NUMERIC(10,3) NOT NULL DEFAULT 0,
+    peso_max_kg        NUMERIC(10,3),
+    valor              NUMERIC(12,2) NOT NULL,
+    valor_kg_excedente NUMERIC(12,4) NOT NULL DEFAULT 0,
+    fator_cubagem      NUMERIC(10,2) NOT NULL DEFAULT 6000,
+    CHECK (uf IS NOT NULL OR (cep_inicio IS NOT NULL AND cep_fim IS NOT NULL))
+);
+
+-- Regras de frete subsidiado dos marketplaces
+CREATE TABLE IF NOT EXISTS freight_subsidy_rules (
+    canal                      TEXT PRIMARY KEY,
+    preco_minimo               NUMERIC(12,2) NOT NULL,
+    percentual_vendedor        NUMERIC(7,4) NOT NULL,
+    percentual_vendedor_abaixo NUMERIC(7,4) NOT NULL DEFAULT 0,
+    limite_vendedor            NUMERIC(12,2) NOT NULL DEFAULT 0
+);
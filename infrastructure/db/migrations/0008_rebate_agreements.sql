@@ -0,0 +1,21 @@
+-- Fornecedor (CNPJ) e marca de cada produto
+CREATE TABLE IF NOT EXISTS product_suppliers (
+    produto    TEXT PRIMARY KEY,
+    fornecedor TEXT NOT NULL,
+    marca      TEXT
+);
+
+-- Acordos de rebate negociados por fornecedor, opcionalmente restritos a uma marca
+CREATE TABLE IF NOT EXISTS rebate_agreements (
+    id              SERIAL PRIMARY KEY,
+    fornecedor      TEXT NOT NULL,
+    marca           TEXT,
+    descricao       TEXT NOT NULL,
+    percentual      NUMERIC(7,4) NOT NULL CHECK (percentual BETWEEN 0 AND 1),
+    -- Volume mínimo de compras (valor de nota) no período de vigência
+    volume_minimo   NUMERIC(15,2) NOT NULL DEFAULT 0,
+    vigencia_inicio DATE NOT NULL,
+    vigencia_fim    DATE NOT NULL,
+    CHECK (vigencia_fim >= vigencia_inicio)
+);
+CREATE INDEX IF NOT EXISTS rebate_agreements_fornecedor_idx ON rebate_agreements (fornecedor, vigencia_inicio, vigencia_fim);
@@ -0,0 +1,12 @@
+-- Custos de meios de pagamento por canal (canal 'padrao' vale para os demais)
+CREATE TABLE IF NOT EXISTS payment_profiles (
+    canal                   TEXT NOT NULL,
+    meio_pagamento          TEXT NOT NULL CHECK (meio_pagamento IN ('pix', 'boleto', 'debito', 'credito')),
+    -- Taxa do adquirente/intermediador sobre o valor da venda
+    taxa                    NUMERIC(7,4) NOT NULL DEFAULT 0,
+    -- Parcelas sem juros oferecidas ao cliente (crédito)
+    parcelas_sem_juros      INTEGER NOT NULL DEFAULT 1 CHECK (parcelas_sem_juros >= 1),
+    -- Taxa mensal de antecipação dos recebíveis parcelados
+    taxa_antecipacao_mensal NUMERIC(7,4) NOT NULL DEFAULT 0,
+    PRIMARY KEY (canal, meio_pagamento)
+);
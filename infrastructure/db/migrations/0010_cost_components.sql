@@ -0,0 +1,14 @@
+-- Componentes de custo por departamento e/ou canal (NULL vale para todos).
+-- provisao_custo_nf substitui a provisão fixa de 1% sobre o custo médio NF.
+CREATE TABLE IF NOT EXISTS cost_components (
+    id                SERIAL PRIMARY KEY,
+    departamento      INTEGER,
+    canal             TEXT,
+    taxa_devolucao    NUMERIC(7,4) NOT NULL DEFAULT 0,
+    taxa_chargeback   NUMERIC(7,4) NOT NULL DEFAULT 0,
+    embalagem         NUMERIC(12,4) NOT NULL DEFAULT 0,
+    fulfillment       NUMERIC(12,4) NOT NULL DEFAULT 0,
+    provisao_custo_nf NUMERIC(7,4) NOT NULL DEFAULT 0.01
+);
+CREATE UNIQUE INDEX IF NOT EXISTS cost_components_escopo_idx
+    ON cost_components (COALESCE(departamento, -1), COALESCE(canal, ''));
@@ -0,0 +1,5 @@
+-- Regras de remarcação por idade do estoque
+CREATE TABLE IF NOT EXISTS markdown_rules (
+    dias_minimos INTEGER PRIMARY KEY CHECK (dias_minimos > 0),
+    desconto     NUMERIC(7,4) NOT NULL CHECK (desconto > 0 AND desconto < 1)
+);
@@ -0,0 +1,11 @@
+-- Preços de concorrentes importados da ferramenta de monitoramento
+CREATE TABLE IF NOT EXISTS competitor_prices (
+    id           BIGSERIAL PRIMARY KEY,
+    produto      TEXT NOT NULL,
+    ean          TEXT,
+    concorrente  TEXT NOT NULL,
+    preco        NUMERIC(15,4) NOT NULL CHECK (preco > 0),
+    coletado_em  TIMESTAMPTZ NOT NULL,
+    importado_em TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS competitor_prices_produto_idx ON competitor_prices (produto, concorrente, coletado_em DESC);
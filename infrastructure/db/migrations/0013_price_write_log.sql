@@ -0,0 +1,21 @@
+-- Gravações de preço na tabela do ERP (antes/depois de cada item)
+CREATE TABLE IF NOT EXISTS price_write_runs (
+    id            TEXT PRIMARY KEY,
+    criado_em     TIMESTAMPTZ NOT NULL,
+    usuario       TEXT NOT NULL,
+    dry_run       BOOLEAN NOT NULL,
+    status        TEXT NOT NULL CHECK (status IN ('pendente', 'simulada', 'aplicada', 'falhou', 'revertida')),
+    erro          TEXT,
+    atualizado_em TIMESTAMPTZ NOT NULL DEFAULT now(),
+    revertido_por TEXT
+);
+
+CREATE TABLE IF NOT EXISTS price_write_changes (
+    run_id         TEXT NOT NULL REFERENCES price_write_runs (id),
+    sku            TEXT NOT NULL,
+    produto        INTEGER NOT NULL,
+    tabela         TEXT NOT NULL,
+    preco_anterior NUMERIC(15,4) NOT NULL,
+    preco_novo     NUMERIC(15,4) NOT NULL,
+    PRIMARY KEY (run_id, tabela, sku)
+);
@@ -0,0 +1,38 @@
+-- Propostas de preço e fluxo de aprovação
+CREATE TABLE IF NOT EXISTS price_proposals (
+    id             TEXT PRIMARY KEY,
+    sku            TEXT NOT NULL,
+    tabela         TEXT NOT NULL,
+    origem         TEXT NOT NULL DEFAULT '',
+    preco_atual    NUMERIC(15,4) NOT NULL,
+    preco_proposto NUMERIC(15,4) NOT NULL CHECK (preco_proposto > 0),
+    variacao       NUMERIC(9,4) NOT NULL,
+    papel_exigido  TEXT NOT NULL DEFAULT '',
+    status         TEXT NOT NULL CHECK (status IN ('rascunho', 'pendente', 'aprovada', 'rejeitada', 'publicada')),
+    criado_por     TEXT NOT NULL,
+    criado_em      TIMESTAMPTZ NOT NULL,
+    atualizado_em  TIMESTAMPTZ NOT NULL,
+    gravacao_id    TEXT REFERENCES price_write_runs (id)
+);
+CREATE INDEX IF NOT EXISTS price_proposals_status_idx ON price_proposals (status, criado_em);
+
+-- Cada transição com revisor, comentário e data
+CREATE TABLE IF NOT EXISTS price_proposal_events (
+    id          BIGSERIAL PRIMARY KEY,
+    proposta_id TEXT NOT NULL REFERENCES price_proposals (id),
+    de          TEXT NOT NULL DEFAULT '',
+    para        TEXT NOT NULL,
+    usuario     TEXT NOT NULL,
+    papel       TEXT NOT NULL DEFAULT '',
+    comentario  TEXT NOT NULL DEFAULT '',
+    criado_em   TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX IF NOT EXISTS price_proposal_events_proposta_idx ON price_proposal_events (proposta_id, id);
+
+-- Papel exigido por faixa de variação absoluta (variacao_ate NULL = sem limite;
+-- papel vazio = aprovação automática)
+CREATE TABLE IF NOT EXISTS approval_rules (
+    id            SERIAL PRIMARY KEY,
+    variacao_ate  NUMERIC(9,4),
+    papel_exigido TEXT NOT NULL DEFAULT ''
+);
@@ -0,0 +1,14 @@
+-- Elasticidades-preço estimadas a partir do histórico de vendas (última estimativa por escopo)
+CREATE TABLE IF NOT EXISTS elasticity_estimates (
+    escopo       TEXT NOT NULL CHECK (escopo IN ('sku', 'departamento')),
+    chave        TEXT NOT NULL,
+    elasticidade NUMERIC(9,4) NOT NULL,
+    erro_padrao  NUMERIC(9,4) NOT NULL,
+    r2           NUMERIC(7,4) NOT NULL,
+    observacoes  INTEGER NOT NULL,
+    confianca    TEXT NOT NULL,
+    periodo_de   DATE NOT NULL,
+    periodo_ate  DATE NOT NULL,
+    calculado_em TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (escopo, chave)
+);
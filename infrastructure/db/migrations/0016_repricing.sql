@@ -0,0 +1,54 @@
+-- Preço calculado vigente de cada produto, mantido pelo recálculo automático
+CREATE TABLE IF NOT EXISTS computed_prices (
+    produto          TEXT PRIMARY KEY,
+    preco            NUMERIC(15,2) NOT NULL,
+    preco_a_vista    NUMERIC(15,2) NOT NULL,
+    preco_equilibrio NUMERIC(15,4) NOT NULL,
+    custo_unitario   NUMERIC(15,4) NOT NULL,
+    motivo           TEXT NOT NULL,
+    calculado_em     TIMESTAMPTZ NOT NULL
+);
+
+-- Marca d'água da varredura de recuperação: mudanças até ela já foram recalculadas
+CREATE TABLE IF NOT EXISTS repricing_state (
+    id             INTEGER PRIMARY KEY CHECK (id = 1),
+    processado_ate TIMESTAMPTZ NOT NULL
+);
+INSERT INTO repricing_state (id, processado_ate) VALUES (1, now()) ON CONFLICT (id) DO NOTHING;
+
+-- Notificações no canal 'repricing': o payload é o produto, ou '*' para todos
+CREATE OR REPLACE FUNCTION notify_repricing_productscmp() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('repricing', NEW.produto::text);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION notify_repricing_config_params() RETURNS trigger AS $$
+BEGIN
+    IF NEW IS DISTINCT FROM OLD THEN
+        NEW.atualizado_em := now();
+        PERFORM pg_notify('repricing', '*');
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- As tabelas de origem são do ERP; os gatilhos só são criados quando elas existem
+DO $$
+BEGIN
+    IF to_regclass('productscmp') IS NOT NULL THEN
+        ALTER TABLE productscmp ADD COLUMN IF NOT EXISTS inserido_em TIMESTAMPTZ NOT NULL DEFAULT now();
+        CREATE INDEX IF NOT EXISTS productscmp_inserido_em_idx ON productscmp (inserido_em);
+        DROP TRIGGER IF EXISTS productscmp_repricing ON productscmp;
+        CREATE TRIGGER productscmp_repricing AFTER INSERT ON productscmp
+            FOR EACH ROW EXECUTE PROCEDURE notify_repricing_productscmp();
+    END IF;
+    IF to_regclass('config_params') IS NOT NULL THEN
+        ALTER TABLE config_params ADD COLUMN IF NOT EXISTS atualizado_em TIMESTAMPTZ NOT NULL DEFAULT now();
+        DROP TRIGGER IF EXISTS config_params_repricing ON config_params;
+        CREATE TRIGGER config_params_repricing BEFORE UPDATE ON config_params
+            FOR EACH ROW EXECUTE PROCEDURE notify_repricing_config_params();
+    END IF;
+END
+$$;
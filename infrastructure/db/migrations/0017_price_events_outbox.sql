@@ -0,0 +1,33 @@
+-- Destinos dos webhooks de alteração de preço
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    id        BIGSERIAL PRIMARY KEY,
+    nome      TEXT NOT NULL,
+    url       TEXT NOT NULL,
+    segredo   TEXT NOT NULL,
+    ativo     BOOLEAN NOT NULL DEFAULT true,
+    criado_em TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Outbox: eventos gravados na mesma transação dos preços calculados
+CREATE TABLE IF NOT EXISTS price_events (
+    id        BIGSERIAL PRIMARY KEY,
+    tipo      TEXT NOT NULL,
+    produto   TEXT NOT NULL,
+    payload   JSONB NOT NULL,
+    criado_em TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS price_events_criado_em_idx ON price_events (criado_em);
+
+-- Entrega de cada evento a cada destino; 'falhou' é a lista de mensagens mortas
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    evento_id         BIGINT NOT NULL REFERENCES price_events (id) ON DELETE CASCADE,
+    assinatura_id     BIGINT NOT NULL REFERENCES webhook_subscriptions (id) ON DELETE CASCADE,
+    status            TEXT NOT NULL DEFAULT 'pendente' CHECK (status IN ('pendente', 'entregue', 'falhou')),
+    tentativas        INTEGER NOT NULL DEFAULT 0,
+    proxima_tentativa TIMESTAMPTZ NOT NULL DEFAULT now(),
+    ultimo_erro       TEXT NOT NULL DEFAULT '',
+    entregue_em       TIMESTAMPTZ,
+    PRIMARY KEY (evento_id, assinatura_id)
+);
+CREATE INDEX IF NOT EXISTS webhook_deliveries_pendentes_idx ON webhook_deliveries (proxima_tentativa)
+    WHERE status = 'pendente';
@@ -0,0 +1,32 @@
+-- Jobs assíncronos de cálculo de preço em lote
+CREATE TABLE IF NOT EXISTS price_jobs (
+    id                      TEXT PRIMARY KEY,
+    status                  TEXT NOT NULL CHECK (status IN ('pendente', 'executando', 'concluido', 'cancelado')),
+    usuario                 TEXT NOT NULL,
+    parametros              JSONB NOT NULL,
+    total                   INTEGER NOT NULL,
+    processados             INTEGER NOT NULL DEFAULT 0,
+    erros                   INTEGER NOT NULL DEFAULT 0,
+    criado_em               TIMESTAMPTZ NOT NULL,
+    iniciado_em             TIMESTAMPTZ,
+    concluido_em            TIMESTAMPTZ,
+    -- Reserva da instância que executa; vencida, outra instância retoma o job
+    reservado_ate           TIMESTAMPTZ,
+    -- Início e progresso da execução atual, usados na estimativa de término
+    retomado_em             TIMESTAMPTZ,
+    processados_na_retomada INTEGER NOT NULL DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS price_jobs_status_idx ON price_jobs (status, criado_em);
+
+CREATE TABLE IF NOT EXISTS price_job_items (
+    job_id           TEXT NOT NULL REFERENCES price_jobs (id) ON DELETE CASCADE,
+    posicao          INTEGER NOT NULL,
+    sku              TEXT NOT NULL,
+    status           TEXT NOT NULL DEFAULT 'pendente' CHECK (status IN ('pendente', 'ok', 'erro')),
+    preco            NUMERIC(15,2),
+    preco_a_vista    NUMERIC(15,2),
+    preco_equilibrio NUMERIC(15,4),
+    erro             TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (job_id, posicao)
+);
+CREATE INDEX IF NOT EXISTS price_job_items_pendentes_idx ON price_job_items (job_id, posicao) WHERE status = 'pendente';
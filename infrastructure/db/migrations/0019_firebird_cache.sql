@@ -0,0 +1,18 @@
+-- Última leitura boa do Firebird por produto, usada quando ele está fora do ar
+CREATE TABLE IF NOT EXISTS cost_fire_cache (
+    produto       TEXT PRIMARY KEY,
+    sku_fire      TEXT NOT NULL,
+    cod_produto   TEXT NOT NULL,
+    departamento  INTEGER NOT NULL,
+    comissao      NUMERIC(15,4) NOT NULL,
+    frete         NUMERIC(15,4) NOT NULL,
+    atualizado_em TIMESTAMPTZ NOT NULL
+);
+
+-- Perfil fiscal (redução de ICMS e origem); reducao_icms nula quando o perfil não tem linhas
+CREATE TABLE IF NOT EXISTS tax_profile_cache (
+    produto       INTEGER PRIMARY KEY,
+    reducao_icms  NUMERIC(15,4),
+    origem_prod   TEXT NOT NULL,
+    atualizado_em TIMESTAMPTZ NOT NULL
+);
@@ -0,0 +1,10 @@
+-- Alíquotas de ICMS por UF de destino, mantidas pelo fiscal
+CREATE TABLE IF NOT EXISTS icms_uf (
+    uf                     CHAR(2) PRIMARY KEY,
+    -- Alíquota interna da UF de destino (base do Difal na venda a não contribuinte)
+    aliquota_interna       NUMERIC(6,4) NOT NULL,
+    -- Alíquota interestadual de mercadoria nacional saindo da UF de origem (7% ou 12%)
+    aliquota_interestadual NUMERIC(6,4) NOT NULL,
+    -- Fundo de Combate à Pobreza da UF de destino
+    fcp                    NUMERIC(6,4) NOT NULL DEFAULT 0
+);
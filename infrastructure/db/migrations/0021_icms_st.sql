@@ -0,0 +1,8 @@
+-- Substituição tributária por produto e UF de destino, mantida pelo fiscal
+CREATE TABLE IF NOT EXISTS icms_st (
+    produto TEXT NOT NULL,
+    uf      CHAR(2) NOT NULL,
+    -- MVA aplicada na base do ST; na venda interestadual, já ajustada
+    mva     NUMERIC(7,4) NOT NULL CHECK (mva >= 0),
+    PRIMARY KEY (produto, uf)
+);
@@ -0,0 +1,28 @@
+-- Estoque após cada índice de custo médio e a data da entrada que o gerou. Fica fora
+-- do productscmp, que é do ERP; índice sem linha aqui tem estoque desconhecido.
+CREATE TABLE IF NOT EXISTS cmp_stock (
+    produto     TEXT NOT NULL,
+    index       INTEGER NOT NULL,
+    quantidade  NUMERIC(15,4) NOT NULL,
+    data_indice TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (produto, index)
+);
+CREATE INDEX IF NOT EXISTS cmp_stock_produto_data_idx ON cmp_stock (produto, data_indice);
+
+-- As colunas quantidade e data_indice criadas no productscmp pelas migrações 0004 e 0005
+-- passam para o cmp_stock: copia os índices gerados pela importação (com data) e remove
+-- as colunas e o índice do productscmp
+DO $$
+BEGIN
+    IF to_regclass('productscmp') IS NOT NULL THEN
+        INSERT INTO cmp_stock (produto, index, quantidade, data_indice)
+        SELECT produto, index, quantidade, data_indice
+        FROM productscmp
+        WHERE data_indice IS NOT NULL
+        ON CONFLICT DO NOTHING;
+
+        DROP INDEX IF EXISTS productscmp_produto_data_idx;
+        ALTER TABLE productscmp DROP COLUMN IF EXISTS data_indice;
+        ALTER TABLE productscmp DROP COLUMN IF EXISTS quantidade;
+    END IF;
+END $$;
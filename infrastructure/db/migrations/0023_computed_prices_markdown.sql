@@ -0,0 +1,2 @@
+-- Desconto de remarcação por idade do estoque aplicado pelo recálculo
+ALTER TABLE computed_prices ADD COLUMN IF NOT EXISTS desconto_markdown NUMERIC(7,4) NOT NULL DEFAULT 0;
@@ -0,0 +1,7 @@
+-- Jobs de relatório: sem itens, o resultado inteiro fica no próprio job
+ALTER TABLE price_jobs ADD COLUMN IF NOT EXISTS tipo TEXT NOT NULL DEFAULT 'preco';
+ALTER TABLE price_jobs ADD COLUMN IF NOT EXISTS resultado JSONB;
+ALTER TABLE price_jobs ADD COLUMN IF NOT EXISTS erro TEXT NOT NULL DEFAULT '';
+ALTER TABLE price_jobs DROP CONSTRAINT IF EXISTS price_jobs_status_check;
+ALTER TABLE price_jobs ADD CONSTRAINT price_jobs_status_check
+    CHECK (status IN ('pendente', 'executando', 'concluido', 'cancelado', 'falhou'));
@@ -0,0 +1,11 @@
+-- Usuários da API: o token vai no cabeçalho Authorization: Bearer <token> e só o hash
+-- SHA-256 (hex) é gravado. Os papéis liberam a aprovação das propostas de preço.
+-- Exemplo: INSERT INTO api_users (usuario, token_hash, papeis)
+--          VALUES ('ana', encode(sha256('<token>'::bytea), 'hex'), '{gerente}');
+CREATE TABLE IF NOT EXISTS api_users (
+    usuario    TEXT PRIMARY KEY,
+    token_hash TEXT NOT NULL UNIQUE,
+    papeis     TEXT[] NOT NULL DEFAULT '{}',
+    ativo      BOOLEAN NOT NULL DEFAULT true,
+    criado_em  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1,13 @@
+-- Fila de publicação das gravações de preço no sysuni (BI). Só gravações aplicadas ou
+-- revertidas entram na fila, na mesma transação que registra a situação.
+CREATE TABLE IF NOT EXISTS price_run_publications (
+    run_id            TEXT PRIMARY KEY REFERENCES price_write_runs (id),
+    status            TEXT NOT NULL DEFAULT 'pendente' CHECK (status IN ('pendente', 'publicada')),
+    tentativas        INTEGER NOT NULL DEFAULT 0,
+    proxima_tentativa TIMESTAMPTZ NOT NULL DEFAULT now(),
+    ultimo_erro       TEXT NOT NULL DEFAULT '',
+    publicado_em      TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS price_run_publications_pendentes
+    ON price_run_publications (proxima_tentativa) WHERE status = 'pendente';
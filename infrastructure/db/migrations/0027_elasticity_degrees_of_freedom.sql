@@ -0,0 +1,2 @@
+-- Graus de liberdade da regressão para o intervalo pela t de Student; 0 nas estimativas antigas
+ALTER TABLE elasticity_estimates ADD COLUMN IF NOT EXISTS graus_liberdade INTEGER NOT NULL DEFAULT 0;
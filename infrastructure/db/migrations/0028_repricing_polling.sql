@@ -0,0 +1,48 @@
+-- Recálculo por consulta periódica: o productscmp e o config_params são do ERP e não
+-- recebem gatilhos nem colunas. Remove o que versões anteriores da 0016 criaram neles;
+-- as colunas inserido_em e atualizado_em deixam de ser usadas e podem ser removidas pelo DBA.
+DO $$
+BEGIN
+    IF to_regclass('productscmp') IS NOT NULL THEN
+        DROP TRIGGER IF EXISTS productscmp_repricing ON productscmp;
+    END IF;
+    IF to_regclass('config_params') IS NOT NULL THEN
+        DROP TRIGGER IF EXISTS config_params_repricing ON config_params;
+    END IF;
+END
+$$;
+DROP FUNCTION IF EXISTS notify_repricing_productscmp();
+DROP FUNCTION IF EXISTS notify_repricing_config_params();
+DROP INDEX IF EXISTS productscmp_inserido_em_idx;
+DROP TABLE IF EXISTS repricing_state;
+
+-- Último índice de custo recalculado de cada produto
+CREATE TABLE IF NOT EXISTS repricing_cost_state (
+    produto   TEXT PRIMARY KEY,
+    max_index INTEGER NOT NULL
+);
+
+-- Hash dos parâmetros do config_params usados no último recálculo completo
+CREATE TABLE IF NOT EXISTS repricing_params_state (
+    id   INTEGER PRIMARY KEY CHECK (id = 1),
+    hash TEXT NOT NULL
+);
+
+-- Parte da situação atual, como a marca d'água que substitui: só mudanças posteriores
+-- disparam o recálculo. O hash é o mesmo calculado pelo RepricingRepository.
+DO $$
+BEGIN
+    IF to_regclass('productscmp') IS NOT NULL THEN
+        INSERT INTO repricing_cost_state (produto, max_index)
+        SELECT produto, MAX(max_index) FROM productscmp GROUP BY produto
+        ON CONFLICT DO NOTHING;
+    END IF;
+    IF to_regclass('config_params') IS NOT NULL THEN
+        INSERT INTO repricing_params_state (id, hash)
+        SELECT 1, md5(concat_ws('|', lucro_adicional_desejado, lucro_padrao_desejado, imposto_federal,
+                operacao, custo_fixo, aliquota_pis, aliquota_cofins, rebate, fcp, redutor_padrao))
+        FROM config_params WHERE id = 1
+        ON CONFLICT DO NOTHING;
+    END IF;
+END
+$$;
@@ -0,0 +1,36 @@
+-- Volta a notificar o canal 'repricing' (removido pela 0028): o payload é o produto de
+-- cada novo índice do productscmp, inclusive os gravados pela importação de NF-e, ou '*'
+-- quando o config_params muda. As tabelas repricing_cost_state e repricing_params_state
+-- da 0028 continuam como registro do que já foi recalculado, usado na varredura feita na
+-- conexão e em cada reconexão do ouvinte.
+CREATE OR REPLACE FUNCTION notify_repricing_productscmp() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('repricing', NEW.produto::text);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Não depende da coluna atualizado_em, que a 0028 deixou sem uso
+CREATE OR REPLACE FUNCTION notify_repricing_config_params() RETURNS trigger AS $$
+BEGIN
+    IF NEW IS DISTINCT FROM OLD THEN
+        PERFORM pg_notify('repricing', '*');
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DO $$
+BEGIN
+    IF to_regclass('productscmp') IS NOT NULL THEN
+        DROP TRIGGER IF EXISTS productscmp_repricing ON productscmp;
+        CREATE TRIGGER productscmp_repricing AFTER INSERT ON productscmp
+            FOR EACH ROW EXECUTE PROCEDURE notify_repricing_productscmp();
+    END IF;
+    IF to_regclass('config_params') IS NOT NULL THEN
+        DROP TRIGGER IF EXISTS config_params_repricing ON config_params;
+        CREATE TRIGGER config_params_repricing AFTER UPDATE ON config_params
+            FOR EACH ROW EXECUTE PROCEDURE notify_repricing_config_params();
+    END IF;
+END
+$$;
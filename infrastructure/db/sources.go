@@ -0,0 +1,188 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"calculator/domain/entities"
+	"calculator/domain/repositories"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Tempos do acompanhamento das conexões
+const (
+	// tempoPing é o limite de cada teste de conexão
+	tempoPing = 5 * time.Second
+	// intervaloSaude é o intervalo dos testes com a fonte conectada
+	intervaloSaude = 30 * time.Second
+	// esperaInicial e esperaMaxima limitam o backoff das reconexões
+	esperaInicial = time.Second
+	esperaMaxima  = 2 * time.Minute
+)
+
+// source é uma fonte de dados com a situação da conexão
+type source struct {
+	db     *sql.DB
+	mu     sync.Mutex
+	status entities.SourceStatus
+}
+
+// Sources acompanha as conexões com os bancos e implementa SourceChecker
+type Sources struct {
+	fontes map[string]*source
+}
+
+// NewSources cria o registro de fontes de dados
+func NewSources() *Sources {
+	return &Sources{fontes: map[string]*source{}}
+}
+
+// Open registra a fonte e testa a conexão. Fonte obrigatória fora do ar é erro; a
+// opcional fica indisponível e é reconectada em segundo plano a partir de Start.
+func (ss *Sources) Open(nome string, obrigatoria bool, open func(string) (*sql.DB, error), connStr string) (*sql.DB, error) {
+	db, err := open(connStr)
+	if err != nil {
+		return nil, err
+	}
+	s := &source{db: db, status: entities.SourceStatus{Nome: nome, Obrigatoria: obrigatoria}}
+	if err := s.ping(); err != nil {
+		if obrigatoria {
+			db.Close()
+			return nil, fmt.Errorf("erro ping %s: %w", nome, err)
+		}
+		logrus.WithError(err).WithField("fonte", nome).Warn("Fonte de dados opcional indisponível na inicialização")
+	}
+	ss.fontes[nome] = s
+	return db, nil
+}
+
+// Start testa as fontes periodicamente; as indisponíveis são testadas com backoff
+func (ss *Sources) Start(stop <-chan struct{}) {
+	for _, s := range ss.fontes {
+		go s.monitor(stop)
+	}
+}
+
+// CheckSources devolve ErrUnavailable com o motivo das fontes fora do ar
+func (ss *Sources) CheckSources(nomes ...string) error {
+	var fora []string
+	for _, nome := range nomes {
+		s, ok := ss.fontes[nome]
+		if !ok {
+			fora = append(fora, nome+" não configurado")
+			continue
+		}
+		st := s.snapshot()
+		if !st.Disponivel {
+			fora = append(fora, fmt.Sprintf("%s desde %s (%s)", nome, st.Desde.Format(time.RFC3339), st.UltimoErro))
+		}
+	}
+	if len(fora) > 0 {
+		return fmt.Errorf("%w: %s", repositories.ErrUnavailable, strings.Join(fora, "; "))
+	}
+	return nil
+}
+
+// SourcesStatus devolve a situação de todas as fontes em ordem de nome
+func (ss *Sources) SourcesStatus() []entities.SourceStatus {
+	status := make([]entities.SourceStatus, 0, len(ss.fontes))
+	for _, s := range ss.fontes {
+		status = append(status, s.snapshot())
+	}
+	sort.Slice(status, func(i, j int) bool { return status[i].Nome < status[j].Nome })
+	return status
+}
+
+// snapshot copia a situação atual da fonte
+func (s *source) snapshot() entities.SourceStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.status
+}
+
+// ping testa a conexão com limite de tempo e registra a situação
+func (s *source) ping() error {
+	// O driver pode ignorar o contexto ao discar; o resultado é abandonado após o limite
+	done := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), tempoPing)
+		defer cancel()
+		done <- s.db.PingContext(ctx)
+	}()
+	var err error
+	select {
+	case err = <-done:
+	case <-time.After(tempoPing):
+		err = fmt.Errorf("sem resposta em %s", tempoPing)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	agora := time.Now()
+	if err != nil {
+		if s.status.Disponivel || s.status.Desde.IsZero() {
+			s.status.Desde = agora
+		}
+		s.status.Disponivel = false
+		s.status.UltimoErro = err.Error()
+		s.status.Falhas++
+		return err
+	}
+	if !s.status.Disponivel {
+		s.status.Desde = agora
+	}
+	s.status.Disponivel = true
+	s.status.UltimoErro = ""
+	s.status.Falhas = 0
+	s.status.ProximaTentativa = nil
+	return nil
+}
+
+// monitor repete o teste: a cada intervaloSaude com a fonte conectada e, fora do ar,
+// com espera dobrando de esperaInicial até esperaMaxima
+func (s *source) monitor(stop <-chan struct{}) {
+	espera := esperaInicial
+	for {
+		st := s.snapshot()
+		proximo := intervaloSaude
+		if !st.Disponivel {
+			proximo = espera
+			quando := time.Now().Add(proximo)
+			s.mu.Lock()
+			s.status.ProximaTentativa = &quando
+			s.mu.Unlock()
+		}
+
+		timer := time.NewTimer(proximo)
+		select {
+		case <-stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		estava := st.Disponivel
+		err := s.ping()
+		switch {
+		case err == nil:
+			espera = esperaInicial
+			if !estava {
+				logrus.WithField("fonte", st.Nome).Info("Fonte de dados reconectada")
+			}
+		case estava:
+			espera = esperaInicial
+			logrus.WithError(err).WithField("fonte", st.Nome).Warn("Fonte de dados indisponível, tentando reconectar")
+		default:
+			espera *= 2
+			if espera > esperaMaxima {
+				espera = esperaMaxima
+			}
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package repositories
+
+import (
+	"time"
+
+	"calculator/domain/entities"
+	"calculator/domain/repositories"
+
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+)
+
+// Reconexão e verificação da conexão do LISTEN
+const (
+	listenerMinReconnect = 5 * time.Second
+	listenerMaxReconnect = 2 * time.Minute
+	listenerPing         = 90 * time.Second
+)
+
+// pgChangeListener implementa ChangeListener com LISTEN/NOTIFY do Postgres
+type pgChangeListener struct {
+	connStr string
+}
+
+// NewChangeListener cria o ouvinte; ele abre a própria conexão, fora do pool
+func NewChangeListener(connStr string) repositories.ChangeListener {
+	return &pgChangeListener{connStr: connStr}
+}
+
+// Listen escuta o canal de recálculo em segundo plano. O pq reconecta sozinho e, ao
+// reconectar, envia nil em Notify; isso vira um evento Perdidos para disparar a varredura.
+func (l *pgChangeListener) Listen(stop <-chan struct{}, onChange func(entities.ChangeEvent)) {
+	listener := pq.NewListener(l.connStr, listenerMinReconnect, listenerMaxReconnect,
+		func(ev pq.ListenerEventType, err error) {
+			if err != nil {
+				logrus.WithError(err).Warn("Conexão de notificações do Postgres com problema")
+			}
+		})
+	if err := listener.Listen(entities.CanalRecalculo); err != nil {
+		logrus.WithError(err).Warn("Falha ao escutar o canal de recálculo; tentando novamente na reconexão")
+	}
+
+	go func() {
+		defer listener.Close()
+		// Varredura inicial: cobre as mudanças feitas com o serviço parado
+		onChange(entities.ChangeEvent{Perdidos: true})
+		for {
+			select {
+			case n := <-listener.Notify:
+				if n == nil {
+					onChange(entities.ChangeEvent{Perdidos: true})
+					continue
+				}
+				if n.Extra == entities.TodosProdutos {
+					onChange(entities.ChangeEvent{Todos: true})
+				} else {
+					onChange(entities.ChangeEvent{Produto: n.Extra})
+				}
+			case <-time.After(listenerPing):
+				if err := listener.Ping(); err != nil {
+					logrus.WithError(err).Warn("Ping da conexão de notificações falhou")
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
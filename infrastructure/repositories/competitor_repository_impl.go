@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"calculator/domain/entities"
+	"calculator/domain/repositories"
+)
+
+// competitorRepositoryImpl implementa CompetitorRepository no Postgres
+type competitorRepositoryImpl struct {
+	postgresDB *sql.DB
+}
+
+// NewCompetitorRepository constrói o repositório de preços de concorrentes
+func NewCompetitorRepository(pg *sql.DB) repositories.CompetitorRepository {
+	return &competitorRepositoryImpl{postgresDB: pg}
+}
+
+// SaveCompetitorPrices → grava o histórico; preços antigos são mantidos
+func (r *competitorRepositoryImpl) SaveCompetitorPrices(prices []entities.CompetitorPrice) error {
+	tx, err := r.postgresDB.Begin()
+	if err != nil {
+		return fmt.Errorf("SaveCompetitorPrices begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, p := range prices {
+		_, err := tx.Exec(`INSERT INTO competitor_prices (produto, ean, concorrente, preco, coletado_em)
+				VALUES ($1, NULLIF($2, ''), $3, $4, $5)`,
+			p.Produto, p.Ean, p.Concorrente, p.Preco, p.ColetadoEm)
+		if err != nil {
+			return fmt.Errorf("SaveCompetitorPrices insert: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("SaveCompetitorPrices commit: %w", err)
+	}
+	return nil
+}
+
+// GetLatestCompetitorPrices → última coleta de cada concorrente
+func (r *competitorRepositoryImpl) GetLatestCompetitorPrices(produto string, desde time.Time) ([]entities.CompetitorPrice, error) {
+	q := `SELECT DISTINCT ON (concorrente) concorrente, COALESCE(ean, ''), preco, coletado_em
+			FROM competitor_prices
+			WHERE produto = $1
+			AND coletado_em >= $2
+			ORDER BY concorrente, coletado_em DESC, id DESC`
+	rows, err := r.postgresDB.Query(q, produto, desde)
+	if err != nil {
+		return nil, fmt.Errorf("GetLatestCompetitorPrices query: %w", err)
+	}
+	defer rows.Close()
+
+	var precos []entities.CompetitorPrice
+	for rows.Next() {
+		p := entities.CompetitorPrice{Produto: produto}
+		if err := rows.Scan(&p.Concorrente, &p.Ean, &p.Preco, &p.ColetadoEm); err != nil {
+			return nil, fmt.Errorf("GetLatestCompetitorPrices scan: %w", err)
+		}
+		precos = append(precos, p)
+	}
+	return precos, rows.Err()
+}
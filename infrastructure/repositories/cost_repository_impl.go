@@ -0,0 +1,342 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"calculator/domain/entities"
+	"calculator/domain/repositories"
+
+	"github.com/lib/pq"
+)
+
+// costRepositoryImpl implementa CostRepository no Postgres
+type costRepositoryImpl struct {
+	postgresDB *sql.DB
+}
+
+// NewCostRepository constrói o repositório de custos
+func NewCostRepository(pg *sql.DB) repositories.CostRepository {
+	return &costRepositoryImpl{postgresDB: pg}
+}
+
+// FindProductByCodes → EAN tem prioridade sobre o código do fornecedor
+func (r *costRepositoryImpl) FindProductByCodes(ean, cnpjFornecedor, codigoFornecedor string) (string, error) {
+	q := `SELECT produto FROM product_codes
+			WHERE ($1 <> '' AND ean = $1)
+			   OR (cnpj_fornecedor = $2 AND codigo_fornecedor = $3)
+			ORDER BY (ean = $1) DESC
+			LIMIT 1`
+
+	var produto string
+	err := r.postgresDB.QueryRow(q, ean, cnpjFornecedor, codigoFornecedor).Scan(&produto)
+	if err == sql.ErrNoRows {
+		return "", repositories.ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("FindProductByCodes scan: %w", err)
+	}
+	return produto, nil
+}
+
+// currentCmpSQL busca o índice vigente pelo mesmo critério de max_index usado em GetProductCmpValues
+const currentCmpSQL = `SELECT p.index, p.cmp, p.cmp_nf, p.cmp_icms, p.cmp_pis_cofins, s.quantidade, s.data_indice
+			FROM productscmp p
+			LEFT JOIN cmp_stock s ON s.produto = p.produto AND s.index = p.index
+			WHERE p.produto = $1
+			AND p.index = (
+				SELECT max_index
+				FROM productscmp
+				WHERE produto = $1
+				LIMIT 1
+			)`
+
+// scanCurrentCmp lê o índice vigente com o estoque de cmp_stock; ErrNotFound se o produto não tiver custo
+func scanCurrentCmp(row *sql.Row, produto string) (entities.CmpIndex, error) {
+	c := entities.CmpIndex{Produto: produto}
+	var quantidade sql.NullFloat64
+	var data sql.NullTime
+	err := row.Scan(&c.Index, &c.Cmp, &c.CmpNF, &c.CmpIcms, &c.CmpPisCofins, &quantidade, &data)
+	if err == sql.ErrNoRows {
+		return c, repositories.ErrNotFound
+	}
+	if err != nil {
+		return c, fmt.Errorf("scanCurrentCmp: %w", err)
+	}
+	c.Quantidade = quantidade.Float64
+	c.EstoqueRegistrado = quantidade.Valid
+	c.DataIndice = data.Time
+	return c, nil
+}
+
+// PurchaseImported → verifica a chave da NF-e nas entradas
+func (r *costRepositoryImpl) PurchaseImported(chave string) (bool, error) {
+	var exists bool
+	err := r.postgresDB.QueryRow(`SELECT EXISTS (SELECT 1 FROM purchase_entries WHERE chave_nfe = $1)`, chave).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("PurchaseImported scan: %w", err)
+	}
+	return exists, nil
+}
+
+// SavePurchaseImport → advisory lock por produto (também sem linha no productscmp), em ordem
+// para não haver deadlock; índice vigente lido e novos índices gravados na mesma transação.
+// max_index é atualizado em todas as linhas do produto.
+func (r *costRepositoryImpl) SavePurchaseImport(entries []entities.PurchaseEntry,
+	compute func(atuais map[string]entities.CmpIndex) ([]entities.CmpIndex, error)) ([]entities.CmpIndex, error) {
+
+	tx, err := r.postgresDB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("SavePurchaseImport begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	vistos := map[string]bool{}
+	var produtos []string
+	for _, e := range entries {
+		if !vistos[e.Produto] {
+			vistos[e.Produto] = true
+			produtos = append(produtos, e.Produto)
+		}
+	}
+	sort.Strings(produtos)
+
+	atuais := make(map[string]entities.CmpIndex, len(produtos))
+	for _, p := range produtos {
+		if _, err := tx.Exec(`SELECT pg_advisory_xact_lock(hashtext('productscmp'), hashtext($1))`, p); err != nil {
+			return nil, fmt.Errorf("SavePurchaseImport lock: %w", err)
+		}
+		c, err := scanCurrentCmp(tx.QueryRow(currentCmpSQL, p), p)
+		if errors.Is(err, repositories.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("SavePurchaseImport: %w", err)
+		}
+		atuais[p] = c
+	}
+
+	indices, err := compute(atuais)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range entries {
+		_, err := tx.Exec(`INSERT INTO purchase_entries
+				(chave_nfe, numero_item, produto, fornecedor, data_emissao, quantidade,
+				 custo_nf, credito_icms, credito_pis_cofins, ipi)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+			e.ChaveNfe, e.NumeroItem, e.Produto, e.Fornecedor, e.DataEmissao, e.Quantidade,
+			e.CustoNF, e.CreditoIcms, e.CreditoPisCofins, e.Ipi)
+		if err != nil {
+			return nil, fmt.Errorf("SavePurchaseImport insert entrada: %w", err)
+		}
+	}
+
+	for _, c := range indices {
+		if err := insertCmpIndex(tx, c); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("SavePurchaseImport commit: %w", err)
+	}
+	return indices, nil
+}
+
+// GetCmpHistory → índices em ordem cronológica; índices legados sem data vêm primeiro
+func (r *costRepositoryImpl) GetCmpHistory(produto string, de, ate time.Time) ([]entities.CmpIndex, error) {
+	q := `SELECT p.index, p.cmp, p.cmp_nf, p.cmp_icms, p.cmp_pis_cofins, COALESCE(s.quantidade, 0), s.data_indice
+			FROM productscmp p
+			LEFT JOIN cmp_stock s ON s.produto = p.produto AND s.index = p.index
+			WHERE p.produto = $1
+			AND ($2::timestamptz IS NULL OR s.data_indice >= $2)
+			AND ($3::timestamptz IS NULL OR s.data_indice <= $3)
+			ORDER BY s.data_indice NULLS FIRST, p.index`
+	rows, err := r.postgresDB.Query(q, produto, nullTime(de), nullTime(ate))
+	if err != nil {
+		return nil, fmt.Errorf("GetCmpHistory query: %w", err)
+	}
+	defer rows.Close()
+
+	var hist []entities.CmpIndex
+	for rows.Next() {
+		c := entities.CmpIndex{Produto: produto}
+		var data sql.NullTime
+		if err := rows.Scan(&c.Index, &c.Cmp, &c.CmpNF, &c.CmpIcms, &c.CmpPisCofins, &c.Quantidade, &data); err != nil {
+			return nil, fmt.Errorf("GetCmpHistory scan: %w", err)
+		}
+		c.DataIndice = data.Time
+		hist = append(hist, c)
+	}
+	return hist, rows.Err()
+}
+
+// SumStockMovements → saldo das movimentações no intervalo (desde, ate]
+func (r *costRepositoryImpl) SumStockMovements(produto string, desde, ate time.Time) (float64, error) {
+	q := `SELECT COALESCE(SUM(quantidade), 0)
+			FROM stock_movements
+			WHERE produto = $1
+			AND ($2::timestamptz IS NULL OR data > $2)
+			AND data <= $3`
+	var total float64
+	if err := r.postgresDB.QueryRow(q, produto, nullTime(desde), ate).Scan(&total); err != nil {
+		return 0, fmt.Errorf("SumStockMovements scan: %w", err)
+	}
+	return total, nil
+}
+
+// SaveStockMovements → grava todas as movimentações em uma transação
+func (r *costRepositoryImpl) SaveStockMovements(movs []entities.StockMovement) error {
+	tx, err := r.postgresDB.Begin()
+	if err != nil {
+		return fmt.Errorf("SaveStockMovements begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, m := range movs {
+		_, err := tx.Exec(`INSERT INTO stock_movements (produto, data, tipo, quantidade, referencia)
+				VALUES ($1, $2, $3, $4, $5)`,
+			m.Produto, m.Data, m.Tipo, m.Quantidade, m.Referencia)
+		if err != nil {
+			return fmt.Errorf("SaveStockMovements insert: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("SaveStockMovements commit: %w", err)
+	}
+	return nil
+}
+
+// SaveReplacementCosts → upsert por produto
+func (r *costRepositoryImpl) SaveReplacementCosts(costs []entities.ReplacementCost) error {
+	tx, err := r.postgresDB.Begin()
+	if err != nil {
+		return fmt.Errorf("SaveReplacementCosts begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, c := range costs {
+		_, err := tx.Exec(`INSERT INTO replacement_costs (produto, custo, informado_em, usuario)
+				VALUES ($1, $2, now(), $3)
+				ON CONFLICT (produto) DO UPDATE
+				SET custo = EXCLUDED.custo, informado_em = EXCLUDED.informado_em, usuario = EXCLUDED.usuario`,
+			c.Produto, c.Custo, c.Usuario)
+		if err != nil {
+			return fmt.Errorf("SaveReplacementCosts upsert: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("SaveReplacementCosts commit: %w", err)
+	}
+	return nil
+}
+
+// GetStockPositions → estoque do índice vigente mais as movimentações posteriores.
+// A data de entrada segue o PEPS: percorrendo as compras da mais recente para a mais
+// antiga, é a primeira cujo acumulado cobre o estoque atual. Estoque maior que as
+// compras registradas usa a compra mais antiga ou, sem compras, a data do índice.
+// produtos nil vira NULL no filtro e considera todos.
+func (r *costRepositoryImpl) GetStockPositions(produtos []string) ([]entities.StockPosition, error) {
+	q := `WITH vigente AS (
+				SELECT p.produto, s.quantidade, s.data_indice
+				FROM productscmp p
+				JOIN cmp_stock s ON s.produto = p.produto AND s.index = p.index
+				WHERE p.index = p.max_index
+				AND ($1::text[] IS NULL OR p.produto = ANY($1))
+			), saldo AS (
+				SELECT v.produto, v.data_indice,
+					   v.quantidade + COALESCE((
+						   SELECT SUM(m.quantidade) FROM stock_movements m
+						   WHERE m.produto = v.produto
+						   AND (v.data_indice IS NULL OR m.data > v.data_indice)
+					   ), 0) AS quantidade
+				FROM vigente v
+			), entradas AS (
+				SELECT e.produto, e.data_emissao,
+					   SUM(e.quantidade) OVER (
+						   PARTITION BY e.produto
+						   ORDER BY e.data_emissao DESC, e.numero_item DESC
+					   ) AS acumulado
+				FROM purchase_entries e
+			)
+			SELECT s.produto, s.quantidade,
+				   COALESCE(
+					   (SELECT MAX(en.data_emissao) FROM entradas en
+						WHERE en.produto = s.produto AND en.acumulado >= s.quantidade),
+					   (SELECT MIN(en.data_emissao) FROM entradas en WHERE en.produto = s.produto),
+					   s.data_indice
+				   ) AS data_entrada
+			FROM saldo s
+			WHERE s.quantidade > 0
+			ORDER BY s.produto`
+	rows, err := r.postgresDB.Query(q, pq.Array(produtos))
+	if err != nil {
+		return nil, fmt.Errorf("GetStockPositions query: %w", err)
+	}
+	defer rows.Close()
+
+	var posicoes []entities.StockPosition
+	for rows.Next() {
+		var p entities.StockPosition
+		var data sql.NullTime
+		if err := rows.Scan(&p.Produto, &p.Quantidade, &data); err != nil {
+			return nil, fmt.Errorf("GetStockPositions scan: %w", err)
+		}
+		p.DataEntrada = data.Time
+		posicoes = append(posicoes, p)
+	}
+	return posicoes, rows.Err()
+}
+
+// GetMarkdownRules → regras em ordem crescente de idade
+func (r *costRepositoryImpl) GetMarkdownRules() ([]entities.MarkdownRule, error) {
+	rows, err := r.postgresDB.Query(`SELECT dias_minimos, desconto FROM markdown_rules ORDER BY dias_minimos`)
+	if err != nil {
+		return nil, fmt.Errorf("GetMarkdownRules query: %w", err)
+	}
+	defer rows.Close()
+
+	var regras []entities.MarkdownRule
+	for rows.Next() {
+		var m entities.MarkdownRule
+		if err := rows.Scan(&m.DiasMinimos, &m.Desconto); err != nil {
+			return nil, fmt.Errorf("GetMarkdownRules scan: %w", err)
+		}
+		regras = append(regras, m)
+	}
+	return regras, rows.Err()
+}
+
+// nullTime converte a data zero em NULL nos filtros opcionais
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
+
+// insertCmpIndex grava um novo índice, com o estoque em cmp_stock, e o torna o vigente do produto
+func insertCmpIndex(tx *sql.Tx, c entities.CmpIndex) error {
+	_, err := tx.Exec(`INSERT INTO productscmp
+			(produto, index, max_index, cmp, cmp_nf, cmp_icms, cmp_pis_cofins)
+			VALUES ($1, $2, $2, $3, $4, $5, $6)`,
+		c.Produto, c.Index, c.Cmp, c.CmpNF, c.CmpIcms, c.CmpPisCofins)
+	if err != nil {
+		return fmt.Errorf("insertCmpIndex insert: %w", err)
+	}
+	_, err = tx.Exec(`INSERT INTO cmp_stock (produto, index, quantidade, data_indice)
+			VALUES ($1, $2, $3, $4)`,
+		c.Produto, c.Index, c.Quantidade, c.DataIndice)
+	if err != nil {
+		return fmt.Errorf("insertCmpIndex insert estoque: %w", err)
+	}
+	_, err = tx.Exec(`UPDATE productscmp SET max_index = $2 WHERE produto = $1`, c.Produto, c.Index)
+	if err != nil {
+		return fmt.Errorf("insertCmpIndex update max_index: %w", err)
+	}
+	return nil
+}
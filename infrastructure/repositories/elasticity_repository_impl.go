@@ -0,0 +1,66 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+
+	"calculator/domain/entities"
+	"calculator/domain/repositories"
+)
+
+// elasticityRepositoryImpl implementa ElasticityRepository no Postgres
+type elasticityRepositoryImpl struct {
+	postgresDB *sql.DB
+}
+
+// NewElasticityRepository constrói o repositório de elasticidades
+func NewElasticityRepository(pg *sql.DB) repositories.ElasticityRepository {
+	return &elasticityRepositoryImpl{postgresDB: pg}
+}
+
+// SaveElasticityEstimates → upsert por escopo e chave
+func (r *elasticityRepositoryImpl) SaveElasticityEstimates(estimates []entities.ElasticityEstimate) error {
+	tx, err := r.postgresDB.Begin()
+	if err != nil {
+		return fmt.Errorf("SaveElasticityEstimates begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, e := range estimates {
+		_, err := tx.Exec(`INSERT INTO elasticity_estimates (escopo, chave, elasticidade, erro_padrao, r2,
+					observacoes, graus_liberdade, confianca, periodo_de, periodo_ate, calculado_em)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+				ON CONFLICT (escopo, chave) DO UPDATE
+				SET elasticidade = EXCLUDED.elasticidade, erro_padrao = EXCLUDED.erro_padrao, r2 = EXCLUDED.r2,
+					observacoes = EXCLUDED.observacoes, graus_liberdade = EXCLUDED.graus_liberdade, confianca = EXCLUDED.confianca,
+					periodo_de = EXCLUDED.periodo_de, periodo_ate = EXCLUDED.periodo_ate,
+					calculado_em = EXCLUDED.calculado_em`,
+			e.Escopo, e.Chave, e.Elasticidade, e.ErroPadrao, e.R2, e.Observacoes, e.GrausLiberdade, e.Confianca, e.De, e.Ate, e.CalculadoEm)
+		if err != nil {
+			return fmt.Errorf("SaveElasticityEstimates upsert: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("SaveElasticityEstimates commit: %w", err)
+	}
+	return nil
+}
+
+// GetElasticityEstimate → estimativa gravada; o intervalo é refeito a partir do erro padrão
+func (r *elasticityRepositoryImpl) GetElasticityEstimate(escopo, chave string) (entities.ElasticityEstimate, error) {
+	e := entities.ElasticityEstimate{Escopo: escopo, Chave: chave}
+	err := r.postgresDB.QueryRow(`SELECT elasticidade, erro_padrao, r2, observacoes, graus_liberdade, confianca,
+				periodo_de, periodo_ate, calculado_em
+			FROM elasticity_estimates WHERE escopo = $1 AND chave = $2`, escopo, chave).
+		Scan(&e.Elasticidade, &e.ErroPadrao, &e.R2, &e.Observacoes, &e.GrausLiberdade, &e.Confianca,
+			&e.De, &e.Ate, &e.CalculadoEm)
+	if err == sql.ErrNoRows {
+		return e, repositories.ErrNotFound
+	}
+	if err != nil {
+		return e, fmt.Errorf("GetElasticityEstimate scan: %w", err)
+	}
+	e.CalcularIntervalo()
+	return e, nil
+}
@@ -0,0 +1,227 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"calculator/domain/entities"
+	"calculator/domain/repositories"
+	"calculator/internal/firebird"
+)
+
+// Consulta dos preços do Millennium
+const (
+	// erpSkuSuffix é o sufixo da grade padrão usado em np_comissao_frete
+	erpSkuSuffix = "_0_0_U"
+	// erpInBatch fica abaixo do limite de 1500 itens do IN no Firebird
+	erpInBatch = 500
+)
+
+// identificadorSQL aceita só nomes simples, já que a tabela entra no texto da consulta
+var identificadorSQL = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_$]*$`)
+
+// ErpPriceTable é a tabela de preços do ERP, com um preço por produto e tabela de preço
+// (U02, U03, ...). Os nomes vêm da configuração (ERP_PRICE_*).
+type ErpPriceTable struct {
+	Nome          string
+	ColunaProduto string
+	ColunaTabela  string
+	ColunaPreco   string
+}
+
+// Validar recusa nomes vazios ou que não sejam identificadores simples e confere no
+// catálogo do Firebird (RDB$RELATION_FIELDS) se a tabela e as colunas existem. Sem
+// conexão com o Firebird devolve ErrUnavailable: a conferência fica para o primeiro uso.
+func (t ErpPriceTable) Validar(fb *sql.DB) error {
+	colunas := map[string]string{
+		"coluna do produto": t.ColunaProduto, "coluna da tabela de preço": t.ColunaTabela, "coluna do preço": t.ColunaPreco,
+	}
+	if !identificadorSQL.MatchString(t.Nome) {
+		return fmt.Errorf("tabela de preços do ERP: tabela inválida: %q", t.Nome)
+	}
+	for campo, nome := range colunas {
+		if !identificadorSQL.MatchString(nome) {
+			return fmt.Errorf("tabela de preços do ERP: %s inválida: %q", campo, nome)
+		}
+	}
+
+	// Nomes sem aspas ficam em maiúsculas no catálogo
+	rows, err := fb.Query(`SELECT TRIM(RDB$FIELD_NAME) FROM RDB$RELATION_FIELDS
+			WHERE TRIM(RDB$RELATION_NAME) = ?`, strings.ToUpper(t.Nome))
+	if err != nil {
+		return erpCatalogError(err)
+	}
+	defer rows.Close()
+	existentes := map[string]bool{}
+	for rows.Next() {
+		var nome string
+		if err := rows.Scan(&nome); err != nil {
+			return erpCatalogError(err)
+		}
+		existentes[nome] = true
+	}
+	if err := rows.Err(); err != nil {
+		return erpCatalogError(err)
+	}
+
+	if len(existentes) == 0 {
+		return fmt.Errorf("tabela de preços do ERP: tabela %s não existe no Firebird (ERP_PRICE_TABLE)", t.Nome)
+	}
+	var faltando []string
+	for campo, nome := range colunas {
+		if !existentes[strings.ToUpper(nome)] {
+			faltando = append(faltando, fmt.Sprintf("%s %s", campo, nome))
+		}
+	}
+	if len(faltando) > 0 {
+		sort.Strings(faltando)
+		return fmt.Errorf("tabela de preços do ERP: %s sem %s (ERP_PRICE_COL_*)", t.Nome, strings.Join(faltando, ", "))
+	}
+	return nil
+}
+
+// erpCatalogError separa a falta de conexão, que adia a conferência, dos demais erros
+func erpCatalogError(err error) error {
+	if firebird.IsConnectionError(err) {
+		return fmt.Errorf("%w: tabela de preços do ERP não conferida: %v", repositories.ErrUnavailable, err)
+	}
+	return fmt.Errorf("tabela de preços do ERP: consulta ao catálogo: %w", err)
+}
+
+// erpPriceRepositoryImpl implementa ErpPriceRepository no Firebird
+type erpPriceRepositoryImpl struct {
+	firebirdDB *sql.DB
+	tabela     ErpPriceTable
+	// conferida indica que Validar passou contra o catálogo do Firebird
+	mu        sync.Mutex
+	conferida bool
+}
+
+// NewErpPriceRepository constrói o repositório de preços do ERP. A tabela é conferida
+// no catálogo antes do primeiro uso; nenhuma leitura ou gravação roda sem essa conferência.
+func NewErpPriceRepository(fb *sql.DB, t ErpPriceTable) repositories.ErpPriceRepository {
+	return &erpPriceRepositoryImpl{firebirdDB: fb, tabela: t}
+}
+
+// conferir valida a tabela uma única vez; falha de conexão é tentada de novo no próximo uso
+func (r *erpPriceRepositoryImpl) conferir() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.conferida {
+		return nil
+	}
+	if err := r.tabela.Validar(r.firebirdDB); err != nil {
+		return err
+	}
+	r.conferida = true
+	return nil
+}
+
+// GetErpPrices → preço da tabela por produto, com o SKU e o departamento do cadastro
+func (r *erpPriceRepositoryImpl) GetErpPrices(filter entities.PriceDiffFilter) ([]entities.ErpPrice, error) {
+	if err := r.conferir(); err != nil {
+		return nil, err
+	}
+	if len(filter.Skus) <= erpInBatch {
+		return r.queryErpPrices(filter)
+	}
+	var precos []entities.ErpPrice
+	skus := filter.Skus
+	for len(skus) > 0 {
+		n := erpInBatch
+		if len(skus) < n {
+			n = len(skus)
+		}
+		filter.Skus = skus[:n]
+		parte, err := r.queryErpPrices(filter)
+		if err != nil {
+			return nil, err
+		}
+		precos = append(precos, parte...)
+		skus = skus[n:]
+	}
+	return precos, nil
+}
+
+// queryErpPrices executa a consulta para até erpInBatch SKUs
+func (r *erpPriceRepositoryImpl) queryErpPrices(filter entities.PriceDiffFilter) ([]entities.ErpPrice, error) {
+	q := fmt.Sprintf(`SELECT n.sku, p.produto, p.departamento, t.%[2]s
+			FROM %[1]s t
+			JOIN produtos p ON p.produto = t.%[4]s
+			JOIN np_comissao_frete n ON n.cod_produto = p.cod_produto
+			WHERE t.%[3]s = ?`, r.tabela.Nome, r.tabela.ColunaPreco, r.tabela.ColunaTabela, r.tabela.ColunaProduto)
+	args := []interface{}{filter.Tabela}
+	if filter.Departamento != 0 {
+		q += ` AND p.departamento = ?`
+		args = append(args, filter.Departamento)
+	}
+	if len(filter.Skus) > 0 {
+		q += ` AND n.sku IN (?` + strings.Repeat(", ?", len(filter.Skus)-1) + `)`
+		for _, sku := range filter.Skus {
+			args = append(args, sku+erpSkuSuffix)
+		}
+	}
+	q += ` ORDER BY n.sku`
+
+	rows, err := r.firebirdDB.Query(q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("GetErpPrices query: %w", err)
+	}
+	defer rows.Close()
+
+	var precos []entities.ErpPrice
+	for rows.Next() {
+		p := entities.ErpPrice{Tabela: filter.Tabela}
+		if err := rows.Scan(&p.Sku, &p.Produto, &p.Departamento, &p.Preco); err != nil {
+			return nil, fmt.Errorf("GetErpPrices scan: %w", err)
+		}
+		// Só a grade padrão corresponde ao SKU usado no cálculo
+		sku := strings.TrimSpace(p.Sku)
+		if !strings.HasSuffix(sku, erpSkuSuffix) {
+			continue
+		}
+		p.Sku = strings.TrimSuffix(sku, erpSkuSuffix)
+		precos = append(precos, p)
+	}
+	return precos, rows.Err()
+}
+
+// ApplyErpPrices → UPDATE condicionado ao preço anterior, tudo ou nada. Falha no commit
+// é ErrIndeterminate: o Firebird pode ter gravado antes de a conexão cair.
+func (r *erpPriceRepositoryImpl) ApplyErpPrices(changes []entities.PriceWriteChange) error {
+	if err := r.conferir(); err != nil {
+		return err
+	}
+	tx, err := r.firebirdDB.Begin()
+	if err != nil {
+		return fmt.Errorf("ApplyErpPrices begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	q := fmt.Sprintf(`UPDATE %[1]s SET %[2]s = ?
+			WHERE %[3]s = ? AND %[4]s = ? AND ABS(%[2]s - ?) < 0.005`,
+		r.tabela.Nome, r.tabela.ColunaPreco, r.tabela.ColunaTabela, r.tabela.ColunaProduto)
+	for _, c := range changes {
+		res, err := tx.Exec(q, c.PrecoNovo, c.Tabela, c.Produto, c.PrecoAnterior)
+		if err != nil {
+			return fmt.Errorf("ApplyErpPrices update %s: %w", c.Sku, err)
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("ApplyErpPrices rows %s: %w", c.Sku, err)
+		}
+		if n != 1 {
+			return fmt.Errorf("%w: preço do SKU %s na tabela %s não é mais %.2f",
+				repositories.ErrConflict, c.Sku, c.Tabela, c.PrecoAnterior)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%w: ApplyErpPrices commit: %v", repositories.ErrIndeterminate, err)
+	}
+	return nil
+}
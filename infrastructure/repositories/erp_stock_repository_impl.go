@@ -0,0 +1,35 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+
+	"calculator/domain/repositories"
+)
+
+// erpStockRepositoryImpl implementa ErpStockRepository no Firebird com a consulta configurada
+type erpStockRepositoryImpl struct {
+	firebirdDB *sql.DB
+	sources    repositories.SourceChecker
+	query      string
+}
+
+// NewErpStockRepository usa a consulta de estoque do ERP (ERP_STOCK_QUERY), que recebe o
+// produto como único parâmetro e devolve a quantidade em estoque
+func NewErpStockRepository(fb *sql.DB, sc repositories.SourceChecker, query string) repositories.ErpStockRepository {
+	return &erpStockRepositoryImpl{firebirdDB: fb, sources: sc, query: query}
+}
+
+// GetErpStock → produto sem linha no ERP tem estoque zero
+func (r *erpStockRepositoryImpl) GetErpStock(produto string) (float64, error) {
+	if err := r.sources.CheckSources(repositories.FonteFirebird); err != nil {
+		return 0, fmt.Errorf("GetErpStock: %w", err)
+	}
+
+	var quantidade sql.NullFloat64
+	err := r.firebirdDB.QueryRow(r.query, produto).Scan(&quantidade)
+	if err != nil && err != sql.ErrNoRows {
+		return 0, fmt.Errorf("GetErpStock scan: %w", err)
+	}
+	return quantidade.Float64, nil
+}
@@ -0,0 +1,153 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"calculator/domain/entities"
+	"calculator/domain/repositories"
+	"calculator/internal/firebird"
+
+	"github.com/lib/pq"
+)
+
+// firebirdCacheRepositoryImpl implementa FirebirdCacheRepository
+type firebirdCacheRepositoryImpl struct {
+	postgresDB     *sql.DB
+	firebirdDB     *sql.DB
+	productService *firebird.ProductService
+}
+
+// NewFirebirdCacheRepository lê do Firebird e guarda o cache no Postgres
+func NewFirebirdCacheRepository(pg *sql.DB, fb *sql.DB, ps *firebird.ProductService) repositories.FirebirdCacheRepository {
+	return &firebirdCacheRepositoryImpl{postgresDB: pg, firebirdDB: fb, productService: ps}
+}
+
+// RefreshFirebirdCache copia as leituras para tabelas temporárias e atualiza o cache em uma
+// transação; produtos que saíram do Firebird ficam com a leitura anterior
+func (r *firebirdCacheRepositoryImpl) RefreshFirebirdCache() (entities.FirebirdCacheInfo, error) {
+	inicio := time.Now()
+	custos, err := loadCostFireMap(r.firebirdDB)
+	if err != nil {
+		return entities.FirebirdCacheInfo{}, fmt.Errorf("RefreshFirebirdCache %w", err)
+	}
+	perfis, err := r.productService.LoadPerfisFiscais()
+	if err != nil {
+		return entities.FirebirdCacheInfo{}, fmt.Errorf("RefreshFirebirdCache: %w", err)
+	}
+
+	tx, err := r.postgresDB.Begin()
+	if err != nil {
+		return entities.FirebirdCacheInfo{}, fmt.Errorf("RefreshFirebirdCache begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`CREATE TEMP TABLE cost_fire_novo (LIKE cost_fire_cache) ON COMMIT DROP;
+		CREATE TEMP TABLE tax_profile_novo (LIKE tax_profile_cache) ON COMMIT DROP`)
+	if err != nil {
+		return entities.FirebirdCacheInfo{}, fmt.Errorf("RefreshFirebirdCache temp: %w", err)
+	}
+
+	stmt, err := tx.Prepare(pq.CopyIn("cost_fire_novo", "produto", "sku_fire", "cod_produto", "departamento",
+		"comissao", "frete", "atualizado_em"))
+	if err != nil {
+		return entities.FirebirdCacheInfo{}, fmt.Errorf("RefreshFirebirdCache copy custos: %w", err)
+	}
+	for produto, cf := range custos {
+		if _, err := stmt.Exec(produto, cf.Sku, cf.Cod_produto, cf.Departamento, cf.Comissao, cf.Frete, inicio); err != nil {
+			stmt.Close()
+			return entities.FirebirdCacheInfo{}, fmt.Errorf("RefreshFirebirdCache copy custo: %w", err)
+		}
+	}
+	if err := closeCopy(stmt); err != nil {
+		return entities.FirebirdCacheInfo{}, fmt.Errorf("RefreshFirebirdCache copy custos: %w", err)
+	}
+
+	stmt, err = tx.Prepare(pq.CopyIn("tax_profile_novo", "produto", "reducao_icms", "origem_prod", "atualizado_em"))
+	if err != nil {
+		return entities.FirebirdCacheInfo{}, fmt.Errorf("RefreshFirebirdCache copy perfis: %w", err)
+	}
+	for _, p := range perfis {
+		reducao := sql.NullFloat64{Float64: p.ReducaoIcms, Valid: p.TemReducao}
+		if _, err := stmt.Exec(p.Produto, reducao, p.OrigemProd, inicio); err != nil {
+			stmt.Close()
+			return entities.FirebirdCacheInfo{}, fmt.Errorf("RefreshFirebirdCache copy perfil: %w", err)
+		}
+	}
+	if err := closeCopy(stmt); err != nil {
+		return entities.FirebirdCacheInfo{}, fmt.Errorf("RefreshFirebirdCache copy perfis: %w", err)
+	}
+
+	_, err = tx.Exec(`INSERT INTO cost_fire_cache SELECT * FROM cost_fire_novo
+			ON CONFLICT (produto) DO UPDATE SET sku_fire = EXCLUDED.sku_fire, cod_produto = EXCLUDED.cod_produto,
+				departamento = EXCLUDED.departamento, comissao = EXCLUDED.comissao, frete = EXCLUDED.frete,
+				atualizado_em = EXCLUDED.atualizado_em`)
+	if err != nil {
+		return entities.FirebirdCacheInfo{}, fmt.Errorf("RefreshFirebirdCache upsert custos: %w", err)
+	}
+	_, err = tx.Exec(`INSERT INTO tax_profile_cache SELECT * FROM tax_profile_novo
+			ON CONFLICT (produto) DO UPDATE SET reducao_icms = EXCLUDED.reducao_icms,
+				origem_prod = EXCLUDED.origem_prod, atualizado_em = EXCLUDED.atualizado_em`)
+	if err != nil {
+		return entities.FirebirdCacheInfo{}, fmt.Errorf("RefreshFirebirdCache upsert perfis: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return entities.FirebirdCacheInfo{}, fmt.Errorf("RefreshFirebirdCache commit: %w", err)
+	}
+
+	return entities.FirebirdCacheInfo{
+		AtualizadoEm:  inicio,
+		DuracaoMs:     time.Since(inicio).Milliseconds(),
+		CustosFire:    len(custos),
+		PerfisFiscais: len(perfis),
+	}, nil
+}
+
+// closeCopy envia as linhas pendentes do COPY e fecha o comando
+func closeCopy(stmt *sql.Stmt) error {
+	if _, err := stmt.Exec(); err != nil {
+		stmt.Close()
+		return err
+	}
+	return stmt.Close()
+}
+
+// GetCachedCostFire → última leitura do np_comissao_frete do produto
+func (r *firebirdCacheRepositoryImpl) GetCachedCostFire(sku string) (entities.CostFire, time.Time, error) {
+	q := `SELECT sku_fire, cod_produto, departamento, comissao, frete, atualizado_em
+			FROM cost_fire_cache
+			WHERE produto = $1`
+
+	var cf entities.CostFire
+	var em time.Time
+	err := r.postgresDB.QueryRow(q, sku).Scan(&cf.Sku, &cf.Cod_produto, &cf.Departamento, &cf.Comissao, &cf.Frete, &em)
+	if err == sql.ErrNoRows {
+		return cf, em, repositories.ErrNotFound
+	}
+	if err != nil {
+		return cf, em, fmt.Errorf("GetCachedCostFire scan: %w", err)
+	}
+	return cf, em, nil
+}
+
+// GetCachedPerfilFiscal → última leitura do perfil fiscal do produto
+func (r *firebirdCacheRepositoryImpl) GetCachedPerfilFiscal(produto int) (entities.PerfilFiscal, time.Time, error) {
+	q := `SELECT reducao_icms, origem_prod, atualizado_em
+			FROM tax_profile_cache
+			WHERE produto = $1`
+
+	p := entities.PerfilFiscal{Produto: produto}
+	var reducao sql.NullFloat64
+	var em time.Time
+	err := r.postgresDB.QueryRow(q, produto).Scan(&reducao, &p.OrigemProd, &em)
+	if err == sql.ErrNoRows {
+		return p, em, repositories.ErrNotFound
+	}
+	if err != nil {
+		return p, em, fmt.Errorf("GetCachedPerfilFiscal scan: %w", err)
+	}
+	p.ReducaoIcms = reducao.Float64
+	p.TemReducao = reducao.Valid
+	return p, em, nil
+}
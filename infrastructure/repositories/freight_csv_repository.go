@@ -0,0 +1,82 @@
+package repositories
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"calculator/domain/entities"
+	"calculator/domain/repositories"
+	"calculator/internal/csvutil"
+)
+
+// Arquivos esperados no diretório de tabelas de frete
+const (
+	arquivoFaixasFrete = "transportadoras.csv"
+	arquivoSubsidios   = "frete_subsidiado.csv"
+)
+
+// freightCSVRepository carrega as tabelas de frete de arquivos CSV (separador ";" ou ",")
+type freightCSVRepository struct {
+	dir string
+}
+
+// NewFreightCSVRepository constrói o repositório de frete baseado em arquivos
+func NewFreightCSVRepository(dir string) repositories.FreightRepository {
+	return &freightCSVRepository{dir: dir}
+}
+
+// LoadFreightTables lê transportadoras.csv e, se existir, frete_subsidiado.csv
+//
+// transportadoras.csv: transportadora;uf;cep_inicio;cep_fim;peso_min_kg;peso_max_kg;valor;valor_kg_excedente;fator_cubagem
+// frete_subsidiado.csv: canal;preco_minimo;percentual_vendedor;percentual_vendedor_abaixo;limite_vendedor
+func (r *freightCSVRepository) LoadFreightTables() (entities.FreightTables, error) {
+	t := entities.FreightTables{
+		Subsidios:   map[string]entities.RegraFreteSubsidiado{},
+		Fonte:       "csv:" + r.dir,
+		CarregadoEm: time.Now(),
+	}
+
+	err := csvutil.ReadFile(filepath.Join(r.dir, arquivoFaixasFrete), func(row *csvutil.Row) error {
+		f := entities.FaixaFrete{
+			Transportadora:   row.Str("transportadora"),
+			UF:               strings.ToUpper(row.Str("uf")),
+			CepInicio:        entities.NormalizarCep(row.Str("cep_inicio")),
+			CepFim:           entities.NormalizarCep(row.Str("cep_fim")),
+			PesoMinKg:        row.Num("peso_min_kg"),
+			PesoMaxKg:        row.Num("peso_max_kg"),
+			Valor:            row.Num("valor"),
+			ValorKgExcedente: row.Num("valor_kg_excedente"),
+			FatorCubagem:     row.Num("fator_cubagem"),
+		}
+		if err := row.Err(); err != nil {
+			return err
+		}
+		t.Faixas = append(t.Faixas, f)
+		return nil
+	})
+	if err != nil {
+		return t, err
+	}
+
+	err = csvutil.ReadFile(filepath.Join(r.dir, arquivoSubsidios), func(row *csvutil.Row) error {
+		s := entities.RegraFreteSubsidiado{
+			Canal:                    row.Str("canal"),
+			PrecoMinimo:              row.Num("preco_minimo"),
+			PercentualVendedor:       row.Num("percentual_vendedor"),
+			PercentualVendedorAbaixo: row.Num("percentual_vendedor_abaixo"),
+			LimiteVendedor:           row.Num("limite_vendedor"),
+		}
+		if err := row.Err(); err != nil {
+			return err
+		}
+		t.Subsidios[s.Canal] = s
+		return nil
+	})
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return t, err
+	}
+	return t, nil
+}
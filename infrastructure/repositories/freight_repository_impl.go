@@ -0,0 +1,63 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"calculator/domain/entities"
+	"calculator/domain/repositories"
+)
+
+// freightRepositoryImpl carrega as tabelas de frete do Postgres
+type freightRepositoryImpl struct {
+	postgresDB *sql.DB
+}
+
+// NewFreightRepository constrói o repositório de frete baseado em tabelas
+func NewFreightRepository(pg *sql.DB) repositories.FreightRepository {
+	return &freightRepositoryImpl{postgresDB: pg}
+}
+
+// LoadFreightTables → freight_rates + freight_subsidy_rules
+func (r *freightRepositoryImpl) LoadFreightTables() (entities.FreightTables, error) {
+	t := entities.FreightTables{
+		Subsidios:   map[string]entities.RegraFreteSubsidiado{},
+		Fonte:       "postgres",
+		CarregadoEm: time.Now(),
+	}
+
+	rows, err := r.postgresDB.Query(`SELECT transportadora, COALESCE(uf, ''), COALESCE(cep_inicio, 0), COALESCE(cep_fim, 0),
+				peso_min_kg, COALESCE(peso_max_kg, 0), valor, valor_kg_excedente, fator_cubagem
+			FROM freight_rates`)
+	if err != nil {
+		return t, fmt.Errorf("LoadFreightTables query faixas: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var f entities.FaixaFrete
+		if err := rows.Scan(&f.Transportadora, &f.UF, &f.CepInicio, &f.CepFim, &f.PesoMinKg, &f.PesoMaxKg,
+			&f.Valor, &f.ValorKgExcedente, &f.FatorCubagem); err != nil {
+			return t, fmt.Errorf("LoadFreightTables scan faixa: %w", err)
+		}
+		t.Faixas = append(t.Faixas, f)
+	}
+	if err := rows.Err(); err != nil {
+		return t, fmt.Errorf("LoadFreightTables faixas: %w", err)
+	}
+
+	regras, err := r.postgresDB.Query(`SELECT canal, preco_minimo, percentual_vendedor, percentual_vendedor_abaixo, limite_vendedor
+			FROM freight_subsidy_rules`)
+	if err != nil {
+		return t, fmt.Errorf("LoadFreightTables query subsídios: %w", err)
+	}
+	defer regras.Close()
+	for regras.Next() {
+		var s entities.RegraFreteSubsidiado
+		if err := regras.Scan(&s.Canal, &s.PrecoMinimo, &s.PercentualVendedor, &s.PercentualVendedorAbaixo, &s.LimiteVendedor); err != nil {
+			return t, fmt.Errorf("LoadFreightTables scan subsídio: %w", err)
+		}
+		t.Subsidios[s.Canal] = s
+	}
+	return t, regras.Err()
+}
@@ -0,0 +1,37 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+
+	"calculator/domain/entities"
+	"calculator/domain/repositories"
+
+	"github.com/lib/pq"
+)
+
+// identityRepositoryImpl implementa IdentityRepository no Postgres
+type identityRepositoryImpl struct {
+	postgresDB *sql.DB
+}
+
+// NewIdentityRepository constrói o repositório de usuários da API
+func NewIdentityRepository(pg *sql.DB) repositories.IdentityRepository {
+	return &identityRepositoryImpl{postgresDB: pg}
+}
+
+// GetIdentityByTokenHash → usuário ativo com os papéis
+func (r *identityRepositoryImpl) GetIdentityByTokenHash(hash string) (entities.Identidade, error) {
+	var id entities.Identidade
+	var papeis pq.StringArray
+	err := r.postgresDB.QueryRow(`SELECT usuario, papeis FROM api_users WHERE token_hash = $1 AND ativo`, hash).
+		Scan(&id.Usuario, &papeis)
+	if err == sql.ErrNoRows {
+		return id, repositories.ErrNotFound
+	}
+	if err != nil {
+		return id, fmt.Errorf("GetIdentityByTokenHash scan: %w", err)
+	}
+	id.Papeis = papeis
+	return id, nil
+}
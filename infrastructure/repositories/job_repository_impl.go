@@ -0,0 +1,328 @@
+package repositories
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"calculator/domain/entities"
+	"calculator/domain/repositories"
+
+	"github.com/lib/pq"
+)
+
+// jobRepositoryImpl implementa JobRepository no Postgres
+type jobRepositoryImpl struct {
+	postgresDB *sql.DB
+}
+
+// NewJobRepository constrói o repositório de jobs
+func NewJobRepository(pg *sql.DB) repositories.JobRepository {
+	return &jobRepositoryImpl{postgresDB: pg}
+}
+
+const jobColumns = `id, tipo, status, usuario, parametros, total, processados, erros, criado_em,
+			iniciado_em, concluido_em, retomado_em, processados_na_retomada, erro`
+
+// CreateJob → job e itens em uma transação; os itens entram por COPY. No relatório a
+// coluna parametros guarda o filtro.
+func (r *jobRepositoryImpl) CreateJob(job entities.PriceJob, skus []string) error {
+	parametros := []byte(job.Filtro)
+	if job.Tipo == entities.JobPreco {
+		var err error
+		if parametros, err = json.Marshal(job.Parametros); err != nil {
+			return fmt.Errorf("CreateJob marshal: %w", err)
+		}
+	}
+
+	tx, err := r.postgresDB.Begin()
+	if err != nil {
+		return fmt.Errorf("CreateJob begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`INSERT INTO price_jobs (id, tipo, status, usuario, parametros, total, criado_em)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		job.ID, job.Tipo, job.Status, job.Usuario, parametros, job.Total, job.CriadoEm)
+	if err != nil {
+		return fmt.Errorf("CreateJob insert: %w", err)
+	}
+	if len(skus) == 0 {
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("CreateJob commit: %w", err)
+		}
+		return nil
+	}
+
+	stmt, err := tx.Prepare(pq.CopyIn("price_job_items", "job_id", "posicao", "sku"))
+	if err != nil {
+		return fmt.Errorf("CreateJob copy: %w", err)
+	}
+	for i, sku := range skus {
+		if _, err := stmt.Exec(job.ID, i, sku); err != nil {
+			stmt.Close()
+			return fmt.Errorf("CreateJob copy item: %w", err)
+		}
+	}
+	if _, err := stmt.Exec(); err != nil {
+		stmt.Close()
+		return fmt.Errorf("CreateJob copy flush: %w", err)
+	}
+	if err := stmt.Close(); err != nil {
+		return fmt.Errorf("CreateJob copy close: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("CreateJob commit: %w", err)
+	}
+	return nil
+}
+
+// GetJob → job e os primeiros itens com erro
+func (r *jobRepositoryImpl) GetJob(id string, limiteErros int) (entities.PriceJob, error) {
+	job, err := scanJob(r.postgresDB.QueryRow(`SELECT `+jobColumns+` FROM price_jobs WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return job, repositories.ErrNotFound
+	}
+	if err != nil {
+		return job, fmt.Errorf("GetJob scan: %w", err)
+	}
+	if job.Erros == 0 {
+		return job, nil
+	}
+
+	rows, err := r.postgresDB.Query(`SELECT sku, erro FROM price_job_items
+			WHERE job_id = $1 AND status = 'erro' ORDER BY posicao LIMIT $2`, id, limiteErros)
+	if err != nil {
+		return job, fmt.Errorf("GetJob query errors: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var e entities.PriceJobError
+		if err := rows.Scan(&e.Sku, &e.Erro); err != nil {
+			return job, fmt.Errorf("GetJob scan error: %w", err)
+		}
+		job.PrimeirosErros = append(job.PrimeirosErros, e)
+	}
+	return job, rows.Err()
+}
+
+// ClaimJob → SKIP LOCKED; retomado_em marca o início da execução atual
+func (r *jobRepositoryImpl) ClaimJob(reserva time.Duration) (entities.PriceJob, bool, error) {
+	job, err := scanJob(r.postgresDB.QueryRow(`UPDATE price_jobs SET status = 'executando',
+				iniciado_em = COALESCE(iniciado_em, now()), retomado_em = now(),
+				processados_na_retomada = processados, reservado_ate = now() + $1 * interval '1 second'
+			WHERE id = (
+				SELECT id FROM price_jobs
+				WHERE status = 'pendente' OR (status = 'executando' AND reservado_ate < now())
+				ORDER BY criado_em
+				LIMIT 1 FOR UPDATE SKIP LOCKED
+			)
+			RETURNING `+jobColumns, reserva.Seconds()))
+	if err == sql.ErrNoRows {
+		return job, false, nil
+	}
+	if err != nil {
+		return job, false, fmt.Errorf("ClaimJob scan: %w", err)
+	}
+	return job, true, nil
+}
+
+// GetPendingItems → próximos itens pendentes
+func (r *jobRepositoryImpl) GetPendingItems(jobID string, limite int) ([]entities.PriceJobItem, error) {
+	rows, err := r.postgresDB.Query(`SELECT posicao, sku FROM price_job_items
+			WHERE job_id = $1 AND status = 'pendente' ORDER BY posicao LIMIT $2`, jobID, limite)
+	if err != nil {
+		return nil, fmt.Errorf("GetPendingItems query: %w", err)
+	}
+	defer rows.Close()
+
+	var out []entities.PriceJobItem
+	for rows.Next() {
+		it := entities.PriceJobItem{Status: entities.ItemPendente}
+		if err := rows.Scan(&it.Posicao, &it.Sku); err != nil {
+			return nil, fmt.Errorf("GetPendingItems scan: %w", err)
+		}
+		out = append(out, it)
+	}
+	return out, rows.Err()
+}
+
+// SaveItemResults → itens e contadores na mesma transação; itens já gravados não contam de novo
+func (r *jobRepositoryImpl) SaveItemResults(jobID string, itens []entities.PriceJobItem, reserva time.Duration) (string, error) {
+	tx, err := r.postgresDB.Begin()
+	if err != nil {
+		return "", fmt.Errorf("SaveItemResults begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	var ok, erros int64
+	for _, it := range itens {
+		res, err := tx.Exec(`UPDATE price_job_items
+				SET status = $3, preco = $4, preco_a_vista = $5, preco_equilibrio = $6, erro = $7
+				WHERE job_id = $1 AND posicao = $2 AND status = 'pendente'`,
+			jobID, it.Posicao, it.Status, it.Preco, it.PrecoAVista, it.PrecoEquilibrio, it.Erro)
+		if err != nil {
+			return "", fmt.Errorf("SaveItemResults update item: %w", err)
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return "", fmt.Errorf("SaveItemResults rows: %w", err)
+		}
+		if it.Status == entities.ItemErro {
+			erros += n
+		} else {
+			ok += n
+		}
+	}
+
+	var status string
+	err = tx.QueryRow(`UPDATE price_jobs SET processados = processados + $2, erros = erros + $3,
+				reservado_ate = now() + $4 * interval '1 second'
+			WHERE id = $1 RETURNING status`, jobID, ok+erros, erros, reserva.Seconds()).Scan(&status)
+	if err != nil {
+		return "", fmt.Errorf("SaveItemResults update job: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", fmt.Errorf("SaveItemResults commit: %w", err)
+	}
+	return status, nil
+}
+
+// FinishJob → só encerra se ainda estiver em execução (o cancelamento prevalece)
+func (r *jobRepositoryImpl) FinishJob(jobID, status string) error {
+	_, err := r.postgresDB.Exec(`UPDATE price_jobs SET status = $2, concluido_em = now(), reservado_ate = NULL
+			WHERE id = $1 AND status = 'executando'`, jobID, status)
+	if err != nil {
+		return fmt.Errorf("FinishJob update: %w", err)
+	}
+	return nil
+}
+
+// ReleaseJob → reserva vencida imediatamente
+func (r *jobRepositoryImpl) ReleaseJob(jobID string) error {
+	_, err := r.postgresDB.Exec(`UPDATE price_jobs SET reservado_ate = now()
+			WHERE id = $1 AND status = 'executando'`, jobID)
+	if err != nil {
+		return fmt.Errorf("ReleaseJob update: %w", err)
+	}
+	return nil
+}
+
+// CancelJob → cancelamento condicional à situação
+func (r *jobRepositoryImpl) CancelJob(id string) error {
+	res, err := r.postgresDB.Exec(`UPDATE price_jobs SET status = 'cancelado', concluido_em = now(), reservado_ate = NULL
+			WHERE id = $1 AND status IN ('pendente', 'executando')`, id)
+	if err != nil {
+		return fmt.Errorf("CancelJob update: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("CancelJob rows: %w", err)
+	}
+	if n > 0 {
+		return nil
+	}
+
+	var exists bool
+	if err := r.postgresDB.QueryRow(`SELECT EXISTS (SELECT 1 FROM price_jobs WHERE id = $1)`, id).Scan(&exists); err != nil {
+		return fmt.Errorf("CancelJob exists: %w", err)
+	}
+	if !exists {
+		return repositories.ErrNotFound
+	}
+	return repositories.ErrConflict
+}
+
+// RenewJob → nova reserva a partir de agora
+func (r *jobRepositoryImpl) RenewJob(jobID string, reserva time.Duration) (string, error) {
+	var status string
+	err := r.postgresDB.QueryRow(`UPDATE price_jobs SET reservado_ate = now() + $2 * interval '1 second'
+			WHERE id = $1 RETURNING status`, jobID, reserva.Seconds()).Scan(&status)
+	if err == sql.ErrNoRows {
+		return "", repositories.ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("RenewJob update: %w", err)
+	}
+	return status, nil
+}
+
+// SaveReport → só encerra se ainda estiver em execução (o cancelamento prevalece)
+func (r *jobRepositoryImpl) SaveReport(jobID string, resultado []byte, erro string) error {
+	status, erros := entities.JobConcluido, 0
+	if erro != "" {
+		status, erros, resultado = entities.JobFalhou, 1, nil
+	}
+	_, err := r.postgresDB.Exec(`UPDATE price_jobs SET status = $2, resultado = $3, erro = $4,
+				processados = total, erros = $5, concluido_em = now(), reservado_ate = NULL
+			WHERE id = $1 AND status = 'executando'`, jobID, status, resultado, erro, erros)
+	if err != nil {
+		return fmt.Errorf("SaveReport update: %w", err)
+	}
+	return nil
+}
+
+// GetReport → coluna resultado; ErrNotFound se o job não existir
+func (r *jobRepositoryImpl) GetReport(jobID string) ([]byte, error) {
+	var resultado []byte
+	err := r.postgresDB.QueryRow(`SELECT resultado FROM price_jobs WHERE id = $1`, jobID).Scan(&resultado)
+	if err == sql.ErrNoRows {
+		return nil, repositories.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("GetReport scan: %w", err)
+	}
+	return resultado, nil
+}
+
+// EachJobItem → itens em ordem, sem carregar o job inteiro na memória
+func (r *jobRepositoryImpl) EachJobItem(jobID string, fn func(entities.PriceJobItem) error) error {
+	rows, err := r.postgresDB.Query(`SELECT posicao, sku, status, COALESCE(preco, 0), COALESCE(preco_a_vista, 0),
+				COALESCE(preco_equilibrio, 0), erro
+			FROM price_job_items WHERE job_id = $1 ORDER BY posicao`, jobID)
+	if err != nil {
+		return fmt.Errorf("EachJobItem query: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var it entities.PriceJobItem
+		if err := rows.Scan(&it.Posicao, &it.Sku, &it.Status, &it.Preco, &it.PrecoAVista, &it.PrecoEquilibrio, &it.Erro); err != nil {
+			return fmt.Errorf("EachJobItem scan: %w", err)
+		}
+		if err := fn(it); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// scanJob lê as colunas de jobColumns
+func scanJob(row rowScanner) (entities.PriceJob, error) {
+	var j entities.PriceJob
+	var parametros []byte
+	var iniciado, concluido, retomado sql.NullTime
+	err := row.Scan(&j.ID, &j.Tipo, &j.Status, &j.Usuario, &parametros, &j.Total, &j.Processados, &j.Erros, &j.CriadoEm,
+		&iniciado, &concluido, &retomado, &j.ProcessadosNaRetomada, &j.Erro)
+	if err != nil {
+		return j, err
+	}
+	if j.Tipo != entities.JobPreco {
+		j.Filtro = parametros
+	} else if err := json.Unmarshal(parametros, &j.Parametros); err != nil {
+		return j, err
+	}
+	if iniciado.Valid {
+		j.IniciadoEm = &iniciado.Time
+	}
+	if concluido.Valid {
+		j.ConcluidoEm = &concluido.Time
+	}
+	if retomado.Valid {
+		j.RetomadoEm = &retomado.Time
+	}
+	return j, nil
+}
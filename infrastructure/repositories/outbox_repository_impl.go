@@ -0,0 +1,194 @@
+package repositories
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"calculator/domain/entities"
+	"calculator/domain/repositories"
+
+	"github.com/lib/pq"
+)
+
+// outboxRepositoryImpl implementa OutboxRepository no Postgres
+type outboxRepositoryImpl struct {
+	postgresDB *sql.DB
+}
+
+// NewOutboxRepository constrói o repositório do outbox de eventos de preço
+func NewOutboxRepository(pg *sql.DB) repositories.OutboxRepository {
+	return &outboxRepositoryImpl{postgresDB: pg}
+}
+
+// SaveSubscription → novo destino de webhook
+func (r *outboxRepositoryImpl) SaveSubscription(s entities.WebhookSubscription) (entities.WebhookSubscription, error) {
+	err := r.postgresDB.QueryRow(`INSERT INTO webhook_subscriptions (nome, url, segredo, ativo)
+			VALUES ($1, $2, $3, $4) RETURNING id, criado_em`, s.Nome, s.URL, s.Segredo, s.Ativo).
+		Scan(&s.ID, &s.CriadoEm)
+	if err != nil {
+		return s, fmt.Errorf("SaveSubscription insert: %w", err)
+	}
+	s.Segredo = ""
+	return s, nil
+}
+
+// ListSubscriptions → destinos sem o segredo
+func (r *outboxRepositoryImpl) ListSubscriptions() ([]entities.WebhookSubscription, error) {
+	rows, err := r.postgresDB.Query(`SELECT id, nome, url, ativo, criado_em FROM webhook_subscriptions ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("ListSubscriptions query: %w", err)
+	}
+	defer rows.Close()
+
+	var out []entities.WebhookSubscription
+	for rows.Next() {
+		var s entities.WebhookSubscription
+		if err := rows.Scan(&s.ID, &s.Nome, &s.URL, &s.Ativo, &s.CriadoEm); err != nil {
+			return nil, fmt.Errorf("ListSubscriptions scan: %w", err)
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
+
+// ClaimDueDeliveries → SKIP LOCKED e adiamento da próxima tentativa pelo tempo da reserva
+func (r *outboxRepositoryImpl) ClaimDueDeliveries(limite int, reserva time.Duration) ([]entities.WebhookDelivery, error) {
+	rows, err := r.postgresDB.Query(`WITH devidas AS (
+				SELECT evento_id, assinatura_id FROM webhook_deliveries
+				WHERE status = 'pendente' AND proxima_tentativa <= now()
+				ORDER BY proxima_tentativa, evento_id
+				LIMIT $1 FOR UPDATE SKIP LOCKED
+			), reservadas AS (
+				UPDATE webhook_deliveries d SET proxima_tentativa = now() + $2 * interval '1 second'
+				FROM devidas
+				WHERE d.evento_id = devidas.evento_id AND d.assinatura_id = devidas.assinatura_id
+				RETURNING d.evento_id, d.assinatura_id, d.tentativas, d.ultimo_erro, d.proxima_tentativa
+			)
+			SELECT e.id, e.tipo, e.produto, e.criado_em, e.payload,
+				r.assinatura_id, s.url, s.segredo, r.tentativas, r.ultimo_erro, r.proxima_tentativa
+			FROM reservadas r
+			JOIN price_events e ON e.id = r.evento_id
+			JOIN webhook_subscriptions s ON s.id = r.assinatura_id
+			ORDER BY e.id, r.assinatura_id`, limite, reserva.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("ClaimDueDeliveries query: %w", err)
+	}
+	defer rows.Close()
+
+	var out []entities.WebhookDelivery
+	for rows.Next() {
+		d := entities.WebhookDelivery{Status: entities.EntregaPendente}
+		var payload []byte
+		err := rows.Scan(&d.Evento.ID, &d.Evento.Tipo, &d.Evento.Produto, &d.Evento.CriadoEm, &payload,
+			&d.AssinaturaID, &d.URL, &d.Segredo, &d.Tentativas, &d.UltimoErro, &d.ProximaTentativa)
+		if err != nil {
+			return nil, fmt.Errorf("ClaimDueDeliveries scan: %w", err)
+		}
+		d.Evento.Dados = json.RawMessage(payload)
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
+
+// MarkDelivered → entrega concluída
+func (r *outboxRepositoryImpl) MarkDelivered(eventoID, assinaturaID int64, em time.Time) error {
+	_, err := r.postgresDB.Exec(`UPDATE webhook_deliveries
+			SET status = 'entregue', tentativas = tentativas + 1, ultimo_erro = '', entregue_em = $3
+			WHERE evento_id = $1 AND assinatura_id = $2`, eventoID, assinaturaID, em)
+	if err != nil {
+		return fmt.Errorf("MarkDelivered update: %w", err)
+	}
+	return nil
+}
+
+// MarkFailed → nova tentativa agendada ou mensagem morta
+func (r *outboxRepositoryImpl) MarkFailed(eventoID, assinaturaID int64, tentativas int, erro string, proxima time.Time, morta bool) error {
+	status := entities.EntregaPendente
+	if morta {
+		status = entities.EntregaFalhou
+	}
+	_, err := r.postgresDB.Exec(`UPDATE webhook_deliveries
+			SET status = $3, tentativas = $4, ultimo_erro = $5, proxima_tentativa = $6
+			WHERE evento_id = $1 AND assinatura_id = $2`, eventoID, assinaturaID, status, tentativas, erro, proxima)
+	if err != nil {
+		return fmt.Errorf("MarkFailed update: %w", err)
+	}
+	return nil
+}
+
+// ListDeadLetters → mensagens mortas mais recentes primeiro
+func (r *outboxRepositoryImpl) ListDeadLetters(limite int) ([]entities.WebhookDelivery, error) {
+	rows, err := r.postgresDB.Query(`SELECT e.id, e.tipo, e.produto, e.criado_em, e.payload,
+				d.assinatura_id, s.url, d.status, d.tentativas, d.ultimo_erro, d.proxima_tentativa
+			FROM webhook_deliveries d
+			JOIN price_events e ON e.id = d.evento_id
+			JOIN webhook_subscriptions s ON s.id = d.assinatura_id
+			WHERE d.status = 'falhou'
+			ORDER BY e.id DESC
+			LIMIT $1`, limite)
+	if err != nil {
+		return nil, fmt.Errorf("ListDeadLetters query: %w", err)
+	}
+	defer rows.Close()
+
+	var out []entities.WebhookDelivery
+	for rows.Next() {
+		var d entities.WebhookDelivery
+		var payload []byte
+		err := rows.Scan(&d.Evento.ID, &d.Evento.Tipo, &d.Evento.Produto, &d.Evento.CriadoEm, &payload,
+			&d.AssinaturaID, &d.URL, &d.Status, &d.Tentativas, &d.UltimoErro, &d.ProximaTentativa)
+		if err != nil {
+			return nil, fmt.Errorf("ListDeadLetters scan: %w", err)
+		}
+		d.Evento.Dados = json.RawMessage(payload)
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
+
+// ReplayEvents → upsert das entregas como pendentes, com as tentativas zeradas
+func (r *outboxRepositoryImpl) ReplayEvents(req entities.EventReplayRequest) (int, error) {
+	var desde interface{}
+	if !req.Desde.IsZero() {
+		desde = req.Desde
+	}
+	res, err := r.postgresDB.Exec(`INSERT INTO webhook_deliveries (evento_id, assinatura_id)
+			SELECT e.id, s.id
+			FROM price_events e CROSS JOIN webhook_subscriptions s
+			WHERE s.ativo AND ($1::bigint = 0 OR s.id = $1)
+			AND (e.id = ANY($2) OR ($3::timestamptz IS NOT NULL AND e.criado_em >= $3))
+			ON CONFLICT (evento_id, assinatura_id) DO UPDATE
+			SET status = 'pendente', tentativas = 0, proxima_tentativa = now(), ultimo_erro = '', entregue_em = NULL`,
+		req.AssinaturaID, pq.Array(req.EventoIDs), desde)
+	if err != nil {
+		return 0, fmt.Errorf("ReplayEvents upsert: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("ReplayEvents rows: %w", err)
+	}
+	return int(n), nil
+}
+
+// insertEvent grava o evento no outbox e uma entrega pendente por destino ativo,
+// na transação de quem alterou o preço
+func insertEvent(tx *sql.Tx, tipo, produto string, dados interface{}) error {
+	payload, err := json.Marshal(dados)
+	if err != nil {
+		return fmt.Errorf("insertEvent marshal: %w", err)
+	}
+	var id int64
+	err = tx.QueryRow(`INSERT INTO price_events (tipo, produto, payload) VALUES ($1, $2, $3) RETURNING id`,
+		tipo, produto, payload).Scan(&id)
+	if err != nil {
+		return fmt.Errorf("insertEvent insert: %w", err)
+	}
+	_, err = tx.Exec(`INSERT INTO webhook_deliveries (evento_id, assinatura_id)
+			SELECT $1, id FROM webhook_subscriptions WHERE ativo`, id)
+	if err != nil {
+		return fmt.Errorf("insertEvent deliveries: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,213 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"calculator/domain/entities"
+	"calculator/domain/repositories"
+)
+
+// priceWriteRepositoryImpl implementa PriceWriteRepository no Postgres
+type priceWriteRepositoryImpl struct {
+	postgresDB *sql.DB
+}
+
+// NewPriceWriteRepository constrói o repositório do log de gravações de preço
+func NewPriceWriteRepository(pg *sql.DB) repositories.PriceWriteRepository {
+	return &priceWriteRepositoryImpl{postgresDB: pg}
+}
+
+// SaveWriteRun → cabeçalho e alterações em uma transação
+func (r *priceWriteRepositoryImpl) SaveWriteRun(run entities.PriceWriteRun) error {
+	tx, err := r.postgresDB.Begin()
+	if err != nil {
+		return fmt.Errorf("SaveWriteRun begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`INSERT INTO price_write_runs (id, criado_em, usuario, dry_run, status, erro)
+			VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''))`,
+		run.ID, run.CriadoEm, run.Usuario, run.DryRun, run.Status, run.Erro)
+	if err != nil {
+		return fmt.Errorf("SaveWriteRun insert run: %w", err)
+	}
+	for _, c := range run.Alteracoes {
+		_, err := tx.Exec(`INSERT INTO price_write_changes (run_id, sku, produto, tabela, preco_anterior, preco_novo)
+				VALUES ($1, $2, $3, $4, $5, $6)`,
+			run.ID, c.Sku, c.Produto, c.Tabela, c.PrecoAnterior, c.PrecoNovo)
+		if err != nil {
+			return fmt.Errorf("SaveWriteRun insert change: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("SaveWriteRun commit: %w", err)
+	}
+	return nil
+}
+
+// UpdateWriteRunStatus → ErrNotFound se a gravação não existir
+func (r *priceWriteRepositoryImpl) UpdateWriteRunStatus(id, status, erro, revertidoPor string) error {
+	tx, err := r.postgresDB.Begin()
+	if err != nil {
+		return fmt.Errorf("UpdateWriteRunStatus begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`UPDATE price_write_runs
+			SET status = $2, erro = NULLIF($3, ''), revertido_por = COALESCE(NULLIF($4, ''), revertido_por), atualizado_em = now()
+			WHERE id = $1`, id, status, erro, revertidoPor)
+	if err != nil {
+		return fmt.Errorf("UpdateWriteRunStatus update: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("UpdateWriteRunStatus rows: %w", err)
+	}
+	if n == 0 {
+		return repositories.ErrNotFound
+	}
+	if status == entities.GravacaoAplicada || status == entities.GravacaoRevertida {
+		if _, err := tx.Exec(enqueuePublicationSQL, id); err != nil {
+			return fmt.Errorf("UpdateWriteRunStatus enqueue: %w", err)
+		}
+		if err := insertPublishedEvents(tx, id, status); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("UpdateWriteRunStatus commit: %w", err)
+	}
+	return nil
+}
+
+// enqueuePublicationSQL coloca a gravação na fila ou a volta para pendente
+const enqueuePublicationSQL = `INSERT INTO price_run_publications (run_id) VALUES ($1)
+	ON CONFLICT (run_id) DO UPDATE
+	SET status = 'pendente', tentativas = 0, proxima_tentativa = now(), ultimo_erro = ''`
+
+// insertPublishedEvents grava um evento price.published por item da gravação
+func insertPublishedEvents(tx *sql.Tx, id, status string) error {
+	rows, err := tx.Query(`SELECT sku, tabela, preco_anterior, preco_novo
+			FROM price_write_changes WHERE run_id = $1 ORDER BY tabela, sku`, id)
+	if err != nil {
+		return fmt.Errorf("insertPublishedEvents query: %w", err)
+	}
+	agora := time.Now()
+	var eventos []entities.PricePublishedData
+	for rows.Next() {
+		ev := entities.PricePublishedData{Gravacao: id, Situacao: status, PublicadoEm: agora}
+		if err := rows.Scan(&ev.Produto, &ev.Tabela, &ev.PrecoAnterior, &ev.Preco); err != nil {
+			rows.Close()
+			return fmt.Errorf("insertPublishedEvents scan: %w", err)
+		}
+		if status == entities.GravacaoRevertida {
+			ev.PrecoAnterior, ev.Preco = ev.Preco, ev.PrecoAnterior
+		}
+		eventos = append(eventos, ev)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("insertPublishedEvents rows: %w", err)
+	}
+
+	for _, ev := range eventos {
+		if err := insertEvent(tx, entities.EventoPrecoPublicado, ev.Produto, ev); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetWriteRun → cabeçalho e alterações
+func (r *priceWriteRepositoryImpl) GetWriteRun(id string) (entities.PriceWriteRun, error) {
+	run := entities.PriceWriteRun{ID: id}
+	var erro, revertidoPor sql.NullString
+	err := r.postgresDB.QueryRow(`SELECT criado_em, usuario, dry_run, status, erro, revertido_por
+			FROM price_write_runs WHERE id = $1`, id).
+		Scan(&run.CriadoEm, &run.Usuario, &run.DryRun, &run.Status, &erro, &revertidoPor)
+	if err == sql.ErrNoRows {
+		return run, repositories.ErrNotFound
+	}
+	if err != nil {
+		return run, fmt.Errorf("GetWriteRun scan: %w", err)
+	}
+	run.Erro = erro.String
+	run.RevertidoPor = revertidoPor.String
+
+	rows, err := r.postgresDB.Query(`SELECT sku, produto, tabela, preco_anterior, preco_novo
+			FROM price_write_changes WHERE run_id = $1 ORDER BY tabela, sku`, id)
+	if err != nil {
+		return run, fmt.Errorf("GetWriteRun query changes: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var c entities.PriceWriteChange
+		if err := rows.Scan(&c.Sku, &c.Produto, &c.Tabela, &c.PrecoAnterior, &c.PrecoNovo); err != nil {
+			return run, fmt.Errorf("GetWriteRun scan change: %w", err)
+		}
+		run.Alteracoes = append(run.Alteracoes, c)
+	}
+	return run, rows.Err()
+}
+
+// EnqueuePublication → a gravação deve existir (chave estrangeira)
+func (r *priceWriteRepositoryImpl) EnqueuePublication(id string) error {
+	if _, err := r.postgresDB.Exec(enqueuePublicationSQL, id); err != nil {
+		return fmt.Errorf("EnqueuePublication insert: %w", err)
+	}
+	return nil
+}
+
+// ClaimDuePublications → SKIP LOCKED e adiamento da próxima tentativa pelo tempo da reserva
+func (r *priceWriteRepositoryImpl) ClaimDuePublications(limite int, reserva time.Duration) ([]entities.RunPublication, error) {
+	rows, err := r.postgresDB.Query(`WITH devidas AS (
+				SELECT run_id FROM price_run_publications
+				WHERE status = 'pendente' AND proxima_tentativa <= now()
+				ORDER BY proxima_tentativa, run_id
+				LIMIT $1 FOR UPDATE SKIP LOCKED
+			)
+			UPDATE price_run_publications p SET proxima_tentativa = now() + $2 * interval '1 second'
+			FROM devidas
+			WHERE p.run_id = devidas.run_id
+			RETURNING p.run_id, p.tentativas`, limite, reserva.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("ClaimDuePublications query: %w", err)
+	}
+	defer rows.Close()
+
+	var out []entities.RunPublication
+	for rows.Next() {
+		var p entities.RunPublication
+		if err := rows.Scan(&p.RunID, &p.Tentativas); err != nil {
+			return nil, fmt.Errorf("ClaimDuePublications scan: %w", err)
+		}
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
+
+// MarkPublished → publicação concluída
+func (r *priceWriteRepositoryImpl) MarkPublished(id string, em time.Time) error {
+	_, err := r.postgresDB.Exec(`UPDATE price_run_publications
+			SET status = 'publicada', tentativas = tentativas + 1, ultimo_erro = '', publicado_em = $2
+			WHERE run_id = $1`, id, em)
+	if err != nil {
+		return fmt.Errorf("MarkPublished update: %w", err)
+	}
+	return nil
+}
+
+// MarkPublishFailed → nova tentativa agendada
+func (r *priceWriteRepositoryImpl) MarkPublishFailed(id string, tentativas int, erro string, proxima time.Time) error {
+	_, err := r.postgresDB.Exec(`UPDATE price_run_publications
+			SET tentativas = $2, ultimo_erro = $3, proxima_tentativa = $4
+			WHERE run_id = $1`, id, tentativas, erro, proxima)
+	if err != nil {
+		return fmt.Errorf("MarkPublishFailed update: %w", err)
+	}
+	return nil
+}
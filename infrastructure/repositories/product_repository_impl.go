@@ -3,10 +3,12 @@ package repositories
 import (
 	"database/sql"
 	"fmt"
+	"time"
 	// "strconv"
 	"github.com/sirupsen/logrus"
 	"calculator/domain/entities"
 	"calculator/domain/repositories"
+	"calculator/internal/firebird"
  )
  var log = logrus.New()
 
@@ -22,14 +24,17 @@ type productRepositoryImpl struct {
 	postgresDB  *sql.DB
 	firebirdDB  *sql.DB
 	sqlServerDB *sql.DB
+	sources     repositories.SourceChecker
 }
 
-// NewProductRepository constrói um repository com as três conexões
-func NewProductRepository(pg *sql.DB, fb *sql.DB, ms *sql.DB) repositories.ProductRepository {
+// NewProductRepository constrói um repository com as três conexões; com o Firebird
+// fora do ar as consultas a ele falham na hora, sem esperar o tempo de conexão
+func NewProductRepository(pg *sql.DB, fb *sql.DB, ms *sql.DB, sc repositories.SourceChecker) repositories.ProductRepository {
 	return &productRepositoryImpl{
 		postgresDB:  pg,
 		firebirdDB:  fb,
 		sqlServerDB: ms,
+		sources:     sc,
 	}
 }
 
@@ -54,6 +59,26 @@ func (r *productRepositoryImpl) GetProductCmpValues(sku string) (entities.PriceI
 	return pi, nil
 }
 
+// GetProductCmpValuesAt → índice mais recente com data até a informada;
+// índices legados sem data valem como os mais antigos
+func (r *productRepositoryImpl) GetProductCmpValuesAt(sku string, data time.Time) (entities.PriceInput, error) {
+	q := `SELECT p.cmp_icms, p.cmp_pis_cofins, p.cmp, p.cmp_nf
+			FROM productscmp p
+			LEFT JOIN cmp_stock s ON s.produto = p.produto AND s.index = p.index
+			WHERE p.produto = $1
+			AND (s.data_indice IS NULL OR s.data_indice <= $2)
+			ORDER BY s.data_indice DESC NULLS LAST, p.index DESC
+			LIMIT 1`
+	row := r.postgresDB.QueryRow(q, sku, data)
+
+	var pi entities.PriceInput
+	err := row.Scan(&pi.IcmsMedio, &pi.PisCofinsMedio, &pi.CustoMedioLiq, &pi.CustoMedioNF)
+	if err != nil {
+		return pi, fmt.Errorf("GetProductCmpValuesAt scan: %w", err)
+	}
+	return pi, nil
+}
+
 // GetParameters → carrega Parameters do Postgres
 func (r *productRepositoryImpl) GetParameters() (entities.Parameters, error) {
 	q := `SELECT lucro_adicional_desejado, lucro_padrao_desejado, imposto_federal, operacao,
@@ -81,6 +106,252 @@ func (r *productRepositoryImpl) GetParameters() (entities.Parameters, error) {
 	return pm, nil
 }
 
+// GetFci → busca a FCI do produto; retorna nil quando não houver ficha cadastrada
+func (r *productRepositoryImpl) GetFci(sku string) (*entities.Fci, error) {
+	q := `SELECT produto, numero_fci, conteudo_importacao, data_emissao
+			FROM product_fci
+			WHERE produto = $1`
+	row := r.postgresDB.QueryRow(q, sku)
+
+	var fci entities.Fci
+	err := row.Scan(&fci.Produto, &fci.Numero, &fci.ConteudoImportacao, &fci.DataEmissao)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("GetFci scan: %w", err)
+	}
+	return &fci, nil
+}
+
+// GetFaixasVolume → faixas de quantidade do segmento em ordem crescente
+func (r *productRepositoryImpl) GetFaixasVolume(tipoCliente string) ([]entities.FaixaVolume, error) {
+	q := `SELECT tipo_cliente, quantidade_minima, lucro_desejado
+			FROM price_volume_tiers
+			WHERE tipo_cliente = $1
+			ORDER BY quantidade_minima`
+	rows, err := r.postgresDB.Query(q, tipoCliente)
+	if err != nil {
+		return nil, fmt.Errorf("GetFaixasVolume query: %w", err)
+	}
+	defer rows.Close()
+
+	var faixas []entities.FaixaVolume
+	for rows.Next() {
+		var f entities.FaixaVolume
+		if err := rows.Scan(&f.TipoCliente, &f.QuantidadeMinima, &f.LucroDesejado); err != nil {
+			return nil, fmt.Errorf("GetFaixasVolume scan: %w", err)
+		}
+		faixas = append(faixas, f)
+	}
+	return faixas, rows.Err()
+}
+
+// GetAliquotaUF → alíquotas de ICMS e FCP da UF de destino
+func (r *productRepositoryImpl) GetAliquotaUF(uf string) (entities.AliquotaUF, error) {
+	q := `SELECT uf, aliquota_interna, aliquota_interestadual, fcp
+			FROM icms_uf
+			WHERE uf = $1`
+
+	var a entities.AliquotaUF
+	err := r.postgresDB.QueryRow(q, uf).Scan(&a.UF, &a.AliquotaInterna, &a.AliquotaInterestadual, &a.Fcp)
+	if err == sql.ErrNoRows {
+		return a, repositories.ErrNotFound
+	}
+	if err != nil {
+		return a, fmt.Errorf("GetAliquotaUF scan: %w", err)
+	}
+	return a, nil
+}
+
+// GetRegraST → MVA do produto para a UF de destino
+func (r *productRepositoryImpl) GetRegraST(sku string, uf string) (entities.RegraST, error) {
+	q := `SELECT produto, uf, mva
+			FROM icms_st
+			WHERE produto = $1 AND uf = $2`
+
+	var st entities.RegraST
+	err := r.postgresDB.QueryRow(q, sku, uf).Scan(&st.Produto, &st.UF, &st.Mva)
+	if err == sql.ErrNoRows {
+		return st, repositories.ErrNotFound
+	}
+	if err != nil {
+		return st, fmt.Errorf("GetRegraST scan: %w", err)
+	}
+	return st, nil
+}
+
+// GetLastPurchase → entrada de compra mais recente do produto
+func (r *productRepositoryImpl) GetLastPurchase(sku string) (entities.PurchaseEntry, error) {
+	q := `SELECT chave_nfe, numero_item, produto, fornecedor, data_emissao, quantidade,
+				 custo_nf, credito_icms, credito_pis_cofins, ipi
+			FROM purchase_entries
+			WHERE produto = $1 AND quantidade > 0
+			ORDER BY data_emissao DESC, numero_item DESC
+			LIMIT 1`
+
+	var e entities.PurchaseEntry
+	err := r.postgresDB.QueryRow(q, sku).Scan(&e.ChaveNfe, &e.NumeroItem, &e.Produto, &e.Fornecedor, &e.DataEmissao,
+		&e.Quantidade, &e.CustoNF, &e.CreditoIcms, &e.CreditoPisCofins, &e.Ipi)
+	if err == sql.ErrNoRows {
+		return e, repositories.ErrNotFound
+	}
+	if err != nil {
+		return e, fmt.Errorf("GetLastPurchase scan: %w", err)
+	}
+	return e, nil
+}
+
+// GetSupplierListCost → menor custo entre as tabelas de fornecedor vigentes
+func (r *productRepositoryImpl) GetSupplierListCost(sku string) (float64, error) {
+	q := `SELECT MIN(custo) FROM (
+				SELECT DISTINCT ON (fornecedor) custo
+				FROM supplier_price_list
+				WHERE produto = $1 AND vigencia_inicio <= CURRENT_DATE
+				ORDER BY fornecedor, vigencia_inicio DESC
+			) vigentes`
+
+	var custo sql.NullFloat64
+	if err := r.postgresDB.QueryRow(q, sku).Scan(&custo); err != nil {
+		return 0, fmt.Errorf("GetSupplierListCost scan: %w", err)
+	}
+	if !custo.Valid {
+		return 0, repositories.ErrNotFound
+	}
+	return custo.Float64, nil
+}
+
+// GetReplacementCost → custo de reposição informado manualmente
+func (r *productRepositoryImpl) GetReplacementCost(sku string) (float64, error) {
+	var custo float64
+	err := r.postgresDB.QueryRow(`SELECT custo FROM replacement_costs WHERE produto = $1`, sku).Scan(&custo)
+	if err == sql.ErrNoRows {
+		return 0, repositories.ErrNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("GetReplacementCost scan: %w", err)
+	}
+	return custo, nil
+}
+
+// GetDepartmentCostBasis → base de custo do departamento, "" quando não configurada
+func (r *productRepositoryImpl) GetDepartmentCostBasis(departamento int) (string, error) {
+	var base string
+	err := r.postgresDB.QueryRow(`SELECT base_custo FROM department_cost_basis WHERE departamento = $1`, departamento).Scan(&base)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("GetDepartmentCostBasis scan: %w", err)
+	}
+	return base, nil
+}
+
+// GetProductDimensions → peso e medidas da embalagem de envio
+func (r *productRepositoryImpl) GetProductDimensions(sku string) (entities.ProdutoDimensoes, error) {
+	q := `SELECT produto, peso_kg, altura_cm, largura_cm, comprimento_cm
+			FROM product_dimensions
+			WHERE produto = $1`
+
+	var d entities.ProdutoDimensoes
+	err := r.postgresDB.QueryRow(q, sku).Scan(&d.Produto, &d.PesoKg, &d.AlturaCm, &d.LarguraCm, &d.ComprimentoCm)
+	if err == sql.ErrNoRows {
+		return d, repositories.ErrNotFound
+	}
+	if err != nil {
+		return d, fmt.Errorf("GetProductDimensions scan: %w", err)
+	}
+	return d, nil
+}
+
+// GetRebateAgreements → acordos do fornecedor (e da marca) do produto vigentes na data;
+// o volume considera as compras do fornecedor, restritas à marca quando o acordo for de marca
+// (produtos sem cadastro em product_suppliers contam nos acordos sem marca)
+func (r *productRepositoryImpl) GetRebateAgreements(sku string, data time.Time) ([]entities.RebateAgreement, error) {
+	q := `SELECT a.id, a.fornecedor, COALESCE(a.marca, ''), a.descricao, a.percentual, a.volume_minimo,
+				 a.vigencia_inicio, a.vigencia_fim,
+				 COALESCE((
+					SELECT SUM(e.custo_nf)
+					FROM purchase_entries e
+					LEFT JOIN product_suppliers pe ON pe.produto = e.produto
+					WHERE e.fornecedor = a.fornecedor
+					AND (a.marca IS NULL OR pe.marca = a.marca)
+					AND e.data_emissao >= a.vigencia_inicio
+					AND e.data_emissao < a.vigencia_fim + 1
+				 ), 0) AS volume
+			FROM product_suppliers ps
+			JOIN rebate_agreements a ON a.fornecedor = ps.fornecedor
+				AND (a.marca IS NULL OR a.marca = ps.marca)
+			WHERE ps.produto = $1
+			AND $2::date BETWEEN a.vigencia_inicio AND a.vigencia_fim`
+	rows, err := r.postgresDB.Query(q, sku, data)
+	if err != nil {
+		return nil, fmt.Errorf("GetRebateAgreements query: %w", err)
+	}
+	defer rows.Close()
+
+	var acordos []entities.RebateAgreement
+	for rows.Next() {
+		var a entities.RebateAgreement
+		if err := rows.Scan(&a.ID, &a.Fornecedor, &a.Marca, &a.Descricao, &a.Percentual, &a.VolumeMinimo,
+			&a.VigenciaInicio, &a.VigenciaFim, &a.VolumeComprado); err != nil {
+			return nil, fmt.Errorf("GetRebateAgreements scan: %w", err)
+		}
+		acordos = append(acordos, a)
+	}
+	return acordos, rows.Err()
+}
+
+// GetPaymentProfiles → perfis do canal; sem perfis próprios usa o canal 'padrao'
+func (r *productRepositoryImpl) GetPaymentProfiles(canal string) ([]entities.PaymentProfile, error) {
+	q := `SELECT canal, meio_pagamento, taxa, parcelas_sem_juros, taxa_antecipacao_mensal
+			FROM payment_profiles
+			WHERE canal = (
+				SELECT canal FROM payment_profiles
+				WHERE canal IN ($1, $2)
+				ORDER BY (canal = $1) DESC
+				LIMIT 1
+			)`
+	rows, err := r.postgresDB.Query(q, canal, entities.CanalPadrao)
+	if err != nil {
+		return nil, fmt.Errorf("GetPaymentProfiles query: %w", err)
+	}
+	defer rows.Close()
+
+	var perfis []entities.PaymentProfile
+	for rows.Next() {
+		var p entities.PaymentProfile
+		if err := rows.Scan(&p.Canal, &p.MeioPagamento, &p.Taxa, &p.ParcelasSemJuros, &p.TaxaAntecipacaoMensal); err != nil {
+			return nil, fmt.Errorf("GetPaymentProfiles scan: %w", err)
+		}
+		perfis = append(perfis, p)
+	}
+	return perfis, rows.Err()
+}
+
+// GetCostComponents → prioridade: departamento+canal, canal, departamento, geral
+func (r *productRepositoryImpl) GetCostComponents(departamento int, canal string) (entities.CostComponents, bool, error) {
+	q := `SELECT COALESCE('departamento ' || departamento::text, 'todos os departamentos')
+				 || ' / ' || COALESCE('canal ' || canal, 'todos os canais'),
+				 taxa_devolucao, taxa_chargeback, embalagem, fulfillment, provisao_custo_nf
+			FROM cost_components
+			WHERE (departamento IS NULL OR departamento = $1)
+			AND (canal IS NULL OR canal = $2)
+			ORDER BY (canal IS NOT NULL) DESC, (departamento IS NOT NULL) DESC
+			LIMIT 1`
+
+	var c entities.CostComponents
+	err := r.postgresDB.QueryRow(q, departamento, canal).Scan(&c.Escopo, &c.TaxaDevolucao, &c.TaxaChargeback,
+		&c.Embalagem, &c.Fulfillment, &c.ProvisaoCustoNF)
+	if err == sql.ErrNoRows {
+		return c, false, nil
+	}
+	if err != nil {
+		return c, false, fmt.Errorf("GetCostComponents scan: %w", err)
+	}
+	return c, true, nil
+}
+
 // GetCostFire → busca no Firebird (departamento, comissao, frete)
 	func (r *productRepositoryImpl) GetCostFire(sku string) (entities.CostFire, error) {
 		stringSku := "_0_0_U"
@@ -90,6 +361,10 @@ func (r *productRepositoryImpl) GetParameters() (entities.Parameters, error) {
 			"product":product,
 		}).Info("Gerando sku para consulta")
 
+		if err := r.sources.CheckSources(repositories.FonteFirebird); err != nil {
+			return entities.CostFire{}, fmt.Errorf("GetCostFire: %w", err)
+		}
+
 		// preciso inserir um log para identificar o que esta sendo montado em product
 		q := `select n.sku,n.cod_produto, p.departamento, n.comissao, n.preco FROM 
 		np_comissao_frete n join produtos p on p.cod_produto = n.cod_produto WHERE n.sku = ?`
@@ -101,6 +376,10 @@ func (r *productRepositoryImpl) GetParameters() (entities.Parameters, error) {
 			log.WithFields(logrus.Fields{
 				"sku": product,
 			}).Error("Erro ao escanear resultado:", err)
+			if firebird.IsConnectionError(err) {
+				// Sem resposta do Firebird o cálculo pode usar o cache da última leitura
+				return cf, fmt.Errorf("%w: GetCostFire: %v", repositories.ErrUnavailable, err)
+			}
 			return cf, fmt.Errorf("GetCostFire scan: %w", err)
 		}
 		log.WithFields(logrus.Fields{
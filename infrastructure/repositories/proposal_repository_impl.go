@@ -0,0 +1,201 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+
+	"calculator/domain/entities"
+	"calculator/domain/repositories"
+
+	"github.com/lib/pq"
+)
+
+// proposalRepositoryImpl implementa ProposalRepository no Postgres
+type proposalRepositoryImpl struct {
+	postgresDB *sql.DB
+}
+
+// NewProposalRepository constrói o repositório de propostas de preço
+func NewProposalRepository(pg *sql.DB) repositories.ProposalRepository {
+	return &proposalRepositoryImpl{postgresDB: pg}
+}
+
+const proposalColumns = `id, sku, tabela, origem, preco_atual, preco_proposto, variacao, papel_exigido,
+			status, criado_por, criado_em, atualizado_em, COALESCE(gravacao_id, '')`
+
+// SaveProposals → propostas e eventos iniciais em uma transação
+func (r *proposalRepositoryImpl) SaveProposals(ps []entities.PriceProposal) error {
+	tx, err := r.postgresDB.Begin()
+	if err != nil {
+		return fmt.Errorf("SaveProposals begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, p := range ps {
+		_, err := tx.Exec(`INSERT INTO price_proposals (id, sku, tabela, origem, preco_atual, preco_proposto, variacao,
+					papel_exigido, status, criado_por, criado_em, atualizado_em)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`,
+			p.ID, p.Sku, p.Tabela, p.Origem, p.PrecoAtual, p.PrecoProposto, p.Variacao,
+			p.PapelExigido, p.Status, p.CriadoPor, p.CriadoEm, p.AtualizadoEm)
+		if err != nil {
+			return fmt.Errorf("SaveProposals insert: %w", err)
+		}
+		for _, ev := range p.Eventos {
+			if err := insertProposalEvent(tx, p.ID, ev); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("SaveProposals commit: %w", err)
+	}
+	return nil
+}
+
+// GetProposal → proposta com o histórico de transições
+func (r *proposalRepositoryImpl) GetProposal(id string) (entities.PriceProposal, error) {
+	p, err := scanProposal(r.postgresDB.QueryRow(`SELECT `+proposalColumns+` FROM price_proposals WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return p, repositories.ErrNotFound
+	}
+	if err != nil {
+		return p, fmt.Errorf("GetProposal scan: %w", err)
+	}
+
+	rows, err := r.postgresDB.Query(`SELECT de, para, usuario, papel, comentario, criado_em
+			FROM price_proposal_events WHERE proposta_id = $1 ORDER BY id`, id)
+	if err != nil {
+		return p, fmt.Errorf("GetProposal query events: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var ev entities.ProposalEvent
+		if err := rows.Scan(&ev.De, &ev.Para, &ev.Usuario, &ev.Papel, &ev.Comentario, &ev.Em); err != nil {
+			return p, fmt.Errorf("GetProposal scan event: %w", err)
+		}
+		p.Eventos = append(p.Eventos, ev)
+	}
+	return p, rows.Err()
+}
+
+// ListProposals → mais antigas primeiro, como fila de revisão
+func (r *proposalRepositoryImpl) ListProposals(status string) ([]entities.PriceProposal, error) {
+	rows, err := r.postgresDB.Query(`SELECT `+proposalColumns+` FROM price_proposals
+			WHERE $1 = '' OR status = $1
+			ORDER BY criado_em, sku`, status)
+	if err != nil {
+		return nil, fmt.Errorf("ListProposals query: %w", err)
+	}
+	defer rows.Close()
+
+	var ps []entities.PriceProposal
+	for rows.Next() {
+		p, err := scanProposal(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ListProposals scan: %w", err)
+		}
+		ps = append(ps, p)
+	}
+	return ps, rows.Err()
+}
+
+// ListOpenProposals → rascunho, pendente ou aprovada
+func (r *proposalRepositoryImpl) ListOpenProposals(skus []string) ([]entities.PriceProposal, error) {
+	rows, err := r.postgresDB.Query(`SELECT `+proposalColumns+` FROM price_proposals
+			WHERE status IN ('rascunho', 'pendente', 'aprovada') AND sku = ANY($1)
+			ORDER BY criado_em, sku`, pq.Array(skus))
+	if err != nil {
+		return nil, fmt.Errorf("ListOpenProposals query: %w", err)
+	}
+	defer rows.Close()
+
+	var ps []entities.PriceProposal
+	for rows.Next() {
+		p, err := scanProposal(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ListOpenProposals scan: %w", err)
+		}
+		ps = append(ps, p)
+	}
+	return ps, rows.Err()
+}
+
+// TransitionProposal → UPDATE condicionado à situação anterior mais o evento
+func (r *proposalRepositoryImpl) TransitionProposal(id string, ev entities.ProposalEvent, gravacaoID string) error {
+	tx, err := r.postgresDB.Begin()
+	if err != nil {
+		return fmt.Errorf("TransitionProposal begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`UPDATE price_proposals
+			SET status = $3, atualizado_em = $4, gravacao_id = COALESCE(NULLIF($5, ''), gravacao_id)
+			WHERE id = $1 AND status = $2`, id, ev.De, ev.Para, ev.Em, gravacaoID)
+	if err != nil {
+		return fmt.Errorf("TransitionProposal update: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("TransitionProposal rows: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%w: proposta %s não está mais %s", repositories.ErrConflict, id, ev.De)
+	}
+	if err := insertProposalEvent(tx, id, ev); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("TransitionProposal commit: %w", err)
+	}
+	return nil
+}
+
+// GetApprovalRules → regras cadastradas; vazio quando não houver
+func (r *proposalRepositoryImpl) GetApprovalRules() ([]entities.ApprovalRule, error) {
+	rows, err := r.postgresDB.Query(`SELECT variacao_ate, papel_exigido FROM approval_rules ORDER BY variacao_ate NULLS LAST`)
+	if err != nil {
+		return nil, fmt.Errorf("GetApprovalRules query: %w", err)
+	}
+	defer rows.Close()
+
+	var regras []entities.ApprovalRule
+	for rows.Next() {
+		var limite sql.NullFloat64
+		var regra entities.ApprovalRule
+		if err := rows.Scan(&limite, &regra.PapelExigido); err != nil {
+			return nil, fmt.Errorf("GetApprovalRules scan: %w", err)
+		}
+		if limite.Valid {
+			v := limite.Float64
+			regra.VariacaoAte = &v
+		}
+		regras = append(regras, regra)
+	}
+	return regras, rows.Err()
+}
+
+// rowScanner cobre *sql.Row e *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanProposal lê as colunas de proposalColumns
+func scanProposal(row rowScanner) (entities.PriceProposal, error) {
+	var p entities.PriceProposal
+	err := row.Scan(&p.ID, &p.Sku, &p.Tabela, &p.Origem, &p.PrecoAtual, &p.PrecoProposto, &p.Variacao,
+		&p.PapelExigido, &p.Status, &p.CriadoPor, &p.CriadoEm, &p.AtualizadoEm, &p.GravacaoID)
+	return p, err
+}
+
+// insertProposalEvent grava uma transição dentro da transação informada
+func insertProposalEvent(tx *sql.Tx, id string, ev entities.ProposalEvent) error {
+	_, err := tx.Exec(`INSERT INTO price_proposal_events (proposta_id, de, para, usuario, papel, comentario, criado_em)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		id, ev.De, ev.Para, ev.Usuario, ev.Papel, ev.Comentario, ev.Em)
+	if err != nil {
+		return fmt.Errorf("insert proposal event: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package repositories
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"calculator/domain/entities"
+	"calculator/domain/repositories"
+)
+
+// quoteRepositoryImpl implementa QuoteRepository no Postgres
+type quoteRepositoryImpl struct {
+	postgresDB *sql.DB
+}
+
+// NewQuoteRepository constrói o repositório de orçamentos
+func NewQuoteRepository(pg *sql.DB) repositories.QuoteRepository {
+	return &quoteRepositoryImpl{postgresDB: pg}
+}
+
+// SaveQuote grava o orçamento com o payload completo em JSON
+func (r *quoteRepositoryImpl) SaveQuote(q entities.Quote) error {
+	payload, err := json.Marshal(q)
+	if err != nil {
+		return fmt.Errorf("SaveQuote marshal: %w", err)
+	}
+
+	_, err = r.postgresDB.Exec(`INSERT INTO quotes (id, criado_em, valido_ate, uf, tipo_cliente, valor_total, payload)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		q.ID, q.CriadoEm, q.ValidoAte, q.UF, q.TipoCliente, q.Totais.ValorTotal, payload)
+	if err != nil {
+		return fmt.Errorf("SaveQuote insert: %w", err)
+	}
+	return nil
+}
+
+// GetQuote carrega o orçamento salvo
+func (r *quoteRepositoryImpl) GetQuote(id string) (entities.Quote, error) {
+	var payload []byte
+	err := r.postgresDB.QueryRow(`SELECT payload FROM quotes WHERE id = $1`, id).Scan(&payload)
+	if err == sql.ErrNoRows {
+		return entities.Quote{}, repositories.ErrNotFound
+	}
+	if err != nil {
+		return entities.Quote{}, fmt.Errorf("GetQuote scan: %w", err)
+	}
+
+	var q entities.Quote
+	if err := json.Unmarshal(payload, &q); err != nil {
+		return entities.Quote{}, fmt.Errorf("GetQuote unmarshal: %w", err)
+	}
+	return q, nil
+}
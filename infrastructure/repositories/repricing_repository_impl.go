@@ -0,0 +1,198 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+
+	"calculator/domain/entities"
+	"calculator/domain/repositories"
+
+	"github.com/lib/pq"
+)
+
+// repricingRepositoryImpl implementa RepricingRepository no Postgres
+type repricingRepositoryImpl struct {
+	postgresDB *sql.DB
+}
+
+// NewRepricingRepository constrói o repositório do recálculo automático
+func NewRepricingRepository(pg *sql.DB) repositories.RepricingRepository {
+	return &repricingRepositoryImpl{postgresDB: pg}
+}
+
+// SaveComputedPrices → upsert por produto; quando o preço muda, o evento price.changed
+// entra no outbox na mesma transação
+func (r *repricingRepositoryImpl) SaveComputedPrices(prices []entities.ComputedPrice) error {
+	tx, err := r.postgresDB.Begin()
+	if err != nil {
+		return fmt.Errorf("SaveComputedPrices begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, p := range prices {
+		var anterior sql.NullFloat64
+		err := tx.QueryRow(`SELECT preco FROM computed_prices WHERE produto = $1 FOR UPDATE`, p.Produto).Scan(&anterior)
+		if err != nil && err != sql.ErrNoRows {
+			return fmt.Errorf("SaveComputedPrices select: %w", err)
+		}
+
+		_, err = tx.Exec(`INSERT INTO computed_prices (produto, preco, preco_a_vista, preco_equilibrio,
+					custo_unitario, motivo, calculado_em, desconto_markdown)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+				ON CONFLICT (produto) DO UPDATE
+				SET preco = EXCLUDED.preco, preco_a_vista = EXCLUDED.preco_a_vista,
+					preco_equilibrio = EXCLUDED.preco_equilibrio, custo_unitario = EXCLUDED.custo_unitario,
+					motivo = EXCLUDED.motivo, calculado_em = EXCLUDED.calculado_em,
+					desconto_markdown = EXCLUDED.desconto_markdown`,
+			p.Produto, p.Preco, p.PrecoAVista, p.PrecoEquilibrio, p.CustoUnitario, p.Motivo, p.CalculadoEm,
+			p.DescontoMarkdown)
+		if err != nil {
+			return fmt.Errorf("SaveComputedPrices upsert: %w", err)
+		}
+
+		if anterior.Valid && math.Abs(anterior.Float64-p.Preco) < 0.005 {
+			continue
+		}
+		ev := entities.PriceChangedData{
+			Produto:     p.Produto,
+			Preco:       p.Preco,
+			PrecoAVista: p.PrecoAVista,
+			Motivo:      p.Motivo,
+			CalculadoEm: p.CalculadoEm,
+		}
+		if anterior.Valid {
+			ev.PrecoAnterior = &anterior.Float64
+		}
+		if err := insertEvent(tx, entities.EventoPrecoAlterado, ev.Produto, ev); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("SaveComputedPrices commit: %w", err)
+	}
+	return nil
+}
+
+// GetCostChanges → índice vigente (max_index) de cada produto contra repricing_cost_state;
+// produto novo no productscmp também aparece
+func (r *repricingRepositoryImpl) GetCostChanges() ([]entities.CostChange, error) {
+	rows, err := r.postgresDB.Query(`SELECT c.produto, c.max_index
+			FROM (SELECT produto, MAX(max_index) AS max_index FROM productscmp GROUP BY produto) c
+			LEFT JOIN repricing_cost_state s ON s.produto = c.produto
+			WHERE s.max_index IS DISTINCT FROM c.max_index
+			ORDER BY c.produto`)
+	if err != nil {
+		return nil, fmt.Errorf("GetCostChanges query: %w", err)
+	}
+	defer rows.Close()
+
+	var out []entities.CostChange
+	for rows.Next() {
+		var c entities.CostChange
+		if err := rows.Scan(&c.Produto, &c.Indice); err != nil {
+			return nil, fmt.Errorf("GetCostChanges scan: %w", err)
+		}
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
+
+// GetCostIndices → índice vigente (max_index) de cada produto informado
+func (r *repricingRepositoryImpl) GetCostIndices(produtos []string) ([]entities.CostChange, error) {
+	rows, err := r.postgresDB.Query(`SELECT produto, MAX(max_index) FROM productscmp
+			WHERE produto = ANY($1) GROUP BY produto`, pq.Array(produtos))
+	if err != nil {
+		return nil, fmt.Errorf("GetCostIndices query: %w", err)
+	}
+	defer rows.Close()
+
+	var out []entities.CostChange
+	for rows.Next() {
+		var c entities.CostChange
+		if err := rows.Scan(&c.Produto, &c.Indice); err != nil {
+			return nil, fmt.Errorf("GetCostIndices scan: %w", err)
+		}
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
+
+// MarkCostsSeen → upsert do índice recalculado por produto
+func (r *repricingRepositoryImpl) MarkCostsSeen(changes []entities.CostChange) error {
+	tx, err := r.postgresDB.Begin()
+	if err != nil {
+		return fmt.Errorf("MarkCostsSeen begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, c := range changes {
+		_, err := tx.Exec(`INSERT INTO repricing_cost_state (produto, max_index) VALUES ($1, $2)
+				ON CONFLICT (produto) DO UPDATE SET max_index = EXCLUDED.max_index`, c.Produto, c.Indice)
+		if err != nil {
+			return fmt.Errorf("MarkCostsSeen upsert: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("MarkCostsSeen commit: %w", err)
+	}
+	return nil
+}
+
+// parametersHashSQL resume os parâmetros lidos pelo cálculo (GetParameters); a migration
+// 0028 usa a mesma expressão
+const parametersHashSQL = `md5(concat_ws('|', lucro_adicional_desejado, lucro_padrao_desejado, imposto_federal,
+	operacao, custo_fixo, aliquota_pis, aliquota_cofins, rebate, fcp, redutor_padrao))`
+
+// GetParametersHash → vazio quando não há config_params ou recálculo registrado
+func (r *repricingRepositoryImpl) GetParametersHash() (string, string, error) {
+	var atual, registrado string
+	err := r.postgresDB.QueryRow(`SELECT
+			COALESCE((SELECT `+parametersHashSQL+` FROM config_params WHERE id = 1), ''),
+			COALESCE((SELECT hash FROM repricing_params_state WHERE id = 1), '')`).
+		Scan(&atual, &registrado)
+	if err != nil {
+		return "", "", fmt.Errorf("GetParametersHash scan: %w", err)
+	}
+	return atual, registrado, nil
+}
+
+// SaveParametersHash → registra os parâmetros do último recálculo completo
+func (r *repricingRepositoryImpl) SaveParametersHash(hash string) error {
+	_, err := r.postgresDB.Exec(`INSERT INTO repricing_params_state (id, hash) VALUES (1, $1)
+			ON CONFLICT (id) DO UPDATE SET hash = EXCLUDED.hash`, hash)
+	if err != nil {
+		return fmt.Errorf("SaveParametersHash upsert: %w", err)
+	}
+	return nil
+}
+
+// GetAllProducts → produtos distintos do productscmp
+func (r *repricingRepositoryImpl) GetAllProducts() ([]string, error) {
+	rows, err := r.postgresDB.Query(`SELECT DISTINCT produto FROM productscmp ORDER BY produto`)
+	if err != nil {
+		return nil, fmt.Errorf("GetAllProducts query: %w", err)
+	}
+	defer rows.Close()
+
+	produtos, err := scanStrings(rows)
+	if err != nil {
+		return nil, fmt.Errorf("GetAllProducts scan: %w", err)
+	}
+	return produtos, nil
+}
+
+// scanStrings lê uma coluna de texto de todas as linhas
+func scanStrings(rows *sql.Rows) ([]string, error) {
+	var out []string
+	for rows.Next() {
+		var s string
+		if err := rows.Scan(&s); err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,71 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"calculator/domain/entities"
+	"calculator/domain/repositories"
+)
+
+// salesRepositoryImpl implementa SalesRepository no SQL Server com a consulta configurada
+type salesRepositoryImpl struct {
+	sqlServerDB *sql.DB
+	query       string
+}
+
+// NewSalesRepository usa a consulta das vendas (SALES_QUERY), que devolve as colunas
+// documento, data_venda, sku, quantidade, preco_unitario, canal e uf; vazia desativa o histórico
+func NewSalesRepository(ms *sql.DB, query string) repositories.SalesRepository {
+	return &salesRepositoryImpl{sqlServerDB: ms, query: query}
+}
+
+// GetSales → linhas com quantidade positiva (devoluções ficam de fora)
+func (r *salesRepositoryImpl) GetSales(filter entities.SalesFilter) ([]entities.SaleLine, error) {
+	if r.query == "" {
+		return nil, fmt.Errorf("GetSales: %w: SALES_QUERY não configurada", repositories.ErrUnavailable)
+	}
+	q := `SELECT documento, data_venda, sku, quantidade, preco_unitario, COALESCE(canal, ''), COALESCE(uf, '')
+			FROM (` + r.query + `) AS vendas
+			WHERE quantidade > 0`
+	var args []interface{}
+	param := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("@p%d", len(args))
+	}
+	if !filter.De.IsZero() {
+		q += ` AND data_venda >= ` + param(filter.De)
+	}
+	if !filter.Ate.IsZero() {
+		q += ` AND data_venda <= ` + param(filter.Ate)
+	}
+	if filter.Canal != "" {
+		q += ` AND canal = ` + param(filter.Canal)
+	}
+	if len(filter.Skus) > 0 {
+		marcadores := make([]string, len(filter.Skus))
+		for i, sku := range filter.Skus {
+			marcadores[i] = param(sku)
+		}
+		q += ` AND sku IN (` + strings.Join(marcadores, ", ") + `)`
+	}
+	q += ` ORDER BY data_venda, documento`
+
+	rows, err := r.sqlServerDB.Query(q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("GetSales query: %w", err)
+	}
+	defer rows.Close()
+
+	var vendas []entities.SaleLine
+	for rows.Next() {
+		var s entities.SaleLine
+		if err := rows.Scan(&s.Documento, &s.Data, &s.Sku, &s.Quantidade, &s.PrecoUnitario, &s.Canal, &s.UF); err != nil {
+			return nil, fmt.Errorf("GetSales scan: %w", err)
+		}
+		s.Sku = strings.TrimSpace(s.Sku)
+		vendas = append(vendas, s)
+	}
+	return vendas, rows.Err()
+}
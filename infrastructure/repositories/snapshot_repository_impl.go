@@ -0,0 +1,621 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"calculator/domain/entities"
+	"calculator/domain/repositories"
+	"calculator/internal/firebird"
+)
+
+// sufixoSkuFire é o sufixo do SKU no np_comissao_frete (o mesmo de GetCostFire)
+const sufixoSkuFire = "_0_0_U"
+
+// snapshotRepositoryImpl implementa SnapshotRepository
+type snapshotRepositoryImpl struct {
+	postgresDB     *sql.DB
+	firebirdDB     *sql.DB
+	productRepo    repositories.ProductRepository
+	productService *firebird.ProductService
+}
+
+// NewSnapshotRepository carrega o snapshot dos bancos; o repositório de produtos e o
+// ProductService atendem as consultas que ficam fora da carga
+func NewSnapshotRepository(pg *sql.DB, fb *sql.DB, pr repositories.ProductRepository, ps *firebird.ProductService) repositories.SnapshotRepository {
+	return &snapshotRepositoryImpl{postgresDB: pg, firebirdDB: fb, productRepo: pr, productService: ps}
+}
+
+// componenteEscopo é uma linha do cost_components; nil vale para todos
+type componenteEscopo struct {
+	departamento *int
+	canal        *string
+	componentes  entities.CostComponents
+}
+
+// pricingSnapshot guarda os dados de uma carga; é somente leitura depois de montado
+type pricingSnapshot struct {
+	inner          repositories.ProductRepository
+	productService *firebird.ProductService
+	info           entities.SnapshotInfo
+	consultasBanco int64
+
+	params       entities.Parameters
+	cmp          map[string]entities.PriceInput
+	costFire     map[string]entities.CostFire
+	perfis       map[int]entities.PerfilFiscal
+	fci          map[string]entities.Fci
+	faixas       map[string][]entities.FaixaVolume
+	ultimaCompra map[string]entities.PurchaseEntry
+	custoTabela  map[string]float64
+	reposicao    map[string]float64
+	baseDept     map[int]string
+	dimensoes    map[string]entities.ProdutoDimensoes
+	dataRebates  string
+	rebates      map[string][]entities.RebateAgreement
+	pagamentos   map[string][]entities.PaymentProfile
+	componentes  []componenteEscopo
+	aliquotasUF  map[string]entities.AliquotaUF
+	// regrasST por produto e UF
+	regrasST map[string]map[string]entities.RegraST
+}
+
+// LoadSnapshot lê as tabelas inteiras; qualquer falha descarta a carga
+func (r *snapshotRepositoryImpl) LoadSnapshot() (repositories.PricingSnapshot, error) {
+	inicio := time.Now()
+	s := &pricingSnapshot{inner: r.productRepo, productService: r.productService}
+
+	var err error
+	if s.params, err = r.productRepo.GetParameters(); err != nil {
+		return nil, err
+	}
+	loaders := []func(*pricingSnapshot) error{
+		r.loadCmp,
+		r.loadCostFire,
+		r.loadFci,
+		r.loadFaixas,
+		r.loadUltimasCompras,
+		r.loadCustosTabela,
+		r.loadReposicao,
+		r.loadBasesDepartamento,
+		r.loadDimensoes,
+		r.loadPagamentos,
+		r.loadComponentes,
+		r.loadAliquotasUF,
+		r.loadRegrasST,
+	}
+	for _, load := range loaders {
+		if err := load(s); err != nil {
+			return nil, err
+		}
+	}
+	if s.perfis, err = r.productService.LoadPerfisFiscais(); err != nil {
+		return nil, fmt.Errorf("LoadSnapshot perfis fiscais: %w", err)
+	}
+	if err := r.loadRebates(s, inicio); err != nil {
+		return nil, err
+	}
+
+	s.info = entities.SnapshotInfo{
+		CarregadoEm:    inicio,
+		DuracaoCargaMs: time.Since(inicio).Milliseconds(),
+		Custos:         len(s.cmp),
+		CustosFire:     len(s.costFire),
+		PerfisFiscais:  len(s.perfis),
+		Fcis:           len(s.fci),
+		Rebates:        len(s.rebates),
+	}
+	return s, nil
+}
+
+// loadCmp → índice atual de cada produto no productscmp
+func (r *snapshotRepositoryImpl) loadCmp(s *pricingSnapshot) error {
+	rows, err := r.postgresDB.Query(`SELECT DISTINCT ON (produto) produto, cmp_icms, cmp_pis_cofins, cmp, cmp_nf
+			FROM productscmp
+			WHERE index = max_index
+			ORDER BY produto`)
+	if err != nil {
+		return fmt.Errorf("LoadSnapshot productscmp query: %w", err)
+	}
+	defer rows.Close()
+
+	s.cmp = map[string]entities.PriceInput{}
+	for rows.Next() {
+		var produto string
+		var pi entities.PriceInput
+		if err := rows.Scan(&produto, &pi.IcmsMedio, &pi.PisCofinsMedio, &pi.CustoMedioLiq, &pi.CustoMedioNF); err != nil {
+			return fmt.Errorf("LoadSnapshot productscmp scan: %w", err)
+		}
+		s.cmp[produto] = pi
+	}
+	return rows.Err()
+}
+
+// loadCostFire → comissão, frete e departamento do np_comissao_frete no Firebird
+func (r *snapshotRepositoryImpl) loadCostFire(s *pricingSnapshot) error {
+	custos, err := loadCostFireMap(r.firebirdDB)
+	if err != nil {
+		return fmt.Errorf("LoadSnapshot %w", err)
+	}
+	s.costFire = custos
+	return nil
+}
+
+// loadCostFireMap lê o np_comissao_frete inteiro, indexado pelo SKU sem o sufixo
+func loadCostFireMap(fb *sql.DB) (map[string]entities.CostFire, error) {
+	rows, err := fb.Query(`select n.sku, n.cod_produto, p.departamento, n.comissao, n.preco FROM
+		np_comissao_frete n join produtos p on p.cod_produto = n.cod_produto WHERE n.sku LIKE '%_0_0_U'`)
+	if err != nil {
+		return nil, fmt.Errorf("np_comissao_frete query: %w", err)
+	}
+	defer rows.Close()
+
+	custos := map[string]entities.CostFire{}
+	for rows.Next() {
+		var cf entities.CostFire
+		if err := rows.Scan(&cf.Sku, &cf.Cod_produto, &cf.Departamento, &cf.Comissao, &cf.Frete); err != nil {
+			return nil, fmt.Errorf("np_comissao_frete scan: %w", err)
+		}
+		cf.Sku = strings.TrimSpace(cf.Sku)
+		// O '_' do LIKE aceita qualquer caractere; só valem os SKUs com o sufixo exato
+		if !strings.HasSuffix(cf.Sku, sufixoSkuFire) {
+			continue
+		}
+		custos[strings.TrimSuffix(cf.Sku, sufixoSkuFire)] = cf
+	}
+	return custos, rows.Err()
+}
+
+// loadFci → fichas de conteúdo de importação
+func (r *snapshotRepositoryImpl) loadFci(s *pricingSnapshot) error {
+	rows, err := r.postgresDB.Query(`SELECT produto, numero_fci, conteudo_importacao, data_emissao FROM product_fci`)
+	if err != nil {
+		return fmt.Errorf("LoadSnapshot product_fci query: %w", err)
+	}
+	defer rows.Close()
+
+	s.fci = map[string]entities.Fci{}
+	for rows.Next() {
+		var f entities.Fci
+		if err := rows.Scan(&f.Produto, &f.Numero, &f.ConteudoImportacao, &f.DataEmissao); err != nil {
+			return fmt.Errorf("LoadSnapshot product_fci scan: %w", err)
+		}
+		s.fci[f.Produto] = f
+	}
+	return rows.Err()
+}
+
+// loadFaixas → faixas de quantidade por tipo de cliente em ordem crescente
+func (r *snapshotRepositoryImpl) loadFaixas(s *pricingSnapshot) error {
+	rows, err := r.postgresDB.Query(`SELECT tipo_cliente, quantidade_minima, lucro_desejado
+			FROM price_volume_tiers
+			ORDER BY tipo_cliente, quantidade_minima`)
+	if err != nil {
+		return fmt.Errorf("LoadSnapshot price_volume_tiers query: %w", err)
+	}
+	defer rows.Close()
+
+	s.faixas = map[string][]entities.FaixaVolume{}
+	for rows.Next() {
+		var f entities.FaixaVolume
+		if err := rows.Scan(&f.TipoCliente, &f.QuantidadeMinima, &f.LucroDesejado); err != nil {
+			return fmt.Errorf("LoadSnapshot price_volume_tiers scan: %w", err)
+		}
+		s.faixas[f.TipoCliente] = append(s.faixas[f.TipoCliente], f)
+	}
+	return rows.Err()
+}
+
+// loadUltimasCompras → entrada de compra mais recente de cada produto
+func (r *snapshotRepositoryImpl) loadUltimasCompras(s *pricingSnapshot) error {
+	rows, err := r.postgresDB.Query(`SELECT DISTINCT ON (produto) chave_nfe, numero_item, produto, fornecedor,
+				 data_emissao, quantidade, custo_nf, credito_icms, credito_pis_cofins, ipi
+			FROM purchase_entries
+			WHERE quantidade > 0
+			ORDER BY produto, data_emissao DESC, numero_item DESC`)
+	if err != nil {
+		return fmt.Errorf("LoadSnapshot purchase_entries query: %w", err)
+	}
+	defer rows.Close()
+
+	s.ultimaCompra = map[string]entities.PurchaseEntry{}
+	for rows.Next() {
+		var e entities.PurchaseEntry
+		if err := rows.Scan(&e.ChaveNfe, &e.NumeroItem, &e.Produto, &e.Fornecedor, &e.DataEmissao,
+			&e.Quantidade, &e.CustoNF, &e.CreditoIcms, &e.CreditoPisCofins, &e.Ipi); err != nil {
+			return fmt.Errorf("LoadSnapshot purchase_entries scan: %w", err)
+		}
+		s.ultimaCompra[e.Produto] = e
+	}
+	return rows.Err()
+}
+
+// loadCustosTabela → menor custo entre as tabelas de fornecedor vigentes de cada produto
+func (r *snapshotRepositoryImpl) loadCustosTabela(s *pricingSnapshot) error {
+	rows, err := r.postgresDB.Query(`SELECT produto, MIN(custo) FROM (
+				SELECT DISTINCT ON (produto, fornecedor) produto, custo
+				FROM supplier_price_list
+				WHERE vigencia_inicio <= CURRENT_DATE
+				ORDER BY produto, fornecedor, vigencia_inicio DESC
+			) vigentes
+			GROUP BY produto`)
+	if err != nil {
+		return fmt.Errorf("LoadSnapshot supplier_price_list query: %w", err)
+	}
+	defer rows.Close()
+
+	s.custoTabela = map[string]float64{}
+	for rows.Next() {
+		var produto string
+		var custo float64
+		if err := rows.Scan(&produto, &custo); err != nil {
+			return fmt.Errorf("LoadSnapshot supplier_price_list scan: %w", err)
+		}
+		s.custoTabela[produto] = custo
+	}
+	return rows.Err()
+}
+
+// loadReposicao → custos de reposição informados
+func (r *snapshotRepositoryImpl) loadReposicao(s *pricingSnapshot) error {
+	rows, err := r.postgresDB.Query(`SELECT produto, custo FROM replacement_costs`)
+	if err != nil {
+		return fmt.Errorf("LoadSnapshot replacement_costs query: %w", err)
+	}
+	defer rows.Close()
+
+	s.reposicao = map[string]float64{}
+	for rows.Next() {
+		var produto string
+		var custo float64
+		if err := rows.Scan(&produto, &custo); err != nil {
+			return fmt.Errorf("LoadSnapshot replacement_costs scan: %w", err)
+		}
+		s.reposicao[produto] = custo
+	}
+	return rows.Err()
+}
+
+// loadBasesDepartamento → base de custo configurada por departamento
+func (r *snapshotRepositoryImpl) loadBasesDepartamento(s *pricingSnapshot) error {
+	rows, err := r.postgresDB.Query(`SELECT departamento, base_custo FROM department_cost_basis`)
+	if err != nil {
+		return fmt.Errorf("LoadSnapshot department_cost_basis query: %w", err)
+	}
+	defer rows.Close()
+
+	s.baseDept = map[int]string{}
+	for rows.Next() {
+		var departamento int
+		var base string
+		if err := rows.Scan(&departamento, &base); err != nil {
+			return fmt.Errorf("LoadSnapshot department_cost_basis scan: %w", err)
+		}
+		s.baseDept[departamento] = base
+	}
+	return rows.Err()
+}
+
+// loadDimensoes → peso e medidas de envio
+func (r *snapshotRepositoryImpl) loadDimensoes(s *pricingSnapshot) error {
+	rows, err := r.postgresDB.Query(`SELECT produto, peso_kg, altura_cm, largura_cm, comprimento_cm FROM product_dimensions`)
+	if err != nil {
+		return fmt.Errorf("LoadSnapshot product_dimensions query: %w", err)
+	}
+	defer rows.Close()
+
+	s.dimensoes = map[string]entities.ProdutoDimensoes{}
+	for rows.Next() {
+		var d entities.ProdutoDimensoes
+		if err := rows.Scan(&d.Produto, &d.PesoKg, &d.AlturaCm, &d.LarguraCm, &d.ComprimentoCm); err != nil {
+			return fmt.Errorf("LoadSnapshot product_dimensions scan: %w", err)
+		}
+		s.dimensoes[d.Produto] = d
+	}
+	return rows.Err()
+}
+
+// loadRebates → acordos vigentes na data da carga por produto; o volume de cada
+// acordo é calculado uma vez e repetido para os produtos do fornecedor/marca
+func (r *snapshotRepositoryImpl) loadRebates(s *pricingSnapshot, data time.Time) error {
+	q := `WITH acordos AS (
+				SELECT a.id, a.fornecedor, a.marca, a.descricao, a.percentual, a.volume_minimo,
+					 a.vigencia_inicio, a.vigencia_fim,
+					 COALESCE((
+						SELECT SUM(e.custo_nf)
+						FROM purchase_entries e
+						LEFT JOIN product_suppliers pe ON pe.produto = e.produto
+						WHERE e.fornecedor = a.fornecedor
+						AND (a.marca IS NULL OR pe.marca = a.marca)
+						AND e.data_emissao >= a.vigencia_inicio
+						AND e.data_emissao < a.vigencia_fim + 1
+					 ), 0) AS volume
+				FROM rebate_agreements a
+				WHERE $1::date BETWEEN a.vigencia_inicio AND a.vigencia_fim
+			)
+			SELECT ps.produto, a.id, a.fornecedor, COALESCE(a.marca, ''), a.descricao, a.percentual,
+				 a.volume_minimo, a.vigencia_inicio, a.vigencia_fim, a.volume
+			FROM product_suppliers ps
+			JOIN acordos a ON a.fornecedor = ps.fornecedor
+				AND (a.marca IS NULL OR a.marca = ps.marca)`
+	rows, err := r.postgresDB.Query(q, data)
+	if err != nil {
+		return fmt.Errorf("LoadSnapshot rebate_agreements query: %w", err)
+	}
+	defer rows.Close()
+
+	s.dataRebates = data.Format("2006-01-02")
+	s.rebates = map[string][]entities.RebateAgreement{}
+	for rows.Next() {
+		var produto string
+		var a entities.RebateAgreement
+		if err := rows.Scan(&produto, &a.ID, &a.Fornecedor, &a.Marca, &a.Descricao, &a.Percentual, &a.VolumeMinimo,
+			&a.VigenciaInicio, &a.VigenciaFim, &a.VolumeComprado); err != nil {
+			return fmt.Errorf("LoadSnapshot rebate_agreements scan: %w", err)
+		}
+		s.rebates[produto] = append(s.rebates[produto], a)
+	}
+	return rows.Err()
+}
+
+// loadPagamentos → perfis de pagamento por canal
+func (r *snapshotRepositoryImpl) loadPagamentos(s *pricingSnapshot) error {
+	rows, err := r.postgresDB.Query(`SELECT canal, meio_pagamento, taxa, parcelas_sem_juros, taxa_antecipacao_mensal
+			FROM payment_profiles`)
+	if err != nil {
+		return fmt.Errorf("LoadSnapshot payment_profiles query: %w", err)
+	}
+	defer rows.Close()
+
+	s.pagamentos = map[string][]entities.PaymentProfile{}
+	for rows.Next() {
+		var p entities.PaymentProfile
+		if err := rows.Scan(&p.Canal, &p.MeioPagamento, &p.Taxa, &p.ParcelasSemJuros, &p.TaxaAntecipacaoMensal); err != nil {
+			return fmt.Errorf("LoadSnapshot payment_profiles scan: %w", err)
+		}
+		s.pagamentos[p.Canal] = append(s.pagamentos[p.Canal], p)
+	}
+	return rows.Err()
+}
+
+// loadComponentes → todas as linhas do cost_components; a prioridade é aplicada na consulta
+func (r *snapshotRepositoryImpl) loadComponentes(s *pricingSnapshot) error {
+	rows, err := r.postgresDB.Query(`SELECT departamento, canal,
+				 COALESCE('departamento ' || departamento::text, 'todos os departamentos')
+				 || ' / ' || COALESCE('canal ' || canal, 'todos os canais'),
+				 taxa_devolucao, taxa_chargeback, embalagem, fulfillment, provisao_custo_nf
+			FROM cost_components`)
+	if err != nil {
+		return fmt.Errorf("LoadSnapshot cost_components query: %w", err)
+	}
+	defer rows.Close()
+
+	s.componentes = nil
+	for rows.Next() {
+		var dept sql.NullInt64
+		var canal sql.NullString
+		var c entities.CostComponents
+		if err := rows.Scan(&dept, &canal, &c.Escopo, &c.TaxaDevolucao, &c.TaxaChargeback,
+			&c.Embalagem, &c.Fulfillment, &c.ProvisaoCustoNF); err != nil {
+			return fmt.Errorf("LoadSnapshot cost_components scan: %w", err)
+		}
+		ce := componenteEscopo{componentes: c}
+		if dept.Valid {
+			d := int(dept.Int64)
+			ce.departamento = &d
+		}
+		if canal.Valid {
+			ce.canal = &canal.String
+		}
+		s.componentes = append(s.componentes, ce)
+	}
+	return rows.Err()
+}
+
+// loadAliquotasUF → alíquotas de ICMS e FCP por UF de destino
+func (r *snapshotRepositoryImpl) loadAliquotasUF(s *pricingSnapshot) error {
+	rows, err := r.postgresDB.Query(`SELECT uf, aliquota_interna, aliquota_interestadual, fcp FROM icms_uf`)
+	if err != nil {
+		return fmt.Errorf("LoadSnapshot icms_uf query: %w", err)
+	}
+	defer rows.Close()
+
+	s.aliquotasUF = map[string]entities.AliquotaUF{}
+	for rows.Next() {
+		var a entities.AliquotaUF
+		if err := rows.Scan(&a.UF, &a.AliquotaInterna, &a.AliquotaInterestadual, &a.Fcp); err != nil {
+			return fmt.Errorf("LoadSnapshot icms_uf scan: %w", err)
+		}
+		s.aliquotasUF[a.UF] = a
+	}
+	return rows.Err()
+}
+
+// loadRegrasST → MVA da substituição tributária por produto e UF
+func (r *snapshotRepositoryImpl) loadRegrasST(s *pricingSnapshot) error {
+	rows, err := r.postgresDB.Query(`SELECT produto, uf, mva FROM icms_st`)
+	if err != nil {
+		return fmt.Errorf("LoadSnapshot icms_st query: %w", err)
+	}
+	defer rows.Close()
+
+	s.regrasST = map[string]map[string]entities.RegraST{}
+	for rows.Next() {
+		var st entities.RegraST
+		if err := rows.Scan(&st.Produto, &st.UF, &st.Mva); err != nil {
+			return fmt.Errorf("LoadSnapshot icms_st scan: %w", err)
+		}
+		if s.regrasST[st.Produto] == nil {
+			s.regrasST[st.Produto] = map[string]entities.RegraST{}
+		}
+		s.regrasST[st.Produto][st.UF] = st
+	}
+	return rows.Err()
+}
+
+// Info descreve a carga
+func (s *pricingSnapshot) Info() entities.SnapshotInfo {
+	return s.info
+}
+
+// ConsultasBanco é o total de consultas desta carga atendidas pelos bancos
+func (s *pricingSnapshot) ConsultasBanco() int64 {
+	return atomic.LoadInt64(&s.consultasBanco)
+}
+
+// viaBanco registra uma consulta que ficou fora da carga
+func (s *pricingSnapshot) viaBanco() repositories.ProductRepository {
+	atomic.AddInt64(&s.consultasBanco, 1)
+	return s.inner
+}
+
+// GetProductCmpValues → produto criado depois da carga vai ao banco
+func (s *pricingSnapshot) GetProductCmpValues(sku string) (entities.PriceInput, error) {
+	if pi, ok := s.cmp[sku]; ok {
+		return pi, nil
+	}
+	return s.viaBanco().GetProductCmpValues(sku)
+}
+
+// GetProductCmpValuesAt → índices históricos não ficam na carga
+func (s *pricingSnapshot) GetProductCmpValuesAt(sku string, data time.Time) (entities.PriceInput, error) {
+	return s.viaBanco().GetProductCmpValuesAt(sku, data)
+}
+
+// GetParameters → config_params da carga
+func (s *pricingSnapshot) GetParameters() (entities.Parameters, error) {
+	return s.params, nil
+}
+
+// GetCostFire → SKU sem linha na carga vai ao Firebird
+func (s *pricingSnapshot) GetCostFire(sku string) (entities.CostFire, error) {
+	if cf, ok := s.costFire[sku]; ok {
+		return cf, nil
+	}
+	return s.viaBanco().GetCostFire(sku)
+}
+
+// GetFci → nil quando o produto não tem ficha
+func (s *pricingSnapshot) GetFci(sku string) (*entities.Fci, error) {
+	f, ok := s.fci[sku]
+	if !ok {
+		return nil, nil
+	}
+	return &f, nil
+}
+
+// GetLastPurchase → última entrada de compra da carga
+func (s *pricingSnapshot) GetLastPurchase(sku string) (entities.PurchaseEntry, error) {
+	e, ok := s.ultimaCompra[sku]
+	if !ok {
+		return e, repositories.ErrNotFound
+	}
+	return e, nil
+}
+
+// GetSupplierListCost → menor custo de tabela vigente na carga
+func (s *pricingSnapshot) GetSupplierListCost(sku string) (float64, error) {
+	custo, ok := s.custoTabela[sku]
+	if !ok {
+		return 0, repositories.ErrNotFound
+	}
+	return custo, nil
+}
+
+// GetReplacementCost → custo de reposição da carga
+func (s *pricingSnapshot) GetReplacementCost(sku string) (float64, error) {
+	custo, ok := s.reposicao[sku]
+	if !ok {
+		return 0, repositories.ErrNotFound
+	}
+	return custo, nil
+}
+
+// GetDepartmentCostBasis → "" quando não configurada
+func (s *pricingSnapshot) GetDepartmentCostBasis(departamento int) (string, error) {
+	return s.baseDept[departamento], nil
+}
+
+// GetProductDimensions → peso e medidas da carga
+func (s *pricingSnapshot) GetProductDimensions(sku string) (entities.ProdutoDimensoes, error) {
+	d, ok := s.dimensoes[sku]
+	if !ok {
+		return d, repositories.ErrNotFound
+	}
+	return d, nil
+}
+
+// GetRebateAgreements → acordos da data da carga; outras datas vão ao banco
+func (s *pricingSnapshot) GetRebateAgreements(sku string, data time.Time) ([]entities.RebateAgreement, error) {
+	if data.Format("2006-01-02") != s.dataRebates {
+		return s.viaBanco().GetRebateAgreements(sku, data)
+	}
+	return s.rebates[sku], nil
+}
+
+// GetPaymentProfiles → perfis do canal ou do canal padrão
+func (s *pricingSnapshot) GetPaymentProfiles(canal string) ([]entities.PaymentProfile, error) {
+	if perfis, ok := s.pagamentos[canal]; ok {
+		return perfis, nil
+	}
+	return s.pagamentos[entities.CanalPadrao], nil
+}
+
+// GetCostComponents → prioridade: departamento+canal, canal, departamento, geral
+func (s *pricingSnapshot) GetCostComponents(departamento int, canal string) (entities.CostComponents, bool, error) {
+	melhor, prioridade := entities.CostComponents{}, -1
+	for _, ce := range s.componentes {
+		if ce.departamento != nil && *ce.departamento != departamento {
+			continue
+		}
+		if ce.canal != nil && *ce.canal != canal {
+			continue
+		}
+		p := 0
+		if ce.canal != nil {
+			p += 2
+		}
+		if ce.departamento != nil {
+			p++
+		}
+		if p > prioridade {
+			melhor, prioridade = ce.componentes, p
+		}
+	}
+	return melhor, prioridade >= 0, nil
+}
+
+// GetFaixasVolume → faixas do segmento em ordem crescente
+func (s *pricingSnapshot) GetFaixasVolume(tipoCliente string) ([]entities.FaixaVolume, error) {
+	return s.faixas[tipoCliente], nil
+}
+
+// GetAliquotaUF → alíquotas da UF na carga
+func (s *pricingSnapshot) GetAliquotaUF(uf string) (entities.AliquotaUF, error) {
+	a, ok := s.aliquotasUF[uf]
+	if !ok {
+		return a, repositories.ErrNotFound
+	}
+	return a, nil
+}
+
+// GetRegraST → MVA do produto para a UF na carga
+func (s *pricingSnapshot) GetRegraST(sku string, uf string) (entities.RegraST, error) {
+	st, ok := s.regrasST[sku][uf]
+	if !ok {
+		return st, repositories.ErrNotFound
+	}
+	return st, nil
+}
+
+// GetPerfilFiscal → produto sem perfil na carga consulta o Firebird
+func (s *pricingSnapshot) GetPerfilFiscal(produto int) (entities.PerfilFiscal, error) {
+	if perfil, ok := s.perfis[produto]; ok {
+		return perfil, nil
+	}
+	atomic.AddInt64(&s.consultasBanco, 1)
+	return s.productService.GetPerfilFiscal(produto)
+}
@@ -0,0 +1,118 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+
+	"calculator/domain/entities"
+	"calculator/domain/repositories"
+)
+
+// sysuniTablesDDL cria as tabelas de destino no sysuni quando ainda não existem
+const sysuniTablesDDL = `
+IF OBJECT_ID('dbo.price_runs', 'U') IS NULL
+	CREATE TABLE dbo.price_runs (
+		id            VARCHAR(36) NOT NULL PRIMARY KEY,
+		criado_em     DATETIMEOFFSET NOT NULL,
+		usuario       NVARCHAR(100) NOT NULL,
+		dry_run       BIT NOT NULL,
+		status        VARCHAR(20) NOT NULL,
+		revertido_por NVARCHAR(100) NULL,
+		publicado_em  DATETIMEOFFSET NOT NULL
+	);
+IF OBJECT_ID('dbo.price_results', 'U') IS NULL
+	CREATE TABLE dbo.price_results (
+		run_id         VARCHAR(36) NOT NULL,
+		tabela         VARCHAR(10) NOT NULL,
+		sku            VARCHAR(50) NOT NULL,
+		produto        INT NOT NULL,
+		preco_anterior DECIMAL(15,4) NOT NULL,
+		preco_novo     DECIMAL(15,4) NOT NULL,
+		CONSTRAINT pk_price_results PRIMARY KEY (run_id, tabela, sku)
+	);`
+
+// sysuniPublisherImpl implementa PricePublisher no SQL Server (banco sysuni)
+type sysuniPublisherImpl struct {
+	sqlServerDB *sql.DB
+	mu          sync.Mutex
+	prepared    bool
+}
+
+// NewSysuniPublisher constrói o publicador do sysuni
+func NewSysuniPublisher(ms *sql.DB) repositories.PricePublisher {
+	return &sysuniPublisherImpl{sqlServerDB: ms}
+}
+
+// PublishPriceRun → MERGE do cabeçalho e dos itens em uma transação
+func (p *sysuniPublisherImpl) PublishPriceRun(ctx context.Context, run entities.PriceWriteRun) error {
+	if err := p.ensureTables(ctx); err != nil {
+		return err
+	}
+
+	tx, err := p.sqlServerDB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("PublishPriceRun begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `MERGE dbo.price_runs AS t
+		USING (SELECT @p1 AS id) AS s ON t.id = s.id
+		WHEN MATCHED THEN
+			UPDATE SET status = @p5, revertido_por = NULLIF(@p6, ''), publicado_em = SYSDATETIMEOFFSET()
+		WHEN NOT MATCHED THEN
+			INSERT (id, criado_em, usuario, dry_run, status, revertido_por, publicado_em)
+			VALUES (@p1, @p2, @p3, @p4, @p5, NULLIF(@p6, ''), SYSDATETIMEOFFSET());`,
+		run.ID, run.CriadoEm, run.Usuario, run.DryRun, run.Status, run.RevertidoPor)
+	if err != nil {
+		return fmt.Errorf("PublishPriceRun merge run: %w", err)
+	}
+
+	for _, c := range run.Alteracoes {
+		_, err := tx.ExecContext(ctx, `MERGE dbo.price_results AS t
+			USING (SELECT @p1 AS run_id, @p2 AS tabela, @p3 AS sku) AS s
+				ON t.run_id = s.run_id AND t.tabela = s.tabela AND t.sku = s.sku
+			WHEN MATCHED THEN
+				UPDATE SET produto = @p4, preco_anterior = @p5, preco_novo = @p6
+			WHEN NOT MATCHED THEN
+				INSERT (run_id, tabela, sku, produto, preco_anterior, preco_novo)
+				VALUES (@p1, @p2, @p3, @p4, @p5, @p6);`,
+			run.ID, c.Tabela, c.Sku, c.Produto, c.PrecoAnterior, c.PrecoNovo)
+		if err != nil {
+			return fmt.Errorf("PublishPriceRun merge result %s: %w", c.Sku, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("PublishPriceRun commit: %w", err)
+	}
+	return nil
+}
+
+// ensureTables cria as tabelas na primeira publicação bem-sucedida
+func (p *sysuniPublisherImpl) ensureTables(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.prepared {
+		return nil
+	}
+	if _, err := p.sqlServerDB.ExecContext(ctx, sysuniTablesDDL); err != nil {
+		return fmt.Errorf("PublishPriceRun create tables: %w", err)
+	}
+	p.prepared = true
+	return nil
+}
+
+// noopPublisherImpl é usado quando a publicação está desativada
+type noopPublisherImpl struct{}
+
+// NewNoopPricePublisher constrói um publicador que não grava nada
+func NewNoopPricePublisher() repositories.PricePublisher {
+	return noopPublisherImpl{}
+}
+
+// PublishPriceRun não faz nada
+func (noopPublisherImpl) PublishPriceRun(context.Context, entities.PriceWriteRun) error {
+	return nil
+}
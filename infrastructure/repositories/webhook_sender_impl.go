@@ -0,0 +1,52 @@
+package repositories
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"calculator/domain/entities"
+	"calculator/domain/repositories"
+	"calculator/internal/webhook"
+)
+
+// webhookTimeout limita cada envio; deve ser menor que a reserva da entrega
+const webhookTimeout = 15 * time.Second
+
+// httpWebhookSender implementa WebhookSender com POST JSON assinado
+type httpWebhookSender struct {
+	client *http.Client
+}
+
+// NewHTTPWebhookSender constrói o enviador de webhooks
+func NewHTTPWebhookSender() repositories.WebhookSender {
+	return &httpWebhookSender{client: &http.Client{Timeout: webhookTimeout}}
+}
+
+// Send → POST com X-Signature = HMAC-SHA256("timestamp.corpo") no segredo do destino
+func (s *httpWebhookSender) Send(d entities.WebhookDelivery, corpo []byte) error {
+	req, err := http.NewRequest(http.MethodPost, d.URL, bytes.NewReader(corpo))
+	if err != nil {
+		return fmt.Errorf("Send request: %w", err)
+	}
+	ts := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhook.HeaderEvento, strconv.FormatInt(d.Evento.ID, 10))
+	req.Header.Set(webhook.HeaderTipo, d.Evento.Tipo)
+	req.Header.Set(webhook.HeaderTimestamp, strconv.FormatInt(ts, 10))
+	req.Header.Set(webhook.HeaderAssinatura, webhook.Sign(d.Segredo, ts, corpo))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("Send post: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("Send: HTTP %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"calculator/domain/entities"
+	"calculator/domain/usecase"
+)
+
+// chaveIdentidade guarda a identidade autenticada no contexto da requisição
+type chaveIdentidade struct{}
+
+// AuthController autentica as rotas que agem em nome de um usuário
+type AuthController struct {
+	authUC usecase.AuthUseCase
+}
+
+// NewAuthController cria uma nova instância de AuthController
+func NewAuthController(uc usecase.AuthUseCase) *AuthController {
+	return &AuthController{authUC: uc}
+}
+
+// Require exige o cabeçalho Authorization: Bearer <token> e responde 401 sem ele
+func (ac *AuthController) Require(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimSpace(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+		id, err := ac.authUC.Authenticate(token)
+		if err != nil {
+			writeError(w, "Error authenticating request:", err)
+			return
+		}
+		next(w, r.WithContext(context.WithValue(r.Context(), chaveIdentidade{}, id)))
+	}
+}
+
+// RequireRole responde 403 quando o usuário autenticado por Require não tem nenhum dos papéis
+func (ac *AuthController) RequireRole(papeis ...string) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			id := identidade(r)
+			for _, p := range papeis {
+				if id.TemPapel(p) {
+					next(w, r)
+					return
+				}
+			}
+			writeError(w, "Error authorizing request:",
+				fmt.Errorf("%w: exige o papel %s", usecase.ErrForbidden, strings.Join(papeis, " ou ")))
+		}
+	}
+}
+
+// identidade devolve o usuário autenticado por Require
+func identidade(r *http.Request) entities.Identidade {
+	id, _ := r.Context().Value(chaveIdentidade{}).(entities.Identidade)
+	return id
+}
@@ -0,0 +1,67 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"calculator/domain/entities"
+	"calculator/domain/usecase"
+)
+
+// BacktestController disponibiliza o backtesting de parâmetros contra as vendas
+type BacktestController struct {
+	backtestUC usecase.BacktestUseCase
+}
+
+// NewBacktestController cria uma nova instância de BacktestController
+func NewBacktestController(uc usecase.BacktestUseCase) *BacktestController {
+	return &BacktestController{backtestUC: uc}
+}
+
+// POST /backtest
+// {"de":"2025-01-01","ate":"2025-03-31","skus":["1234"],"canal":"","elasticidade":-1.5,
+// "parametros":{"lucro_padrao_desejado":0.12}}; os parâmetros omitidos ficam os atuais
+func (bc *BacktestController) RunHandler(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		De           string                       `json:"de"`
+		Ate          string                       `json:"ate"`
+		Skus         []string                     `json:"skus"`
+		Canal        string                       `json:"canal"`
+		Elasticidade *float64                     `json:"elasticidade"`
+		Parametros   *entities.ParametersOverride `json:"parametros"`
+	}
+	// Campo desconhecido em parametros seria ignorado e o backtest repetiria os atuais
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&body); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	de, err := time.ParseInLocation("2006-01-02", body.De, time.Local)
+	if err != nil {
+		http.Error(w, "invalid de value", http.StatusBadRequest)
+		return
+	}
+	ate, err := time.ParseInLocation("2006-01-02", body.Ate, time.Local)
+	if err != nil {
+		http.Error(w, "invalid ate value", http.StatusBadRequest)
+		return
+	}
+
+	res, err := bc.backtestUC.RunBacktest(entities.BacktestRequest{
+		De:           de,
+		Ate:          ate.Add(24*time.Hour - time.Nanosecond),
+		Skus:         body.Skus,
+		Canal:        body.Canal,
+		Parametros:   body.Parametros,
+		Elasticidade: body.Elasticidade,
+	})
+	if err != nil {
+		writeError(w, "Error running backtest:", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"calculator/domain/entities"
+	"calculator/domain/usecase"
+)
+
+// CompetitorController disponibiliza a importação de preços de concorrentes e o posicionamento
+type CompetitorController struct {
+	competitorUC usecase.CompetitorUseCase
+}
+
+// NewCompetitorController cria uma nova instância de CompetitorController
+func NewCompetitorController(uc usecase.CompetitorUseCase) *CompetitorController {
+	return &CompetitorController{competitorUC: uc}
+}
+
+// POST /competitorPrices/import
+// Recebe no corpo o CSV exportado pela ferramenta de monitoramento
+func (cc *CompetitorController) ImportHandler(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxStockUpload)
+
+	res, err := cc.competitorUC.ImportCompetitorPrices(r.Body)
+	if err != nil {
+		writeError(w, "Error importing competitor prices:", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(res)
+}
+
+// /positioning?skus=1234,5678&dias=7&ancorado=true&descontoAncora=0.02&margemMinima=0.05
+func (cc *CompetitorController) PositioningHandler(w http.ResponseWriter, r *http.Request) {
+	skus := splitList(r.URL.Query().Get("skus"))
+	if len(skus) == 0 {
+		http.Error(w, "skus is required", http.StatusBadRequest)
+		return
+	}
+
+	var opts entities.PositioningOptions
+	if v := r.URL.Query().Get("dias"); v != "" {
+		dias, err := strconv.Atoi(v)
+		if err != nil || dias <= 0 {
+			http.Error(w, "invalid dias value", http.StatusBadRequest)
+			return
+		}
+		opts.Dias = dias
+	}
+	opts.Ancorado = r.URL.Query().Get("ancorado") == "true"
+	var margemMinima float64
+	for name, dst := range map[string]*float64{
+		"descontoAncora": &opts.DescontoAncora,
+		"margemMinima":   &margemMinima,
+	} {
+		if v := r.URL.Query().Get(name); v != "" {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				http.Error(w, "invalid "+name+" value", http.StatusBadRequest)
+				return
+			}
+			*dst = f
+			if name == "margemMinima" {
+				opts.MargemMinima = &margemMinima
+			}
+		}
+	}
+
+	relatorio, err := cc.competitorUC.Positioning(skus, opts)
+	if err != nil {
+		writeError(w, "Error building positioning report:", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(relatorio)
+}
@@ -0,0 +1,103 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"calculator/domain/entities"
+	"calculator/domain/usecase"
+)
+
+// CostController disponibiliza o histórico de custo médio e as movimentações de estoque
+type CostController struct {
+	cmpUC usecase.CmpUseCase
+}
+
+// NewCostController cria uma nova instância de CostController
+func NewCostController(uc usecase.CmpUseCase) *CostController {
+	return &CostController{cmpUC: uc}
+}
+
+// /costHistory?sku=1234&de=2025-01-01&ate=2025-03-31
+func (cc *CostController) CostHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	sku := r.URL.Query().Get("sku")
+	if sku == "" {
+		http.Error(w, "sku is required", http.StatusBadRequest)
+		return
+	}
+
+	de, err := parseDateParam(r, "de", false)
+	if err != nil {
+		http.Error(w, "invalid de value", http.StatusBadRequest)
+		return
+	}
+	ate, err := parseDateParam(r, "ate", true)
+	if err != nil {
+		http.Error(w, "invalid ate value", http.StatusBadRequest)
+		return
+	}
+
+	hist, err := cc.cmpUC.GetCostHistory(sku, de, ate)
+	if err != nil {
+		writeError(w, "Error loading cost history:", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"sku":       sku,
+		"historico": hist,
+	})
+}
+
+// POST /stock/movements
+func (cc *CostController) StockMovementsHandler(w http.ResponseWriter, r *http.Request) {
+	var movs []entities.StockMovement
+	if err := json.NewDecoder(r.Body).Decode(&movs); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := cc.cmpUC.RegisterMovements(movs); err != nil {
+		writeError(w, "Error registering stock movements:", err)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+}
+
+// POST /replacementCosts
+func (cc *CostController) ReplacementCostsHandler(w http.ResponseWriter, r *http.Request) {
+	var costs []entities.ReplacementCost
+	if err := json.NewDecoder(r.Body).Decode(&costs); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	// O usuário vem da autenticação, nunca do corpo
+	usuario := identidade(r).Usuario
+	for i := range costs {
+		costs[i].Usuario = usuario
+	}
+
+	if err := cc.cmpUC.RegisterReplacementCosts(costs); err != nil {
+		writeError(w, "Error registering replacement costs:", err)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+}
+
+// parseDateParam lê uma data AAAA-MM-DD da query string; com endOfDay a data cobre o dia inteiro
+func parseDateParam(r *http.Request, name string, endOfDay bool) (time.Time, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	d, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		d = d.Add(24*time.Hour - time.Nanosecond)
+	}
+	return d, nil
+}
@@ -0,0 +1,103 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"calculator/domain/entities"
+	"calculator/domain/usecase"
+
+	"github.com/gorilla/mux"
+)
+
+// ElasticityController disponibiliza a estimativa de elasticidade e a sugestão de preço
+type ElasticityController struct {
+	elasticityUC usecase.ElasticityUseCase
+}
+
+// NewElasticityController cria uma nova instância de ElasticityController
+func NewElasticityController(uc usecase.ElasticityUseCase) *ElasticityController {
+	return &ElasticityController{elasticityUC: uc}
+}
+
+// POST /elasticity/estimate?usuario=ana
+// {"de":"2024-01-01","ate":"2024-12-31","skus":["1234"]}
+// A estimativa roda como job; o resultado sai em GET /elasticity/estimate/{id}
+func (ec *ElasticityController) EstimateHandler(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		De   string   `json:"de"`
+		Ate  string   `json:"ate"`
+		Skus []string `json:"skus"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	de, err := time.ParseInLocation("2006-01-02", body.De, time.Local)
+	if err != nil {
+		http.Error(w, "invalid de value", http.StatusBadRequest)
+		return
+	}
+	ate, err := time.ParseInLocation("2006-01-02", body.Ate, time.Local)
+	if err != nil {
+		http.Error(w, "invalid ate value", http.StatusBadRequest)
+		return
+	}
+
+	job, err := ec.elasticityUC.StartEstimate(r.URL.Query().Get("usuario"), entities.ElasticityRequest{
+		De:   de,
+		Ate:  ate.Add(24*time.Hour - time.Nanosecond),
+		Skus: body.Skus,
+	})
+	if err != nil {
+		writeError(w, "Error starting elasticity estimate:", err)
+		return
+	}
+	writeJobAccepted(w, job, "/elasticity/estimate/"+job.ID)
+}
+
+// GET /elasticity/estimate/{id}
+// Enquanto o job não termina devolve o próprio job
+func (ec *ElasticityController) ResultHandler(w http.ResponseWriter, r *http.Request) {
+	job, estimativas, err := ec.elasticityUC.GetEstimate(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, "Error loading elasticity estimate:", err)
+		return
+	}
+	if job.Status != entities.JobConcluido {
+		writeJobStatus(w, job)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(estimativas)
+}
+
+// /priceSuggestion?sku=1234&variacaoMaxima=0.2
+func (ec *ElasticityController) SuggestionHandler(w http.ResponseWriter, r *http.Request) {
+	sku := r.URL.Query().Get("sku")
+	if sku == "" {
+		http.Error(w, "sku is required", http.StatusBadRequest)
+		return
+	}
+	var variacao float64
+	if v := r.URL.Query().Get("variacaoMaxima"); v != "" {
+		var err error
+		variacao, err = strconv.ParseFloat(v, 64)
+		if err != nil || variacao <= 0 {
+			http.Error(w, "invalid variacaoMaxima value", http.StatusBadRequest)
+			return
+		}
+	}
+
+	s, err := ec.elasticityUC.SuggestPrice(sku, variacao)
+	if err != nil {
+		writeError(w, "Error suggesting price:", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s)
+}
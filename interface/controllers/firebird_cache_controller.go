@@ -0,0 +1,38 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"calculator/domain/usecase"
+)
+
+// FirebirdCacheController disponibiliza a situação e a atualização do cache do Firebird
+type FirebirdCacheController struct {
+	cacheUC usecase.FirebirdCacheUseCase
+}
+
+// NewFirebirdCacheController cria uma nova instância de FirebirdCacheController
+func NewFirebirdCacheController(uc usecase.FirebirdCacheUseCase) *FirebirdCacheController {
+	return &FirebirdCacheController{cacheUC: uc}
+}
+
+// GET /firebirdCache
+// Última atualização, idade máxima aceita e quantas leituras usaram o cache
+func (fc *FirebirdCacheController) StatusHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(fc.cacheUC.Status())
+}
+
+// POST /firebirdCache/refresh
+// Atualiza o cache com a leitura atual do Firebird sem esperar o intervalo
+func (fc *FirebirdCacheController) RefreshHandler(w http.ResponseWriter, r *http.Request) {
+	info, err := fc.cacheUC.Refresh()
+	if err != nil {
+		writeError(w, "Error refreshing Firebird cache:", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(info)
+}
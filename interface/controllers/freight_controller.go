@@ -0,0 +1,38 @@
+package controllers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"calculator/domain/usecase"
+)
+
+// FreightController disponibiliza a manutenção das tabelas de frete
+type FreightController struct {
+	freightUC usecase.FreightUseCase
+}
+
+// NewFreightController cria uma nova instância de FreightController
+func NewFreightController(uc usecase.FreightUseCase) *FreightController {
+	return &FreightController{freightUC: uc}
+}
+
+// POST /freight/reload
+// Recarrega as tabelas de frete sem reiniciar o serviço
+func (fc *FreightController) ReloadHandler(w http.ResponseWriter, r *http.Request) {
+	t, err := fc.freightUC.Reload()
+	if err != nil {
+		log.Println("Error reloading freight tables:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"fonte":        t.Fonte,
+		"faixas":       len(t.Faixas),
+		"subsidios":    len(t.Subsidios),
+		"carregado_em": t.CarregadoEm,
+	})
+}
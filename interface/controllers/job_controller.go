@@ -0,0 +1,141 @@
+package controllers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"calculator/domain/entities"
+	"calculator/domain/usecase"
+
+	"github.com/gorilla/mux"
+)
+
+// maxJobBody limita o corpo do pedido de job (lista de SKUs)
+const maxJobBody = 16 << 20
+
+// JobController disponibiliza os jobs assíncronos de cálculo em lote
+type JobController struct {
+	jobUC usecase.JobUseCase
+}
+
+// NewJobController cria uma nova instância de JobController
+func NewJobController(uc usecase.JobUseCase) *JobController {
+	return &JobController{jobUC: uc}
+}
+
+// POST /jobs
+// {"usuario":"ana","skus":["1234","5678"],"tipo_cliente":"contribuinte","uf":"SP"}
+func (jc *JobController) CreateHandler(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxJobBody)
+	var req entities.PriceJobRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	req.Usuario = identidade(r).Usuario
+
+	job, err := jc.jobUC.CreatePriceJob(req)
+	if err != nil {
+		writeError(w, "Error creating job:", err)
+		return
+	}
+	writeJobAccepted(w, job, "/jobs/"+job.ID)
+}
+
+// writeJobAccepted responde 202 com o job e o endereço do resultado
+func writeJobAccepted(w http.ResponseWriter, job entities.PriceJob, location string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", location)
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(job)
+}
+
+// writeJobStatus devolve o relatório ainda sem resultado: 202 enquanto em andamento e
+// 200 quando cancelado ou com falha, com o motivo no job
+func writeJobStatus(w http.ResponseWriter, job entities.PriceJob) {
+	w.Header().Set("Content-Type", "application/json")
+	if job.Status == entities.JobPendente || job.Status == entities.JobExecutando {
+		w.WriteHeader(http.StatusAccepted)
+	}
+	json.NewEncoder(w).Encode(job)
+}
+
+// GET /jobs/{id}
+func (jc *JobController) GetHandler(w http.ResponseWriter, r *http.Request) {
+	job, err := jc.jobUC.GetJob(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, "Error loading job:", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(job)
+}
+
+// POST /jobs/{id}/cancel
+func (jc *JobController) CancelHandler(w http.ResponseWriter, r *http.Request) {
+	job, err := jc.jobUC.CancelJob(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, "Error canceling job:", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(job)
+}
+
+// GET /jobs/{id}/results?format=csv
+// Os resultados são transmitidos à medida que são lidos; itens pendentes saem com status pendente
+func (jc *JobController) ResultsHandler(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	if _, err := jc.jobUC.GetJob(id); err != nil {
+		writeError(w, "Error loading job:", err)
+		return
+	}
+
+	if r.URL.Query().Get("format") == "csv" {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="job-`+id+`.csv"`)
+		out := csv.NewWriter(w)
+		out.Comma = ';'
+		out.Write([]string{"posicao", "sku", "status", "preco", "preco_a_vista", "preco_equilibrio", "erro"})
+		err := jc.jobUC.EachResult(id, func(it entities.PriceJobItem) error {
+			return out.Write([]string{
+				strconv.Itoa(it.Posicao),
+				it.Sku,
+				it.Status,
+				formatNum(it.Preco),
+				formatNum(it.PrecoAVista),
+				formatNum(it.PrecoEquilibrio),
+				it.Erro,
+			})
+		})
+		out.Flush()
+		if err != nil {
+			// O status já foi enviado; o arquivo sai incompleto
+			log.Println("Error streaming job results:", err)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte("["))
+	enc := json.NewEncoder(w)
+	primeiro := true
+	err := jc.jobUC.EachResult(id, func(it entities.PriceJobItem) error {
+		if !primeiro {
+			w.Write([]byte(","))
+		}
+		primeiro = false
+		return enc.Encode(it)
+	})
+	if err != nil {
+		// Sem o fechamento do array o cliente percebe a resposta incompleta
+		log.Println("Error streaming job results:", err)
+		return
+	}
+	w.Write([]byte("]"))
+}
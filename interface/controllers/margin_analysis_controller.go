@@ -0,0 +1,69 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"calculator/domain/entities"
+	"calculator/domain/usecase"
+
+	"github.com/gorilla/mux"
+)
+
+// MarginAnalysisController disponibiliza a análise de margem realizada x prevista
+type MarginAnalysisController struct {
+	marginUC usecase.MarginAnalysisUseCase
+}
+
+// NewMarginAnalysisController cria uma nova instância de MarginAnalysisController
+func NewMarginAnalysisController(uc usecase.MarginAnalysisUseCase) *MarginAnalysisController {
+	return &MarginAnalysisController{marginUC: uc}
+}
+
+// POST /marginAnalysis?usuario=ana&de=2025-01-01&ate=2025-03-31&skus=1234,5678&canal=mercado_livre
+// A análise roda como job; o resultado sai em GET /marginAnalysis/{id}
+func (mc *MarginAnalysisController) AnalyzeHandler(w http.ResponseWriter, r *http.Request) {
+	de, err := parseDateParam(r, "de", false)
+	if err != nil {
+		http.Error(w, "invalid de value", http.StatusBadRequest)
+		return
+	}
+	ate, err := parseDateParam(r, "ate", true)
+	if err != nil {
+		http.Error(w, "invalid ate value", http.StatusBadRequest)
+		return
+	}
+	if de.IsZero() || ate.IsZero() {
+		http.Error(w, "de and ate are required", http.StatusBadRequest)
+		return
+	}
+
+	job, err := mc.marginUC.StartAnalysis(r.URL.Query().Get("usuario"), entities.SalesFilter{
+		De:    de,
+		Ate:   ate,
+		Skus:  splitList(r.URL.Query().Get("skus")),
+		Canal: r.URL.Query().Get("canal"),
+	})
+	if err != nil {
+		writeError(w, "Error starting margin analysis:", err)
+		return
+	}
+	writeJobAccepted(w, job, "/marginAnalysis/"+job.ID)
+}
+
+// GET /marginAnalysis/{id}
+// Enquanto o job não termina devolve o próprio job
+func (mc *MarginAnalysisController) ResultHandler(w http.ResponseWriter, r *http.Request) {
+	job, analise, err := mc.marginUC.GetAnalysis(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, "Error loading margin analysis:", err)
+		return
+	}
+	if job.Status != entities.JobConcluido {
+		writeJobStatus(w, job)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(analise)
+}
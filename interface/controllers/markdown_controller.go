@@ -0,0 +1,97 @@
+package controllers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"math"
+	"net/http"
+	"strconv"
+
+	"calculator/domain/entities"
+	"calculator/domain/usecase"
+)
+
+// maxStockUpload limita o tamanho do arquivo de estoque
+const maxStockUpload = 16 << 20
+
+// MarkdownController disponibiliza a lista de remarcação por idade do estoque
+type MarkdownController struct {
+	markdownUC usecase.MarkdownUseCase
+}
+
+// NewMarkdownController cria uma nova instância de MarkdownController
+func NewMarkdownController(uc usecase.MarkdownUseCase) *MarkdownController {
+	return &MarkdownController{markdownUC: uc}
+}
+
+// GET /markdown?format=csv
+// Usa o estoque calculado pelo serviço (índice de custo médio mais movimentações)
+func (mc *MarkdownController) ProposeHandler(w http.ResponseWriter, r *http.Request) {
+	propostas, err := mc.markdownUC.ProposeMarkdowns(nil)
+	if err != nil {
+		writeError(w, "Error proposing markdowns:", err)
+		return
+	}
+	writeMarkdowns(w, r, propostas)
+}
+
+// POST /markdown?format=csv
+// Recebe o estoque em CSV no corpo: produto;quantidade;data_entrada
+func (mc *MarkdownController) ProposeFromFileHandler(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxStockUpload)
+
+	positions, err := mc.markdownUC.ParseStockPositions(r.Body)
+	if err != nil {
+		writeError(w, "Error reading stock file:", err)
+		return
+	}
+
+	propostas, err := mc.markdownUC.ProposeMarkdowns(positions)
+	if err != nil {
+		writeError(w, "Error proposing markdowns:", err)
+		return
+	}
+	writeMarkdowns(w, r, propostas)
+}
+
+// writeMarkdowns responde em JSON ou, com format=csv, em CSV separado por ";"
+func writeMarkdowns(w http.ResponseWriter, r *http.Request, propostas []entities.MarkdownProposal) {
+	if r.URL.Query().Get("format") != "csv" {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(propostas)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="markdown.csv"`)
+	out := csv.NewWriter(w)
+	out.Comma = ';'
+	out.Write([]string{"sku", "quantidade", "data_entrada", "dias_estoque", "desconto_regra",
+		"preco_atual", "preco_proposto", "desconto_aplicado", "preco_equilibrio", "limitado_equilibrio",
+		"margem_atual", "margem_proposta", "impacto_margem_unidade", "impacto_margem_total", "erro"})
+	for _, p := range propostas {
+		out.Write([]string{
+			p.Sku,
+			formatNum(p.Quantidade),
+			p.DataEntrada.Format("2006-01-02"),
+			strconv.Itoa(p.DiasEstoque),
+			formatNum(p.DescontoRegra),
+			formatNum(p.PrecoAtual),
+			formatNum(p.PrecoProposto),
+			formatNum(p.DescontoAplicado),
+			formatNum(p.PrecoEquilibrio),
+			strconv.FormatBool(p.LimitadoEquilibrio),
+			formatNum(p.MargemAtual),
+			formatNum(p.MargemProposta),
+			formatNum(p.ImpactoMargemUnidade),
+			formatNum(p.ImpactoMargemTotal),
+			p.Erro,
+		})
+	}
+	out.Flush()
+}
+
+// formatNum escreve números no CSV com até quatro casas decimais
+func formatNum(v float64) string {
+	return strconv.FormatFloat(math.Round(v*10000)/10000, 'f', -1, 64)
+}
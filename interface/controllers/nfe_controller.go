@@ -0,0 +1,89 @@
+package controllers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"calculator/domain/entities"
+	"calculator/domain/usecase"
+)
+
+// maxNfeUpload limita o tamanho do upload de XMLs
+const maxNfeUpload = 32 << 20
+
+// NfeController disponibiliza a importação de NF-e de compra
+type NfeController struct {
+	nfeUC usecase.NfeImportUseCase
+}
+
+// NewNfeController cria uma nova instância de NfeController
+func NewNfeController(uc usecase.NfeImportUseCase) *NfeController {
+	return &NfeController{nfeUC: uc}
+}
+
+// POST /nfe/import
+// Aceita o XML no corpo da requisição ou vários arquivos no campo "xml" (multipart/form-data)
+func (nc *NfeController) ImportHandler(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxNfeUpload)
+
+	var arquivos [][]byte
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		if err := r.ParseMultipartForm(maxNfeUpload); err != nil {
+			http.Error(w, "invalid multipart body", http.StatusBadRequest)
+			return
+		}
+		for _, fh := range r.MultipartForm.File["xml"] {
+			f, err := fh.Open()
+			if err != nil {
+				http.Error(w, "invalid xml file", http.StatusBadRequest)
+				return
+			}
+			data, err := io.ReadAll(f)
+			f.Close()
+			if err != nil {
+				http.Error(w, "invalid xml file", http.StatusBadRequest)
+				return
+			}
+			arquivos = append(arquivos, data)
+		}
+	} else {
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+		arquivos = append(arquivos, data)
+	}
+	if len(arquivos) == 0 || len(arquivos[0]) == 0 {
+		http.Error(w, "xml is required", http.StatusBadRequest)
+		return
+	}
+
+	// Cada arquivo é importado de forma independente
+	type resultadoArquivo struct {
+		entities.NfeImportResult
+		Erro string `json:"erro,omitempty"`
+	}
+	resultados := make([]resultadoArquivo, 0, len(arquivos))
+	for _, data := range arquivos {
+		res, err := nc.nfeUC.ImportNfe(data)
+		if err != nil && len(arquivos) == 1 {
+			writeError(w, "Error importing NF-e:", err)
+			return
+		}
+		item := resultadoArquivo{NfeImportResult: res}
+		if err != nil {
+			item.Erro = err.Error()
+		}
+		resultados = append(resultados, item)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if len(resultados) == 1 {
+		json.NewEncoder(w).Encode(resultados[0].NfeImportResult)
+		return
+	}
+	json.NewEncoder(w).Encode(resultados)
+}
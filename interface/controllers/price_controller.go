@@ -5,7 +5,9 @@ import (
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
 
+	"calculator/domain/entities"
 	"calculator/domain/usecase"
 )
 
@@ -19,7 +21,7 @@ func NewPriceController(uc usecase.PriceUseCase) *PriceController {
 	return &PriceController{priceUC: uc}
 }
 
-// /calcAlpha?sku=1234&userPrice=100.50
+// /calcAlpha?sku=1234&userPrice=100.50&tipoCliente=contribuinte&quantidade=10&dataCusto=2025-01-10&baseCusto=reposicao&uf=SP&cep=01310-100&canal=mercado_livre
 func (pc *PriceController) CalculateAlphaHandler(w http.ResponseWriter, r *http.Request) {
 	// Obter o SKU da query string
 	sku := r.URL.Query().Get("sku")
@@ -42,22 +44,122 @@ func (pc *PriceController) CalculateAlphaHandler(w http.ResponseWriter, r *http.
 		}
 	}
 
-	// Chamar o caso de uso correto com o preço personalizado
-	valorFinal, detailsJSON, err := pc.priceUC.CalculateAlphaPriceWithUserPrice(sku, userPrice)
+	// Tipo de cliente: consumidor_final (padrão) ou contribuinte
+	tipoCliente := r.URL.Query().Get("tipoCliente")
+	if tipoCliente != "" && !entities.TipoClienteValido(tipoCliente) {
+		http.Error(w, "invalid tipoCliente value", http.StatusBadRequest)
+		return
+	}
+
+	// Quantidade para a faixa de volume (padrão 1)
+	quantidade := 1
+	if q := r.URL.Query().Get("quantidade"); q != "" {
+		quantidade, err = strconv.Atoi(q)
+		if err != nil || quantidade <= 0 {
+			http.Error(w, "invalid quantidade value", http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Data do custo médio (padrão: índice atual)
+	dataCusto, err := parseDateParam(r, "dataCusto", true)
 	if err != nil {
-		log.Println("Error calculating alpha:", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, "invalid dataCusto value", http.StatusBadRequest)
+		return
+	}
+
+	// Base de custo: medio, ultima_compra, tabela_fornecedor ou reposicao
+	baseCusto := r.URL.Query().Get("baseCusto")
+	if baseCusto != "" && !entities.BaseCustoValida(baseCusto) {
+		http.Error(w, "invalid baseCusto value", http.StatusBadRequest)
+		return
+	}
+
+	// Destino e canal para o frete por transportadora e regras de subsídio
+	uf := strings.ToUpper(r.URL.Query().Get("uf"))
+	if uf != "" && !entities.UFs[uf] {
+		http.Error(w, "invalid uf value", http.StatusBadRequest)
+		return
+	}
+	cep := r.URL.Query().Get("cep")
+	if cep != "" && entities.NormalizarCep(cep) == 0 {
+		http.Error(w, "invalid cep value", http.StatusBadRequest)
+		return
+	}
+
+	// Chamar o caso de uso com os dados da requisição
+	result, err := pc.priceUC.CalculatePrice(entities.PriceRequest{
+		Sku:         sku,
+		UserPrice:   userPrice,
+		TipoCliente: tipoCliente,
+		Quantidade:  quantidade,
+		DataCusto:   dataCusto,
+		BaseCusto:   baseCusto,
+		UF:          uf,
+		Cep:         cep,
+		Canal:       r.URL.Query().Get("canal"),
+	})
+	if err != nil {
+		writeError(w, "Error calculating alpha:", err)
 		return
 	}
 
 	// Montar a resposta com o valor final e os detalhes
 	resp := map[string]interface{}{
-		"sku":         sku,
-		"valor_final": valorFinal,
-		"detalhes":    json.RawMessage(detailsJSON),
+		"sku":             sku,
+		"valor_final":     result.ValorFinal,
+		"preco_a_vista":   result.PrecoAVista,
+		"preco_parcelado": result.ValorFinal,
+		"parcelas":        result.Parcelas,
+		"valor_parcela":   result.ValorParcela,
+		"detalhes":        result.Detalhes,
+	}
+	if result.Snapshot != nil {
+		resp["snapshot_idade_segundos"] = result.Snapshot.IdadeSegundos
+	}
+	resp["desatualizado"] = result.Desatualizado != nil
+	if result.Desatualizado != nil {
+		resp["idade_dados_segundos"] = result.Desatualizado.IdadeSegundos
 	}
 
 	// Configurar o cabeçalho da resposta e enviar a resposta em JSON
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(resp)
 }
+
+// /priceList?skus=1234,5678&tipoCliente=contribuinte
+func (pc *PriceController) PriceListHandler(w http.ResponseWriter, r *http.Request) {
+	skus := splitList(r.URL.Query().Get("skus"))
+	if len(skus) == 0 {
+		http.Error(w, "skus is required", http.StatusBadRequest)
+		return
+	}
+
+	tipoCliente := r.URL.Query().Get("tipoCliente")
+	if tipoCliente != "" && !entities.TipoClienteValido(tipoCliente) {
+		http.Error(w, "invalid tipoCliente value", http.StatusBadRequest)
+		return
+	}
+
+	lista, err := pc.priceUC.GeneratePriceList(skus, tipoCliente)
+	if err != nil {
+		log.Println("Error generating price list:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(lista)
+}
+
+// splitList separa uma lista informada por vírgulas, ignorando itens vazios
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,96 @@
+package controllers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"calculator/domain/entities"
+	"calculator/domain/usecase"
+
+	"github.com/gorilla/mux"
+)
+
+// PriceDiffController disponibiliza a comparação com os preços do ERP
+type PriceDiffController struct {
+	priceDiffUC usecase.PriceDiffUseCase
+}
+
+// NewPriceDiffController cria uma nova instância de PriceDiffController
+func NewPriceDiffController(uc usecase.PriceDiffUseCase) *PriceDiffController {
+	return &PriceDiffController{priceDiffUC: uc}
+}
+
+// POST /priceDiff?usuario=ana&tabela=U02&departamento=12&limite=0.03&skus=1234,5678
+// O relatório roda como job; o resultado sai em GET /priceDiff/{id}
+func (pc *PriceDiffController) DiffHandler(w http.ResponseWriter, r *http.Request) {
+	filter := entities.PriceDiffFilter{
+		Tabela: r.URL.Query().Get("tabela"),
+		Skus:   splitList(r.URL.Query().Get("skus")),
+	}
+	if v := r.URL.Query().Get("departamento"); v != "" {
+		dept, err := strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "invalid departamento value", http.StatusBadRequest)
+			return
+		}
+		filter.Departamento = dept
+	}
+	if v := r.URL.Query().Get("limite"); v != "" {
+		limite, err := strconv.ParseFloat(v, 64)
+		if err != nil || limite < 0 {
+			http.Error(w, "invalid limite value", http.StatusBadRequest)
+			return
+		}
+		filter.Limite = limite
+	}
+
+	job, err := pc.priceDiffUC.StartDiff(r.URL.Query().Get("usuario"), filter)
+	if err != nil {
+		writeError(w, "Error starting price diff:", err)
+		return
+	}
+	writeJobAccepted(w, job, "/priceDiff/"+job.ID)
+}
+
+// GET /priceDiff/{id}?format=csv
+// Enquanto o job não termina devolve o próprio job
+func (pc *PriceDiffController) ResultHandler(w http.ResponseWriter, r *http.Request) {
+	job, relatorio, err := pc.priceDiffUC.GetDiff(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, "Error loading price diff:", err)
+		return
+	}
+	if job.Status != entities.JobConcluido {
+		writeJobStatus(w, job)
+		return
+	}
+
+	if r.URL.Query().Get("format") != "csv" {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(relatorio)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="price_diff.csv"`)
+	out := csv.NewWriter(w)
+	out.Comma = ';'
+	out.Write([]string{"sku", "departamento", "tabela", "preco_atual", "preco_proposto",
+		"variacao", "margem_atual", "margem_proposta", "erro"})
+	for _, d := range relatorio {
+		out.Write([]string{
+			d.Sku,
+			strconv.Itoa(d.Departamento),
+			d.Tabela,
+			formatNum(d.PrecoAtual),
+			formatNum(d.PrecoProposto),
+			formatNum(d.Variacao),
+			formatNum(d.MargemAtual),
+			formatNum(d.MargemProposta),
+			d.Erro,
+		})
+	}
+	out.Flush()
+}
@@ -0,0 +1,59 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"calculator/domain/usecase"
+
+	"github.com/gorilla/mux"
+)
+
+// PriceWriteController consulta, reverte e republica as gravações de preço no ERP. A
+// gravação só acontece pela publicação de propostas aprovadas (/proposals/publish).
+type PriceWriteController struct {
+	priceWriteUC usecase.PriceWriteUseCase
+}
+
+// NewPriceWriteController cria uma nova instância de PriceWriteController
+func NewPriceWriteController(uc usecase.PriceWriteUseCase) *PriceWriteController {
+	return &PriceWriteController{priceWriteUC: uc}
+}
+
+// GET /priceWrites/{id}
+func (pc *PriceWriteController) GetHandler(w http.ResponseWriter, r *http.Request) {
+	run, err := pc.priceWriteUC.GetWriteRun(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, "Error loading price write:", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(run)
+}
+
+// POST /priceWrites/{id}/rollback
+// Autenticado; quem reverte é o usuário do token
+func (pc *PriceWriteController) RollbackHandler(w http.ResponseWriter, r *http.Request) {
+	run, err := pc.priceWriteUC.RollbackWriteRun(mux.Vars(r)["id"], identidade(r).Usuario)
+	if err != nil {
+		writeError(w, "Error rolling back price write:", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(run)
+}
+
+// POST /priceWrites/{id}/publish
+// Reenvia a gravação ao sysuni
+func (pc *PriceWriteController) PublishHandler(w http.ResponseWriter, r *http.Request) {
+	run, err := pc.priceWriteUC.PublishWriteRun(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, "Error publishing price write:", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(run)
+}
@@ -0,0 +1,106 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"calculator/domain/entities"
+	"calculator/domain/usecase"
+
+	"github.com/gorilla/mux"
+)
+
+// ProposalController disponibiliza o fluxo de aprovação de preços
+type ProposalController struct {
+	proposalUC usecase.ProposalUseCase
+}
+
+// NewProposalController cria uma nova instância de ProposalController
+func NewProposalController(uc usecase.ProposalUseCase) *ProposalController {
+	return &ProposalController{proposalUC: uc}
+}
+
+// POST /proposals
+// As rotas de escrita exigem autenticação; o usuário e os papéis vêm do token
+func (pc *ProposalController) CreateHandler(w http.ResponseWriter, r *http.Request) {
+	var req entities.ProposalCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	req.Usuario = identidade(r).Usuario
+
+	propostas, err := pc.proposalUC.CreateProposals(req)
+	if err != nil {
+		writeError(w, "Error creating proposals:", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(propostas)
+}
+
+// GET /proposals?status=pendente
+func (pc *ProposalController) ListHandler(w http.ResponseWriter, r *http.Request) {
+	propostas, err := pc.proposalUC.ListProposals(r.URL.Query().Get("status"))
+	if err != nil {
+		writeError(w, "Error listing proposals:", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(propostas)
+}
+
+// GET /proposals/{id}
+func (pc *ProposalController) GetHandler(w http.ResponseWriter, r *http.Request) {
+	p, err := pc.proposalUC.GetProposal(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, "Error loading proposal:", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(p)
+}
+
+// POST /proposals/transition
+func (pc *ProposalController) TransitionHandler(w http.ResponseWriter, r *http.Request) {
+	var req entities.ProposalTransitionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	id := identidade(r)
+	req.Usuario, req.Papeis = id.Usuario, id.Papeis
+
+	resultados, err := pc.proposalUC.Transition(req)
+	if err != nil {
+		writeError(w, "Error applying proposal transition:", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resultados)
+}
+
+// POST /proposals/publish
+func (pc *ProposalController) PublishHandler(w http.ResponseWriter, r *http.Request) {
+	var req entities.ProposalPublishRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	id := identidade(r)
+	req.Usuario, req.Papeis = id.Usuario, id.Papeis
+
+	run, err := pc.proposalUC.Publish(req)
+	if err != nil {
+		writeError(w, "Error publishing proposals:", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(run)
+}
@@ -0,0 +1,77 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"calculator/domain/entities"
+	"calculator/domain/repositories"
+	"calculator/domain/usecase"
+
+	"github.com/gorilla/mux"
+)
+
+// QuoteController disponibiliza os endpoints de orçamento
+type QuoteController struct {
+	quoteUC usecase.QuoteUseCase
+}
+
+// NewQuoteController cria uma nova instância de QuoteController
+func NewQuoteController(uc usecase.QuoteUseCase) *QuoteController {
+	return &QuoteController{quoteUC: uc}
+}
+
+// POST /quotes
+func (qc *QuoteController) CreateQuoteHandler(w http.ResponseWriter, r *http.Request) {
+	var req entities.QuoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	quote, err := qc.quoteUC.CreateQuote(req)
+	if err != nil {
+		writeError(w, "Error creating quote:", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(quote)
+}
+
+// GET /quotes/{id}
+func (qc *QuoteController) GetQuoteHandler(w http.ResponseWriter, r *http.Request) {
+	quote, err := qc.quoteUC.GetQuote(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, "Error loading quote:", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(quote)
+}
+
+// writeError traduz os erros do caso de uso para o status HTTP adequado
+func writeError(w http.ResponseWriter, msg string, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidRequest):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, usecase.ErrUnauthorized):
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+	case errors.Is(err, usecase.ErrForbidden):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, repositories.ErrNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, repositories.ErrAlreadyExists), errors.Is(err, repositories.ErrConflict):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, repositories.ErrUnavailable):
+		log.Println(msg, err)
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+	default:
+		log.Println(msg, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,46 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"calculator/domain/entities"
+	"calculator/domain/usecase"
+)
+
+// RepricingController disponibiliza o recálculo de preços e a situação da fila automática
+type RepricingController struct {
+	repricingUC usecase.RepricingUseCase
+}
+
+// NewRepricingController cria uma nova instância de RepricingController
+func NewRepricingController(uc usecase.RepricingUseCase) *RepricingController {
+	return &RepricingController{repricingUC: uc}
+}
+
+// POST /repricing
+// {"skus":["1234","5678"]}
+func (rc *RepricingController) RepriceHandler(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Skus []string `json:"skus"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	res, err := rc.repricingUC.RepriceBatch(body.Skus, entities.MotivoManual)
+	if err != nil {
+		writeError(w, "Error repricing:", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+// GET /repricing/status
+func (rc *RepricingController) StatusHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rc.repricingUC.Status())
+}
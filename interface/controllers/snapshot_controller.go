@@ -0,0 +1,53 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"calculator/domain/entities"
+	"calculator/domain/usecase"
+)
+
+// SnapshotController disponibiliza a situação e a recarga do snapshot de preços
+type SnapshotController struct {
+	// snapshotUC é nil quando o modo snapshot está desativado
+	snapshotUC usecase.SnapshotUseCase
+}
+
+// NewSnapshotController cria uma nova instância de SnapshotController
+func NewSnapshotController(uc usecase.SnapshotUseCase) *SnapshotController {
+	return &SnapshotController{snapshotUC: uc}
+}
+
+// Status devolve a situação do snapshot; também alimenta a métrica pricing_snapshot
+func (sc *SnapshotController) Status() entities.SnapshotStatus {
+	if sc.snapshotUC == nil {
+		return entities.SnapshotStatus{}
+	}
+	return sc.snapshotUC.Status()
+}
+
+// GET /snapshot
+// Carga em uso, idade dos dados e contadores de cargas
+func (sc *SnapshotController) StatusHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sc.Status())
+}
+
+// POST /snapshot/reload
+// Recarrega o snapshot sem esperar o intervalo
+func (sc *SnapshotController) ReloadHandler(w http.ResponseWriter, r *http.Request) {
+	if sc.snapshotUC == nil {
+		writeError(w, "Error reloading snapshot:", fmt.Errorf("%w: modo snapshot desativado (SNAPSHOT_INTERVAL)", usecase.ErrInvalidRequest))
+		return
+	}
+	info, err := sc.snapshotUC.Reload()
+	if err != nil {
+		writeError(w, "Error reloading snapshot:", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(info)
+}
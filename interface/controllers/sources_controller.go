@@ -0,0 +1,47 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"calculator/domain/repositories"
+)
+
+// SourcesController disponibiliza a situação das fontes de dados
+type SourcesController struct {
+	sources repositories.SourceChecker
+}
+
+// NewSourcesController cria uma nova instância de SourcesController
+func NewSourcesController(sc repositories.SourceChecker) *SourcesController {
+	return &SourcesController{sources: sc}
+}
+
+// GET /health
+// Situação de cada fonte; 503 se alguma fonte obrigatória estiver fora do ar
+func (sc *SourcesController) HealthHandler(w http.ResponseWriter, r *http.Request) {
+	status := sc.sources.SourcesStatus()
+	code := http.StatusOK
+	for _, s := range status {
+		if s.Obrigatoria && !s.Disponivel {
+			code = http.StatusServiceUnavailable
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(status)
+}
+
+// Require responde 503 com o motivo enquanto alguma das fontes estiver indisponível
+func (sc *SourcesController) Require(fontes ...string) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if err := sc.sources.CheckSources(fontes...); err != nil {
+				writeError(w, "Data source unavailable:", err)
+				return
+			}
+			next(w, r)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"calculator/domain/entities"
+	"calculator/domain/usecase"
+)
+
+// WebhookController disponibiliza os destinos de webhook, as mensagens mortas e o replay
+type WebhookController struct {
+	webhookUC usecase.WebhookUseCase
+}
+
+// NewWebhookController cria uma nova instância de WebhookController
+func NewWebhookController(uc usecase.WebhookUseCase) *WebhookController {
+	return &WebhookController{webhookUC: uc}
+}
+
+// POST /webhooks
+// {"nome":"ecommerce","url":"https://loja/hooks/precos","segredo":"..."}
+func (wc *WebhookController) CreateHandler(w http.ResponseWriter, r *http.Request) {
+	var s entities.WebhookSubscription
+	if err := json.NewDecoder(r.Body).Decode(&s); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	s, err := wc.webhookUC.CreateSubscription(s)
+	if err != nil {
+		writeError(w, "Error creating webhook:", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(s)
+}
+
+// GET /webhooks
+func (wc *WebhookController) ListHandler(w http.ResponseWriter, r *http.Request) {
+	subs, err := wc.webhookUC.ListSubscriptions()
+	if err != nil {
+		writeError(w, "Error listing webhooks:", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(subs)
+}
+
+// GET /webhooks/deadLetters
+func (wc *WebhookController) DeadLettersHandler(w http.ResponseWriter, r *http.Request) {
+	mortas, err := wc.webhookUC.ListDeadLetters()
+	if err != nil {
+		writeError(w, "Error listing dead letters:", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(mortas)
+}
+
+// POST /events/replay
+// {"evento_ids":[10,11]} ou {"desde":"2024-05-01T00:00:00-03:00","assinatura_id":2}
+func (wc *WebhookController) ReplayHandler(w http.ResponseWriter, r *http.Request) {
+	var req entities.EventReplayRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	n, err := wc.webhookUC.Replay(req)
+	if err != nil {
+		writeError(w, "Error replaying events:", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]int{"agendadas": n})
+}
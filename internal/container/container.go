@@ -2,59 +2,231 @@ package container
 
 import (
 	"database/sql"
+	"errors"
+	"expvar"
 
 	"calculator/config"
+	domainrepo "calculator/domain/repositories"
 	"calculator/infrastructure/db"
 	"calculator/infrastructure/repositories"
 	"calculator/internal/firebird"
 	"calculator/domain/usecase"
 	"calculator/interface/controllers"
+
+	"github.com/sirupsen/logrus"
 )
 
 // Container estrutura para gerenciar dependências
 type Container struct {
 	PriceController *controllers.PriceController
+	QuoteController *controllers.QuoteController
+	NfeController   *controllers.NfeController
+	CostController  *controllers.CostController
+	FreightController *controllers.FreightController
+	MarkdownController *controllers.MarkdownController
+	CompetitorController *controllers.CompetitorController
+	PriceDiffController *controllers.PriceDiffController
+	PriceWriteController *controllers.PriceWriteController
+	ProposalController *controllers.ProposalController
+	MarginAnalysisController *controllers.MarginAnalysisController
+	BacktestController *controllers.BacktestController
+	ElasticityController *controllers.ElasticityController
+	RepricingController *controllers.RepricingController
+	WebhookController *controllers.WebhookController
+	JobController *controllers.JobController
+	SnapshotController *controllers.SnapshotController
+	FirebirdCacheController *controllers.FirebirdCacheController
+	SourcesController *controllers.SourcesController
+	AuthController *controllers.AuthController
 	postgresDB      *sql.DB
 	firebirdDB      *sql.DB
 	sqlServerDB     *sql.DB
+	// stop encerra as rotinas em segundo plano
+	stop chan struct{}
 }
 
 // NewContainer cria uma nova instância de Container
 func NewContainer(cfg *config.Config) (*Container, error) {
-	// Conexões com os bancos de dados
-	postgresDB, err := db.NewPostgresConn(cfg.PostgresURL)
+	// Conexões com os bancos de dados: as opcionais podem começar fora do ar
+	sources := db.NewSources()
+	postgresDB, err := sources.Open(domainrepo.FontePostgres, true, db.OpenPostgres, cfg.PostgresURL)
 	if err != nil {
 		return nil, err
 	}
 
-	firebirdDB, err := db.NewFirebirdConn(cfg.FirebirdURL)
+	firebirdDB, err := sources.Open(domainrepo.FonteFirebird, cfg.FirebirdRequired, db.OpenFirebird, cfg.FirebirdURL)
 	if err != nil {
 		return nil, err
 	}
 
-	sqlServerDB, err := db.NewSQLServerConn(cfg.SQLServerURL)
+	// Aplica as migrations das tabelas mantidas pelo serviço
+	if err := db.RunMigrations(postgresDB); err != nil {
+		return nil, err
+	}
+
+	sqlServerDB, err := sources.Open(domainrepo.FonteSQLServer, cfg.SQLServerRequired, db.OpenSQLServer, cfg.SQLServerURL)
 	if err != nil {
 		return nil, err
 	}
 
+	stop := make(chan struct{})
+	sources.Start(stop)
+	sourcesCtrl := controllers.NewSourcesController(sources)
+
 	// Repositórios e serviços
-	productRepo := repositories.NewProductRepository(postgresDB, firebirdDB, sqlServerDB)
-	productService := firebird.NewProductService(firebirdDB)
+	productRepo := repositories.NewProductRepository(postgresDB, firebirdDB, sqlServerDB, sources)
+	productService := firebird.NewProductService(firebirdDB, sources)
+	quoteRepo := repositories.NewQuoteRepository(postgresDB)
+	costRepo := repositories.NewCostRepository(postgresDB)
+	competitorRepo := repositories.NewCompetitorRepository(postgresDB)
+	erpPriceTable := repositories.ErpPriceTable{
+		Nome:          cfg.ErpPriceTable,
+		ColunaProduto: cfg.ErpPriceColProduto,
+		ColunaTabela:  cfg.ErpPriceColTabela,
+		ColunaPreco:   cfg.ErpPriceColPreco,
+	}
+	// Tabela ou colunas inexistentes impedem a inicialização; com o Firebird fora do ar a
+	// conferência fica para o primeiro uso
+	if err := erpPriceTable.Validar(firebirdDB); err != nil {
+		if !errors.Is(err, domainrepo.ErrUnavailable) {
+			return nil, err
+		}
+		logrus.WithError(err).Warn("Tabela de preços do ERP será conferida quando o Firebird responder")
+	}
+	erpPriceRepo := repositories.NewErpPriceRepository(firebirdDB, erpPriceTable)
+	priceWriteRepo := repositories.NewPriceWriteRepository(postgresDB)
+	proposalRepo := repositories.NewProposalRepository(postgresDB)
+	salesRepo := repositories.NewSalesRepository(sqlServerDB, cfg.SalesQuery)
+	elasticityRepo := repositories.NewElasticityRepository(postgresDB)
+	repricingRepo := repositories.NewRepricingRepository(postgresDB)
+	outboxRepo := repositories.NewOutboxRepository(postgresDB)
+	jobRepo := repositories.NewJobRepository(postgresDB)
+	publisher := repositories.NewNoopPricePublisher()
+	if cfg.SysuniPublish {
+		publisher = repositories.NewSysuniPublisher(sqlServerDB)
+	}
+	freightRepo := repositories.NewFreightRepository(postgresDB)
+	if cfg.FreightSource == "csv" {
+		freightRepo = repositories.NewFreightCSVRepository(cfg.FreightCSVDir)
+	}
+
+	// Tabelas de frete: sem elas o cálculo usa o frete do np_comissao_frete
+	freightUC := usecase.NewFreightUseCase(freightRepo)
+	if _, err := freightUC.Reload(); err != nil {
+		logrus.WithError(err).Warn("Tabelas de frete indisponíveis, usando frete do np_comissao_frete")
+	}
+	freightUC.StartAutoReload(cfg.FreightReloadInterval, stop)
+	freightCtrl := controllers.NewFreightController(freightUC)
+
+	// Modo snapshot: sem carga inicial o cálculo usa os bancos até a próxima recarga
+	var snapshotUC usecase.SnapshotUseCase
+	if cfg.SnapshotInterval > 0 {
+		snapshotUC = usecase.NewSnapshotUseCase(repositories.NewSnapshotRepository(postgresDB, firebirdDB, productRepo, productService))
+		if _, err := snapshotUC.Reload(); err != nil {
+			logrus.WithError(err).Warn("Snapshot de preços indisponível, usando os bancos")
+		}
+		snapshotUC.StartAutoReload(cfg.SnapshotInterval, stop)
+	}
+	snapshotCtrl := controllers.NewSnapshotController(snapshotUC)
+	expvar.Publish("pricing_snapshot", expvar.Func(func() interface{} { return snapshotCtrl.Status() }))
+
+	// Modo degradado: cache da última leitura do Firebird no Postgres
+	firebirdCacheUC := usecase.NewFirebirdCacheUseCase(
+		repositories.NewFirebirdCacheRepository(postgresDB, firebirdDB, productService), cfg.FirebirdCacheMaxAge)
+	firebirdCacheUC.StartAutoRefresh(cfg.FirebirdCacheInterval, stop)
+	firebirdCacheCtrl := controllers.NewFirebirdCacheController(firebirdCacheUC)
+	var priceFallback usecase.FirebirdCacheUseCase
+	if cfg.FirebirdCacheMaxAge > 0 {
+		priceFallback = firebirdCacheUC
+	}
 
 	// UseCases e Controllers
-	priceUC := usecase.NewPriceUseCase(productRepo, productService)
+	priceUC := usecase.NewPriceUseCase(productRepo, productService, freightUC, snapshotUC, priceFallback, cfg.UFOrigem)
 	priceCtrl := controllers.NewPriceController(priceUC)
+	// Jobs em lote: os relatórios se registram ao serem criados, antes do Start
+	jobUC := usecase.NewJobUseCase(jobRepo, priceUC)
+	quoteUC := usecase.NewQuoteUseCase(priceUC, quoteRepo, freightUC)
+	quoteCtrl := controllers.NewQuoteController(quoteUC)
+	var erpStockRepo domainrepo.ErpStockRepository
+	if cfg.ErpStockQuery != "" {
+		erpStockRepo = repositories.NewErpStockRepository(firebirdDB, sources, cfg.ErpStockQuery)
+	}
+	cmpUC := usecase.NewCmpUseCase(costRepo, erpStockRepo)
+	costCtrl := controllers.NewCostController(cmpUC)
+	nfeUC := usecase.NewNfeImportUseCase(costRepo, cmpUC, priceUC)
+	nfeCtrl := controllers.NewNfeController(nfeUC)
+	markdownUC := usecase.NewMarkdownUseCase(costRepo, priceUC)
+	markdownCtrl := controllers.NewMarkdownController(markdownUC)
+	competitorUC := usecase.NewCompetitorUseCase(competitorRepo, costRepo, priceUC)
+	competitorCtrl := controllers.NewCompetitorController(competitorUC)
+	priceDiffUC := usecase.NewPriceDiffUseCase(erpPriceRepo, priceUC, jobUC)
+	priceDiffCtrl := controllers.NewPriceDiffController(priceDiffUC)
+	priceWriteUC := usecase.NewPriceWriteUseCase(erpPriceRepo, priceWriteRepo, publisher)
+	// Sem a publicação ativa a fila acumula e é enviada quando ela for ativada
+	if cfg.SysuniPublish {
+		priceWriteUC.StartPublisher(cfg.SysuniPublishInterval, stop)
+	}
+	priceWriteCtrl := controllers.NewPriceWriteController(priceWriteUC)
+	proposalUC := usecase.NewProposalUseCase(proposalRepo, erpPriceRepo, priceWriteUC)
+	proposalCtrl := controllers.NewProposalController(proposalUC)
+	authCtrl := controllers.NewAuthController(usecase.NewAuthUseCase(repositories.NewIdentityRepository(postgresDB)))
+	marginUC := usecase.NewMarginAnalysisUseCase(salesRepo, priceUC, jobUC)
+	marginCtrl := controllers.NewMarginAnalysisController(marginUC)
+	backtestUC := usecase.NewBacktestUseCase(salesRepo, priceUC)
+	backtestCtrl := controllers.NewBacktestController(backtestUC)
+	elasticityUC := usecase.NewElasticityUseCase(salesRepo, productRepo, elasticityRepo, priceUC, jobUC)
+	elasticityCtrl := controllers.NewElasticityController(elasticityUC)
+
+	// Recálculo automático: gatilhos da migration notificam o canal escutado aqui
+	repricingUC := usecase.NewRepricingUseCase(repricingRepo, priceUC, markdownUC, proposalUC)
+	if cfg.RepricingListen {
+		repricingUC.Start(cfg.RepricingRetryInterval, stop)
+		repositories.NewChangeListener(cfg.PostgresURL).Listen(stop, repricingUC.HandleChange)
+	}
+	repricingCtrl := controllers.NewRepricingController(repricingUC)
+
+	// Webhooks: os eventos entram no outbox junto com os preços calculados
+	webhookUC := usecase.NewWebhookUseCase(outboxRepo, repositories.NewHTTPWebhookSender())
+	webhookUC.StartDispatcher(cfg.WebhookDispatchInterval, stop)
+	webhookCtrl := controllers.NewWebhookController(webhookUC)
+
+	// Jobs em lote: persistidos no Postgres e retomados após reinício
+	jobUC.Start(cfg.JobWorkers, stop)
+	jobCtrl := controllers.NewJobController(jobUC)
 
 	return &Container{
 		PriceController: priceCtrl,
+		QuoteController: quoteCtrl,
+		NfeController:   nfeCtrl,
+		CostController:  costCtrl,
+		FreightController: freightCtrl,
+		MarkdownController: markdownCtrl,
+		CompetitorController: competitorCtrl,
+		PriceDiffController: priceDiffCtrl,
+		PriceWriteController: priceWriteCtrl,
+		ProposalController: proposalCtrl,
+		MarginAnalysisController: marginCtrl,
+		BacktestController: backtestCtrl,
+		ElasticityController: elasticityCtrl,
+		RepricingController: repricingCtrl,
+		WebhookController: webhookCtrl,
+		JobController: jobCtrl,
+		SnapshotController: snapshotCtrl,
+		FirebirdCacheController: firebirdCacheCtrl,
+		SourcesController: sourcesCtrl,
+		AuthController: authCtrl,
 		postgresDB:      postgresDB,
 		firebirdDB:      firebirdDB,
 		sqlServerDB:     sqlServerDB,
+		stop:            stop,
 	}, nil
 }
 
 // Close fecha as conexões de banco de dados
 func (c *Container) Close() {
+	if c.stop != nil {
+		close(c.stop)
+	}
 	if c.postgresDB != nil {
 		c.postgresDB.Close()
 	}
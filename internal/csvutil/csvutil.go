@@ -0,0 +1,126 @@
+package csvutil
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Row dá acesso às colunas de uma linha pelo nome do cabeçalho (minúsculo)
+type Row struct {
+	header map[string]int
+	values []string
+	Line   int
+	err    error
+}
+
+// Err devolve o primeiro erro de conversão da linha
+func (r *Row) Err() error {
+	return r.err
+}
+
+// Has informa se o cabeçalho contém a coluna
+func (r *Row) Has(col string) bool {
+	_, ok := r.header[col]
+	return ok
+}
+
+// Str devolve o texto da coluna sem espaços nas pontas
+func (r *Row) Str(col string) string {
+	i, ok := r.header[col]
+	if !ok || i >= len(r.values) {
+		return ""
+	}
+	return strings.TrimSpace(r.values[i])
+}
+
+// Num aceita decimal com vírgula (1.234,56) ou ponto (1234.56); vazio vale zero
+func (r *Row) Num(col string) float64 {
+	v := r.Str(col)
+	if v == "" {
+		return 0
+	}
+	v = strings.TrimSpace(strings.TrimPrefix(v, "R$"))
+	if strings.Contains(v, ",") {
+		v = strings.ReplaceAll(strings.ReplaceAll(v, ".", ""), ",", ".")
+	}
+	n, err := strconv.ParseFloat(v, 64)
+	if err != nil && r.err == nil {
+		r.err = fmt.Errorf("linha %d, coluna %s: valor inválido %q", r.Line, col, v)
+	}
+	return n
+}
+
+// Date aceita AAAA-MM-DD, DD/MM/AAAA ou data e hora RFC 3339; vazio vale a data zero
+func (r *Row) Date(col string) time.Time {
+	v := r.Str(col)
+	if v == "" {
+		return time.Time{}
+	}
+	for _, layout := range []string{"2006-01-02", "02/01/2006", time.RFC3339, "2006-01-02 15:04:05", "02/01/2006 15:04"} {
+		if t, err := time.ParseInLocation(layout, v, time.Local); err == nil {
+			return t
+		}
+	}
+	if r.err == nil {
+		r.err = fmt.Errorf("linha %d, coluna %s: data inválida %q", r.Line, col, v)
+	}
+	return time.Time{}
+}
+
+// ReadFile lê um CSV com cabeçalho do disco
+func ReadFile(path string, fn func(row *Row) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("erro ao abrir %s: %w", path, err)
+	}
+	defer f.Close()
+	return Parse(f, path, fn)
+}
+
+// Parse processa um CSV com cabeçalho, detectando o separador (";" ou ",") pela primeira linha
+func Parse(in io.Reader, name string, fn func(row *Row) error) error {
+	data, err := io.ReadAll(in)
+	if err != nil {
+		return fmt.Errorf("erro ao ler %s: %w", name, err)
+	}
+	content := strings.TrimPrefix(string(data), "\ufeff")
+
+	reader := csv.NewReader(strings.NewReader(content))
+	firstLine := content
+	if i := strings.IndexByte(content, '\n'); i >= 0 {
+		firstLine = content[:i]
+	}
+	if strings.Count(firstLine, ";") > strings.Count(firstLine, ",") {
+		reader.Comma = ';'
+	}
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("erro ao ler cabeçalho de %s: %w", name, err)
+	}
+	cols := map[string]int{}
+	for i, h := range header {
+		cols[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+
+	line := 1
+	for {
+		values, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		line++
+		if err != nil {
+			return fmt.Errorf("erro ao ler %s: %w", name, err)
+		}
+		if err := fn(&Row{header: cols, values: values, Line: line}); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+}
@@ -3,21 +3,51 @@ package firebird
 import (
 	"database/sql"
 	"fmt"
+	"strings"
+
+	"calculator/domain/entities"
+	"calculator/domain/repositories"
 
 	"github.com/sirupsen/logrus"
 )
 
 type ProductService struct {
-	db *sql.DB
+	db      *sql.DB
+	sources repositories.SourceChecker
 }
 
-// NewProductService criando nova instancia do serviço
-func NewProductService(db *sql.DB) *ProductService {
-	return &ProductService{db: db}
+// NewProductService criando nova instancia do serviço; com o Firebird fora do ar
+// as consultas por produto falham na hora
+func NewProductService(db *sql.DB, sc repositories.SourceChecker) *ProductService {
+	return &ProductService{db: db, sources: sc}
 }
 
 // CalculateIcmsEfetivoEDifal calcula o ICMS efetivo e o Difal baseado no perfil de imposto e origem do produto
 func (ps *ProductService) CalculateIcmsEfetivoEDifal(produto int) (float64, float64, error) {
+	icms, err := ps.CalculateIcmsVenda(produto, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	return icms.IcmsEfetivo, icms.Difal, nil
+}
+
+// CalculateIcmsVenda calcula o ICMS efetivo e o Difal considerando a tabela completa
+// de origens e, quando informada, a FCI do produto
+func (ps *ProductService) CalculateIcmsVenda(produto int, fci *entities.Fci) (entities.IcmsVenda, error) {
+	perfil, err := ps.GetPerfilFiscal(produto)
+	if err != nil {
+		return entities.IcmsVenda{}, err
+	}
+	return CalcularIcmsVenda(perfil, fci)
+}
+
+// GetPerfilFiscal busca a redução de ICMS do perfil de imposto e a origem do produto
+func (ps *ProductService) GetPerfilFiscal(produto int) (entities.PerfilFiscal, error) {
+	perfil := entities.PerfilFiscal{Produto: produto}
+	if err := ps.sources.CheckSources(repositories.FonteFirebird); err != nil {
+		return perfil, fmt.Errorf("erro ao consultar perfil fiscal: %w", err)
+	}
+
 	// Consulta o valor total de RED_ICMS
 	queryRedIcms := `
 		SELECT SUM(RED_ICMS)
@@ -32,10 +62,15 @@ func (ps *ProductService) CalculateIcmsEfetivoEDifal(produto int) (float64, floa
 	var totalIcms sql.NullFloat64
 	row := ps.db.QueryRow(queryRedIcms, produto)
 	err := row.Scan(&totalIcms)
+	if IsConnectionError(err) {
+		return perfil, fmt.Errorf("%w: erro ao consultar RED_ICMS: %v", repositories.ErrUnavailable, err)
+	}
 	if err != nil {
-		return 0, 0, fmt.Errorf("erro ao consultar RED_ICMS: %w", err)
+		return perfil, fmt.Errorf("erro ao consultar RED_ICMS: %w", err)
 	}
 	logrus.WithField("total_icms", totalIcms.Float64).Info("Valor total de ICMS calculado")
+	perfil.ReducaoIcms = totalIcms.Float64
+	perfil.TemReducao = totalIcms.Valid
 
 	// Consulta a origem do produto
 	queryOrigemProd := `
@@ -44,42 +79,90 @@ func (ps *ProductService) CalculateIcmsEfetivoEDifal(produto int) (float64, floa
 		WHERE produto = ?
 	`
 
-	var origemProd string
 	row = ps.db.QueryRow(queryOrigemProd, produto)
-	err = row.Scan(&origemProd)
+	err = row.Scan(&perfil.OrigemProd)
+	if IsConnectionError(err) {
+		return perfil, fmt.Errorf("%w: erro ao consultar origem_prod: %v", repositories.ErrUnavailable, err)
+	}
 	if err != nil {
-		return 0, 0, fmt.Errorf("erro ao consultar origem_prod: %w", err)
+		return perfil, fmt.Errorf("erro ao consultar origem_prod: %w", err)
 	}
-	logrus.WithField("origem_prod", origemProd).Info("Origem do produto consultada")
+	logrus.WithField("origem_prod", perfil.OrigemProd).Info("Origem do produto consultada")
 
-	// Determina se o produto é estrangeiro ou nacional
-	origemUnimarcas := "NACIONAL"
-	if origemProd == "1" || origemProd == "2" || origemProd == "3" || origemProd == "8" {
-		origemUnimarcas = "ESTRANGEIRO"
+	return perfil, nil
+}
+
+// LoadPerfisFiscais carrega o perfil fiscal de todos os produtos em uma consulta
+func (ps *ProductService) LoadPerfisFiscais() (map[int]entities.PerfilFiscal, error) {
+	rows, err := ps.db.Query(`
+		SELECT p.produto, p.origem_prod,
+			(SELECT SUM(i.RED_ICMS) FROM IMPOSTOS_PERFIL i WHERE i.perfil_imposto = p.perfil_imposto)
+		FROM produtos p
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao carregar perfis fiscais: %w", err)
 	}
-	logrus.WithField("origemUnimarcas", origemUnimarcas).Info("Origem Unimarcas determinada")
+	defer rows.Close()
+
+	perfis := map[int]entities.PerfilFiscal{}
+	for rows.Next() {
+		var p entities.PerfilFiscal
+		var origem sql.NullString
+		var total sql.NullFloat64
+		if err := rows.Scan(&p.Produto, &origem, &total); err != nil {
+			return nil, fmt.Errorf("erro ao ler perfil fiscal: %w", err)
+		}
+		p.OrigemProd = origem.String
+		p.ReducaoIcms = total.Float64
+		p.TemReducao = total.Valid
+		perfis[p.Produto] = p
+	}
+	return perfis, rows.Err()
+}
+
+// CalcularIcmsVenda aplica a regra do ICMS efetivo e do Difal ao perfil fiscal do produto
+func CalcularIcmsVenda(perfil entities.PerfilFiscal, fci *entities.Fci) (entities.IcmsVenda, error) {
+	codigo := strings.TrimSpace(perfil.OrigemProd)
+	origem, ok := entities.BuscarOrigem(codigo)
+	if !ok {
+		logrus.WithFields(logrus.Fields{
+			"produto":     perfil.Produto,
+			"origem_prod": codigo,
+		}).Warn("Origem do produto vazia ou desconhecida, assumindo origem nacional")
+	}
+
+	// Define a alíquota interestadual pela origem e conteúdo de importação
+	aliquotaOrigem := origem.AliquotaInterestadual(fci)
+	if !ok {
+		aliquotaOrigem.Motivo += fmt.Sprintf("; origem %q vazia ou desconhecida no cadastro, assumida a origem nacional", codigo)
+	}
+	logrus.WithFields(logrus.Fields{
+		"origem":                 origem.Codigo,
+		"aliquota_interestadual": aliquotaOrigem.AliquotaInterestadual,
+		"motivo":                 aliquotaOrigem.Motivo,
+	}).Info("Alíquota interestadual determinada")
 
 	// Aplica a lógica do cálculo de ICMS efetivo
 	var icmsEfetivo float64
-	if totalIcms.Valid && totalIcms.Float64 > 0 {
+	if perfil.TemReducao && perfil.ReducaoIcms > 0 {
 		icmsEfetivo = 0.088
 	} else {
 		icmsEfetivo = 0.18
 	}
 	logrus.WithField("icmsEfetivo", icmsEfetivo).Info("ICMS Efetivo calculado")
 
-	// Aplica a lógica do cálculo de Difal
-	var difal float64
-	if origemUnimarcas == "NACIONAL" {
-		if icmsEfetivo == 0.088 {
-			difal = 0
-		} else {
-			difal = icmsEfetivo - 0.12
-		}
-	} else {
-		difal = icmsEfetivo - 0.04
+	// Difal é a diferença entre o ICMS efetivo e a alíquota interestadual;
+	// quando a base reduzida já fica abaixo da interestadual não há Difal
+	difal := icmsEfetivo - aliquotaOrigem.AliquotaInterestadual
+	if difal < 0 {
+		difal = 0
 	}
 	logrus.WithField("difal", difal).Info("Difal calculado")
 
-	return icmsEfetivo, difal, nil
+	return entities.IcmsVenda{
+		IcmsEfetivo: icmsEfetivo,
+		Difal:       difal,
+		Origem:      aliquotaOrigem,
+		ReducaoBase: perfil.TemReducao && perfil.ReducaoIcms > 0,
+	}, nil
 }
@@ -0,0 +1,173 @@
+package nfe
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+
+	"calculator/domain/entities"
+)
+
+// Estruturas do leiaute 4.00 da NF-e; só os campos usados na composição de custo
+type nfeProc struct {
+	NFe nfeDoc `xml:"NFe"`
+}
+
+type nfeDoc struct {
+	InfNFe infNFe `xml:"infNFe"`
+}
+
+type infNFe struct {
+	ID     string `xml:"Id,attr"`
+	Versao string `xml:"versao,attr"`
+	Ide    struct {
+		NNF   string `xml:"nNF"`
+		Serie string `xml:"serie"`
+		DhEmi string `xml:"dhEmi"`
+	} `xml:"ide"`
+	Emit struct {
+		CNPJ  string `xml:"CNPJ"`
+		XNome string `xml:"xNome"`
+	} `xml:"emit"`
+	Det []det `xml:"det"`
+}
+
+type det struct {
+	NItem int `xml:"nItem,attr"`
+	Prod  struct {
+		CProd    string  `xml:"cProd"`
+		CEAN     string  `xml:"cEAN"`
+		XProd    string  `xml:"xProd"`
+		NCM      string  `xml:"NCM"`
+		CFOP     string  `xml:"CFOP"`
+		UCom     string  `xml:"uCom"`
+		QCom     float64 `xml:"qCom"`
+		VProd    float64 `xml:"vProd"`
+		CEANTrib string  `xml:"cEANTrib"`
+		VFrete   float64 `xml:"vFrete"`
+		VSeg     float64 `xml:"vSeg"`
+		VDesc    float64 `xml:"vDesc"`
+		VOutro   float64 `xml:"vOutro"`
+	} `xml:"prod"`
+	Imposto struct {
+		// ICMS00, ICMS10, ..., ICMSSN102 etc.: o grupo varia conforme o CST/CSOSN
+		ICMS struct {
+			Grupos []icmsGrupo `xml:",any"`
+		} `xml:"ICMS"`
+		IPI struct {
+			IPITrib struct {
+				VIPI float64 `xml:"vIPI"`
+			} `xml:"IPITrib"`
+		} `xml:"IPI"`
+		PIS struct {
+			Grupos []pisGrupo `xml:",any"`
+		} `xml:"PIS"`
+		COFINS struct {
+			Grupos []cofinsGrupo `xml:",any"`
+		} `xml:"COFINS"`
+	} `xml:"imposto"`
+}
+
+type icmsGrupo struct {
+	Orig        string  `xml:"orig"`
+	CST         string  `xml:"CST"`
+	CSOSN       string  `xml:"CSOSN"`
+	VICMS       float64 `xml:"vICMS"`
+	VICMSST     float64 `xml:"vICMSST"`
+	VCredICMSSN float64 `xml:"vCredICMSSN"`
+}
+
+type pisGrupo struct {
+	VPIS float64 `xml:"vPIS"`
+}
+
+type cofinsGrupo struct {
+	VCOFINS float64 `xml:"vCOFINS"`
+}
+
+// Parse lê o XML de uma NF-e 4.00, com ou sem o envelope nfeProc
+func Parse(data []byte) (entities.NotaFiscalCompra, error) {
+	var inf infNFe
+
+	var proc nfeProc
+	if err := xml.Unmarshal(data, &proc); err == nil && proc.NFe.InfNFe.ID != "" {
+		inf = proc.NFe.InfNFe
+	} else {
+		var doc nfeDoc
+		if err := xml.NewDecoder(bytes.NewReader(data)).Decode(&doc); err != nil {
+			return entities.NotaFiscalCompra{}, fmt.Errorf("erro ao ler XML da NF-e: %w", err)
+		}
+		inf = doc.InfNFe
+	}
+
+	if inf.ID == "" {
+		return entities.NotaFiscalCompra{}, fmt.Errorf("XML não contém infNFe")
+	}
+	if inf.Versao != "" && inf.Versao != "4.00" {
+		return entities.NotaFiscalCompra{}, fmt.Errorf("versão da NF-e não suportada: %s", inf.Versao)
+	}
+
+	emissao, err := time.Parse(time.RFC3339, inf.Ide.DhEmi)
+	if err != nil {
+		return entities.NotaFiscalCompra{}, fmt.Errorf("data de emissão inválida %q: %w", inf.Ide.DhEmi, err)
+	}
+
+	nota := entities.NotaFiscalCompra{
+		Chave:        strings.TrimPrefix(inf.ID, "NFe"),
+		Numero:       inf.Ide.NNF,
+		Serie:        inf.Ide.Serie,
+		EmitenteCNPJ: inf.Emit.CNPJ,
+		EmitenteNome: inf.Emit.XNome,
+		DataEmissao:  emissao,
+	}
+
+	for _, d := range inf.Det {
+		item := entities.NotaFiscalItem{
+			NumeroItem:       d.NItem,
+			CodigoFornecedor: d.Prod.CProd,
+			EAN:              validEAN(d.Prod.CEAN, d.Prod.CEANTrib),
+			Descricao:        d.Prod.XProd,
+			NCM:              d.Prod.NCM,
+			CFOP:             d.Prod.CFOP,
+			Unidade:          d.Prod.UCom,
+			Quantidade:       d.Prod.QCom,
+			ValorProduto:     d.Prod.VProd,
+			Frete:            d.Prod.VFrete,
+			Seguro:           d.Prod.VSeg,
+			Desconto:         d.Prod.VDesc,
+			Outros:           d.Prod.VOutro,
+			Ipi:              d.Imposto.IPI.IPITrib.VIPI,
+		}
+		for _, g := range d.Imposto.ICMS.Grupos {
+			item.Origem = g.Orig
+			// No Simples Nacional o crédito vem em vCredICMSSN
+			item.Icms += g.VICMS + g.VCredICMSSN
+			item.IcmsSt += g.VICMSST
+		}
+		for _, g := range d.Imposto.PIS.Grupos {
+			item.Pis += g.VPIS
+		}
+		for _, g := range d.Imposto.COFINS.Grupos {
+			item.Cofins += g.VCOFINS
+		}
+		nota.Itens = append(nota.Itens, item)
+	}
+
+	if len(nota.Itens) == 0 {
+		return entities.NotaFiscalCompra{}, fmt.Errorf("NF-e %s sem itens", nota.Chave)
+	}
+	return nota, nil
+}
+
+// validEAN devolve o primeiro GTIN informado, ignorando "SEM GTIN"
+func validEAN(codigos ...string) string {
+	for _, c := range codigos {
+		c = strings.TrimSpace(c)
+		if c != "" && !strings.EqualFold(c, "SEM GTIN") {
+			return c
+		}
+	}
+	return ""
+}
@@ -0,0 +1,116 @@
+package nfe
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+const nfeProcXML = `<?xml version="1.0" encoding="UTF-8"?>
+<nfeProc versao="4.00" xmlns="http://www.portalfiscal.inf.br/nfe">
+  <NFe>
+    <infNFe Id="NFe35240112345678000199550010000012341000012345" versao="4.00">
+      <ide><nNF>1234</nNF><serie>1</serie><dhEmi>2024-01-15T10:30:00-03:00</dhEmi></ide>
+      <emit><CNPJ>12345678000199</CNPJ><xNome>Fornecedor Teste</xNome></emit>
+      <det nItem="1">
+        <prod>
+          <cProd>A-100</cProd><cEAN>7891234567890</cEAN><xProd>Produto A</xProd>
+          <NCM>84713012</NCM><CFOP>6102</CFOP><uCom>UN</uCom><qCom>10.0000</qCom>
+          <vProd>1000.00</vProd><cEANTrib>7891234567890</cEANTrib>
+          <vFrete>50.00</vFrete><vSeg>5.00</vSeg><vDesc>20.00</vDesc><vOutro>15.00</vOutro>
+        </prod>
+        <imposto>
+          <ICMS><ICMS10><orig>1</orig><CST>10</CST><vICMS>120.00</vICMS><vICMSST>30.00</vICMSST></ICMS10></ICMS>
+          <IPI><IPITrib><vIPI>65.00</vIPI></IPITrib></IPI>
+          <PIS><PISAliq><vPIS>16.50</vPIS></PISAliq></PIS>
+          <COFINS><COFINSAliq><vCOFINS>76.00</vCOFINS></COFINSAliq></COFINS>
+        </imposto>
+      </det>
+      <det nItem="2">
+        <prod>
+          <cProd>B-200</cProd><cEAN>SEM GTIN</cEAN><xProd>Brinde</xProd>
+          <NCM>84713012</NCM><CFOP>6910</CFOP><uCom>UN</uCom><qCom>1</qCom>
+          <vProd>10.00</vProd><cEANTrib>SEM GTIN</cEANTrib>
+        </prod>
+        <imposto>
+          <ICMS><ICMSSN101><orig>0</orig><CSOSN>101</CSOSN><vCredICMSSN>0.50</vCredICMSSN></ICMSSN101></ICMS>
+        </imposto>
+      </det>
+    </infNFe>
+  </NFe>
+</nfeProc>`
+
+func TestParseNfeProc(t *testing.T) {
+	nota, err := Parse([]byte(nfeProcXML))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	if nota.Chave != "35240112345678000199550010000012341000012345" {
+		t.Errorf("chave = %q", nota.Chave)
+	}
+	if nota.Numero != "1234" || nota.Serie != "1" || nota.EmitenteCNPJ != "12345678000199" {
+		t.Errorf("cabeçalho = %+v", nota)
+	}
+	emissao := time.Date(2024, 1, 15, 13, 30, 0, 0, time.UTC)
+	if !nota.DataEmissao.Equal(emissao) {
+		t.Errorf("emissão = %v, esperado %v", nota.DataEmissao, emissao)
+	}
+	if len(nota.Itens) != 2 {
+		t.Fatalf("itens = %d, esperado 2", len(nota.Itens))
+	}
+
+	a := nota.Itens[0]
+	if a.NumeroItem != 1 || a.CodigoFornecedor != "A-100" || a.EAN != "7891234567890" || a.CFOP != "6102" || a.Origem != "1" {
+		t.Errorf("item 1 = %+v", a)
+	}
+	if a.Quantidade != 10 || a.Icms != 120 || a.IcmsSt != 30 || a.Ipi != 65 || a.Pis != 16.5 || a.Cofins != 76 {
+		t.Errorf("valores do item 1 = %+v", a)
+	}
+	// 1000 + 50 + 5 + 15 - 20 + 65 (IPI) + 30 (ST)
+	if got := a.CustoNF(); math.Abs(got-1145) > 1e-9 {
+		t.Errorf("CustoNF = %v, esperado 1145", got)
+	}
+	if !a.Compra() {
+		t.Errorf("CFOP 6102 deveria ser compra")
+	}
+
+	b := nota.Itens[1]
+	if b.EAN != "" {
+		t.Errorf("SEM GTIN deveria virar EAN vazio, veio %q", b.EAN)
+	}
+	if b.Icms != 0.5 {
+		t.Errorf("crédito do Simples = %v, esperado 0.5", b.Icms)
+	}
+	if b.Compra() {
+		t.Errorf("CFOP 6910 (bonificação) não deveria ser compra")
+	}
+}
+
+func TestParseSemEnvelope(t *testing.T) {
+	inicio := strings.Index(nfeProcXML, "<NFe>")
+	fim := strings.Index(nfeProcXML, "</NFe>") + len("</NFe>")
+	nota, err := Parse([]byte(nfeProcXML[inicio:fim]))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if nota.Numero != "1234" || len(nota.Itens) != 2 {
+		t.Errorf("nota = %+v", nota)
+	}
+}
+
+func TestParseErros(t *testing.T) {
+	casos := map[string]string{
+		"xml inválido":   "<NFe><infNFe",
+		"sem infNFe":     "<NFe></NFe>",
+		"versão":         `<NFe><infNFe Id="NFe1" versao="3.10"><ide><dhEmi>2024-01-15T10:30:00-03:00</dhEmi></ide></infNFe></NFe>`,
+		"data inválida":  `<NFe><infNFe Id="NFe1" versao="4.00"><ide><dhEmi>15/01/2024</dhEmi></ide></infNFe></NFe>`,
+		"nota sem itens": `<NFe><infNFe Id="NFe1" versao="4.00"><ide><dhEmi>2024-01-15T10:30:00-03:00</dhEmi></ide></infNFe></NFe>`,
+	}
+	for nome, xml := range casos {
+		if _, err := Parse([]byte(xml)); err == nil {
+			t.Errorf("%s: esperado erro", nome)
+		}
+	}
+}
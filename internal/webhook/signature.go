@@ -0,0 +1,54 @@
+// Package webhook assina e verifica o corpo dos webhooks de preço (HMAC-SHA256).
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cabeçalhos enviados em cada webhook
+const (
+	HeaderAssinatura = "X-Signature"
+	HeaderTimestamp  = "X-Timestamp"
+	HeaderEvento     = "X-Event-Id"
+	HeaderTipo       = "X-Event-Type"
+)
+
+// prefixoAssinatura identifica o algoritmo no cabeçalho X-Signature
+const prefixoAssinatura = "sha256="
+
+// ErrAssinaturaInvalida indica corpo, segredo ou timestamp que não conferem
+var ErrAssinaturaInvalida = errors.New("assinatura do webhook inválida")
+
+// Sign assina "timestamp.corpo" com o segredo do destino; o timestamp na assinatura
+// impede que um corpo capturado seja reenviado muito tempo depois
+func Sign(segredo string, timestamp int64, corpo []byte) string {
+	mac := hmac.New(sha256.New, []byte(segredo))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(corpo)
+	return prefixoAssinatura + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify confere a assinatura e se o timestamp está dentro da tolerância
+func Verify(segredo, assinatura, timestamp string, corpo []byte, tolerancia time.Duration, agora time.Time) error {
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || !strings.HasPrefix(assinatura, prefixoAssinatura) {
+		return ErrAssinaturaInvalida
+	}
+	if tolerancia > 0 {
+		d := agora.Sub(time.Unix(ts, 0))
+		if d > tolerancia || d < -tolerancia {
+			return ErrAssinaturaInvalida
+		}
+	}
+	if !hmac.Equal([]byte(Sign(segredo, ts, corpo)), []byte(assinatura)) {
+		return ErrAssinaturaInvalida
+	}
+	return nil
+}
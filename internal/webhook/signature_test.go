@@ -0,0 +1,58 @@
+package webhook
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+const (
+	segredoTeste = "segredo"
+	tsTeste      = int64(1700000000)
+)
+
+var corpoTeste = []byte(`{"id":1}`)
+
+func TestSign(t *testing.T) {
+	// HMAC-SHA256("1700000000.{"id":1}") com a chave "segredo"
+	esperado := "sha256=5c702ac91576bf8cd88f81c39eb431027d0993b997cfd361d29e239255a2298f"
+	if got := Sign(segredoTeste, tsTeste, corpoTeste); got != esperado {
+		t.Errorf("Sign = %q, esperado %q", got, esperado)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	agora := time.Unix(tsTeste, 0).Add(time.Minute)
+	assinatura := Sign(segredoTeste, tsTeste, corpoTeste)
+	if err := Verify(segredoTeste, assinatura, "1700000000", corpoTeste, 5*time.Minute, agora); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	// sem tolerância o timestamp não é conferido
+	if err := Verify(segredoTeste, assinatura, "1700000000", corpoTeste, 0, agora.Add(time.Hour)); err != nil {
+		t.Errorf("Verify sem tolerância: %v", err)
+	}
+}
+
+func TestVerifyErros(t *testing.T) {
+	agora := time.Unix(tsTeste, 0)
+	assinatura := Sign(segredoTeste, tsTeste, corpoTeste)
+	casos := map[string]struct {
+		segredo, assinatura, timestamp string
+		corpo                          []byte
+		agora                          time.Time
+	}{
+		"segredo errado":     {"outro", assinatura, "1700000000", corpoTeste, agora},
+		"corpo alterado":     {segredoTeste, assinatura, "1700000000", []byte(`{"id":2}`), agora},
+		"timestamp alterado": {segredoTeste, assinatura, "1700000001", corpoTeste, agora},
+		"timestamp inválido": {segredoTeste, assinatura, "ontem", corpoTeste, agora},
+		"sem prefixo":        {segredoTeste, assinatura[len(prefixoAssinatura):], "1700000000", corpoTeste, agora},
+		"expirado":           {segredoTeste, assinatura, "1700000000", corpoTeste, agora.Add(6 * time.Minute)},
+		"no futuro":          {segredoTeste, assinatura, "1700000000", corpoTeste, agora.Add(-6 * time.Minute)},
+	}
+	for nome, c := range casos {
+		err := Verify(c.segredo, c.assinatura, c.timestamp, c.corpo, 5*time.Minute, c.agora)
+		if !errors.Is(err, ErrAssinaturaInvalida) {
+			t.Errorf("%s: err = %v, esperado ErrAssinaturaInvalida", nome, err)
+		}
+	}
+}